@@ -19,7 +19,8 @@ Usage:
 Options:
   --version TAG              Install a specific release tag. Defaults to the latest release.
   --bin-dir DIR              Install peony into DIR. Defaults to ~/.local/bin.
-  --alias                    Append an optional shell function so bloom runs peony tui.
+  --alias                    Append an optional shell function so bloom runs peony tui,
+                             and load peony's shell completions.
   --shell bash|zsh           Choose which shell rc file to update when aliasing is enabled.
   -h, --help                 Show this help text.
 
@@ -172,6 +173,45 @@ append_bloom_alias_block() {
   } >> "${rc_file}"
 }
 
+completion_block() {
+  local shell_name="$1"
+  if [[ "${shell_name}" == "zsh" ]]; then
+    cat <<'EOF'
+# >>> peony completion >>>
+if command -v peony >/dev/null 2>&1 && (( $+functions[compdef] )); then
+  source <(peony completion zsh)
+fi
+# <<< peony completion <<<
+EOF
+    return
+  fi
+
+  cat <<'EOF'
+# >>> peony completion >>>
+if command -v peony >/dev/null 2>&1; then
+  source <(peony completion bash)
+fi
+# <<< peony completion <<<
+EOF
+}
+
+append_completion_block() {
+  local rc_file="$1"
+  local shell_name="$2"
+  mkdir -p "$(dirname "${rc_file}")"
+  touch "${rc_file}"
+
+  if grep -Fq '# >>> peony completion >>>' "${rc_file}"; then
+    return
+  fi
+
+  {
+    printf '\n'
+    completion_block "${shell_name}"
+    printf '\n'
+  } >> "${rc_file}"
+}
+
 install_binary() {
   local source_bin="$1"
   local target_name="$2"
@@ -270,6 +310,8 @@ main() {
     rc_file="$(resolve_rc_file "${TARGET_SHELL}")"
     append_bloom_alias_block "${rc_file}"
     echo "Added Bloom alias to ${rc_file}"
+    append_completion_block "${rc_file}" "${TARGET_SHELL}"
+    echo "Added peony completions to ${rc_file}"
   fi
 
   path_hint || true
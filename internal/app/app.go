@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/divijg19/peony/internal/core"
+	"github.com/divijg19/peony/internal/exchange"
 	"github.com/divijg19/peony/internal/storage"
 )
 
@@ -41,6 +42,30 @@ func OpenDefault() (*Service, func(), error) {
 	return New(st), closeFn, nil
 }
 
+// OpenReadOnly opens Peony's configured local store without allowing writes.
+func OpenReadOnly() (*Service, func(), error) {
+	dbPath, err := storage.ResolveDBPath()
+	if err != nil {
+		return nil, nil, fmt.Errorf("resolve db path: %w", err)
+	}
+
+	db, err := storage.OpenReadOnly(dbPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("open db: %w", err)
+	}
+
+	st, err := storage.New(db)
+	if err != nil {
+		_ = db.Close()
+		return nil, nil, fmt.Errorf("new store: %w", err)
+	}
+
+	closeFn := func() {
+		_ = db.Close()
+	}
+	return New(st), closeFn, nil
+}
+
 // New creates a Service bound to an existing store.
 func New(store *storage.Store) *Service {
 	return &Service{store: store}
@@ -121,6 +146,12 @@ type GardenSnapshot struct {
 
 // Capture stores a new thought and records its initial event.
 func (s *Service) Capture(content string) (int64, error) {
+	return s.CaptureFeeling(content, nil, nil)
+}
+
+// CaptureFeeling stores a new thought with an optional valence and energy.
+// A named feeling is recorded as a "feel" event after the capture.
+func (s *Service) CaptureFeeling(content string, valence, energy *int) (int64, error) {
 	if s == nil || s.store == nil {
 		return -1, fmt.Errorf("capture: service is nil")
 	}
@@ -129,6 +160,9 @@ func (s *Service) Capture(content string) (int64, error) {
 	if content == "" {
 		return -1, fmt.Errorf("capture: content is empty")
 	}
+	if err := core.ValidateFeeling(valence, energy); err != nil {
+		return -1, err
+	}
 
 	id, err := s.store.CreateThought(content)
 	if err != nil {
@@ -139,6 +173,11 @@ func (s *Service) Capture(content string) (int64, error) {
 	if err := s.store.AppendEvent(id, "captured", nil, &next, nil); err != nil {
 		return -1, err
 	}
+	if valence != nil || energy != nil {
+		if _, err := s.store.SetThoughtFeeling(id, valence, energy); err != nil {
+			return -1, err
+		}
+	}
 	return id, nil
 }
 
@@ -152,6 +191,10 @@ func (s *Service) Snapshot(filter core.State, query string) (GardenSnapshot, err
 	if err != nil {
 		return GardenSnapshot{}, err
 	}
+	hits, err := s.searchHits(query)
+	if err != nil {
+		return GardenSnapshot{}, err
+	}
 
 	query = strings.ToLower(strings.TrimSpace(query))
 	now := time.Now().UTC()
@@ -167,7 +210,7 @@ func (s *Service) Snapshot(filter core.State, query string) (GardenSnapshot, err
 		if filter != "" && item.Thought.CurrentState != filter {
 			continue
 		}
-		if query != "" && !matchesQuery(item, query) {
+		if query != "" && !matchesQuery(item, query, hits) {
 			continue
 		}
 		thoughts = append(thoughts, item)
@@ -215,6 +258,10 @@ func (s *Service) SnapshotBloom(filter BloomFilterKind, query string) (BloomSnap
 	if err != nil {
 		return BloomSnapshot{}, err
 	}
+	hits, err := s.searchHits(query)
+	if err != nil {
+		return BloomSnapshot{}, err
+	}
 
 	query = strings.ToLower(strings.TrimSpace(query))
 	now := time.Now().UTC()
@@ -227,7 +274,7 @@ func (s *Service) SnapshotBloom(filter BloomFilterKind, query string) (BloomSnap
 		if item.Ready {
 			readyCount++
 		}
-		if query != "" && !matchesQuery(item, query) {
+		if query != "" && !matchesQuery(item, query, hits) {
 			continue
 		}
 
@@ -306,55 +353,66 @@ func buildZones(thoughts []GardenThought) []GardenZone {
 	return zones
 }
 
+// loadAllThoughts reads every thought outside the trash with its history in a fixed number of queries.
 func (s *Service) loadAllThoughts() ([]GardenThought, error) {
-	const pageSize = 100
-	var items []GardenThought
-	for page := 0; ; page++ {
-		pageThoughts, err := s.store.ListThoughtsByPagination(pageSize, page*pageSize)
-		if err != nil {
-			return nil, fmt.Errorf("snapshot: list thoughts: %w", err)
-		}
-		for _, partial := range pageThoughts {
-			thought, events, err := s.store.GetThought(partial.ID)
-			if err != nil {
-				return nil, fmt.Errorf("snapshot: get thought %d: %w", partial.ID, err)
-			}
-			items = append(items, GardenThought{Thought: thought, Events: events})
-		}
-		if len(pageThoughts) < pageSize {
-			break
-		}
-	}
-	return items, nil
+	return s.loadThoughts(core.StateReleased)
 }
 
+// loadBloomThoughts reads the thoughts Bloom shows, leaving out archived and released ones.
 func (s *Service) loadBloomThoughts() ([]BloomThought, error) {
-	const pageSize = 100
-	var items []BloomThought
-	for page := 0; ; page++ {
-		pageThoughts, err := s.store.ListBloomThoughtsByPagination(pageSize, page*pageSize)
-		if err != nil {
-			return nil, fmt.Errorf("snapshot: list bloom thoughts: %w", err)
-		}
-		for _, partial := range pageThoughts {
-			thought, events, err := s.store.GetThought(partial.ID)
-			if err != nil {
-				return nil, fmt.Errorf("snapshot: get thought %d: %w", partial.ID, err)
-			}
-			items = append(items, BloomThought{Thought: thought, Events: events})
-		}
-		if len(pageThoughts) < pageSize {
-			break
-		}
+	return s.loadThoughts(core.StateArchived, core.StateReleased)
+}
+
+func (s *Service) loadThoughts(exclude ...core.State) ([]BloomThought, error) {
+	loaded, err := s.store.ListThoughtsWithEvents(exclude...)
+	if err != nil {
+		return nil, fmt.Errorf("snapshot: %w", err)
+	}
+	items := make([]BloomThought, 0, len(loaded))
+	for _, item := range loaded {
+		items = append(items, BloomThought{Thought: item.Thought, Events: item.Events})
 	}
 	return items, nil
 }
 
-func matchesQuery(item GardenThought, query string) bool {
+// searchHits runs the free text of query, minus any #tag tokens, through the full-text index.
+// It returns nil when there is no free text to search for.
+func (s *Service) searchHits(query string) (map[int64]bool, error) {
+	_, text := splitTagQuery(strings.TrimSpace(query))
+	if text == "" {
+		return nil, nil
+	}
+	hits, err := s.store.SearchThoughtIDs(text)
+	if err != nil {
+		return nil, fmt.Errorf("snapshot: %w", err)
+	}
+	return hits, nil
+}
+
+// matchesQuery reports whether a thought matches a lowercased search query.
+// Tokens such as #work require that tag. The remaining words match when the full-text
+// index found the thought's content or notes (hits), or as one phrase within its ID,
+// state, tag names, or event kinds.
+func matchesQuery(item GardenThought, query string, hits map[int64]bool) bool {
+	tags, query := splitTagQuery(query)
+	for _, tag := range tags {
+		if !hasTag(item.Thought, tag) {
+			return false
+		}
+	}
+	if query == "" {
+		return true
+	}
+
 	thought := item.Thought
-	if strings.Contains(strings.ToLower(thought.Content), query) {
+	if hits[thought.ID] {
 		return true
 	}
+	for _, tag := range thought.Tags {
+		if strings.Contains(tag, query) {
+			return true
+		}
+	}
 	if strings.Contains(strings.ToLower(string(thought.CurrentState)), query) {
 		return true
 	}
@@ -365,7 +423,94 @@ func matchesQuery(item GardenThought, query string) bool {
 		if strings.Contains(strings.ToLower(event.Kind), query) {
 			return true
 		}
-		if event.Note != nil && strings.Contains(strings.ToLower(*event.Note), query) {
+	}
+	return false
+}
+
+// splitTagQuery separates #tag tokens from the rest of a query.
+// A token only counts as a tag when it is a valid tag name, so "#12" still searches for the text.
+func splitTagQuery(query string) ([]string, string) {
+	var tags []string
+	var words []string
+	for _, field := range strings.Fields(query) {
+		if strings.HasPrefix(field, "#") {
+			if tag, err := core.NormalizeTag(field); err == nil {
+				tags = append(tags, tag)
+				continue
+			}
+		}
+		words = append(words, field)
+	}
+	return tags, strings.Join(words, " ")
+}
+
+func hasTag(thought core.Thought, tag string) bool {
+	for _, existing := range thought.Tags {
+		if existing == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// ExportFilter narrows which thoughts an export includes. Zero values include everything.
+type ExportFilter struct {
+	States []core.State
+	Since  time.Time
+}
+
+// Export returns every matching thought with its full history as an export document, ordered by ID.
+// Since keeps thoughts updated at or after that instant. Thoughts in the trash are exported only
+// when States names released.
+func (s *Service) Export(filter ExportFilter) (exchange.Document, error) {
+	if s == nil || s.store == nil {
+		return exchange.Document{}, fmt.Errorf("export: service is nil")
+	}
+
+	exclude := []core.State{core.StateReleased}
+	if containsState(filter.States, core.StateReleased) {
+		exclude = nil
+	}
+	all, err := s.loadThoughts(exclude...)
+	if err != nil {
+		return exchange.Document{}, err
+	}
+
+	doc := exchange.Document{
+		Header:   exchange.NewHeader(storage.SchemaVersion),
+		Thoughts: make([]exchange.Thought, 0, len(all)),
+	}
+	for _, item := range all {
+		if len(filter.States) > 0 && !containsState(filter.States, item.Thought.CurrentState) {
+			continue
+		}
+		if !filter.Since.IsZero() && item.Thought.UpdatedAt.Before(filter.Since) {
+			continue
+		}
+		doc.Thoughts = append(doc.Thoughts, exchange.FromCore(item.Thought, item.Events))
+	}
+	sort.SliceStable(doc.Thoughts, func(i, j int) bool {
+		return doc.Thoughts[i].ID < doc.Thoughts[j].ID
+	})
+	return doc, nil
+}
+
+// Import recreates the thoughts in an export document with fresh local IDs.
+func (s *Service) Import(doc exchange.Document, opts storage.ImportOptions) (storage.ImportResult, error) {
+	if s == nil || s.store == nil {
+		return storage.ImportResult{}, fmt.Errorf("import: service is nil")
+	}
+	records := make([]storage.ImportRecord, 0, len(doc.Thoughts))
+	for _, item := range doc.Thoughts {
+		thought, events := exchange.ToCore(item)
+		records = append(records, storage.ImportRecord{Thought: thought, Events: events})
+	}
+	return s.store.ImportThoughts(records, opts)
+}
+
+func containsState(states []core.State, state core.State) bool {
+	for _, candidate := range states {
+		if candidate == state {
 			return true
 		}
 	}
@@ -395,12 +540,27 @@ func (s *Service) Tend(id int64, content string, note *string) error {
 	return s.store.MarkThoughtTended(id, note)
 }
 
-// Rest returns a tended thought to resting.
+// Feel sets a thought's valence and energy (nil clears one) and records a "feel" event.
+// It reports whether the feeling changed.
+func (s *Service) Feel(id int64, valence, energy *int) (bool, error) {
+	if s == nil || s.store == nil {
+		return false, fmt.Errorf("feel: service is nil")
+	}
+	return s.store.SetThoughtFeeling(id, valence, energy)
+}
+
+// Rest returns a tended thought to resting for core.SettleDuration.
 func (s *Service) Rest(id int64, note *string) error {
+	return s.RestFor(id, note, 0)
+}
+
+// RestFor returns a tended thought to resting for settle, or core.SettleDuration when settle is zero.
+// The chosen length is kept on the state_change event.
+func (s *Service) RestFor(id int64, note *string, settle time.Duration) error {
 	if s == nil || s.store == nil {
 		return fmt.Errorf("rest: service is nil")
 	}
-	return s.store.TransitionPostTendResolutionStrict(id, core.StateResting, normalizeNote(note))
+	return s.store.TransitionPostTendResolutionFor(id, core.StateResting, normalizeNote(note), settle)
 }
 
 // Evolve marks a thought as evolved.
@@ -411,15 +571,61 @@ func (s *Service) Evolve(id int64) error {
 	return s.store.ToEvolve(id)
 }
 
-// Archive marks a thought as archived.
-func (s *Service) Archive(id int64) error {
+// Archive marks a thought as archived and stores an optional note.
+func (s *Service) Archive(id int64, note *string) error {
 	if s == nil || s.store == nil {
 		return fmt.Errorf("archive: service is nil")
 	}
-	return s.store.ToArchive(id)
+	return s.store.ToArchiveWithNote(id, normalizeNote(note))
+}
+
+// Revive brings an archived or evolved thought back to resting for core.SettleDuration.
+func (s *Service) Revive(id int64, note *string) error {
+	return s.ReviveFor(id, note, 0)
 }
 
-// ReleasePermanent permanently deletes a thought and reindexes local IDs.
+// ReviveFor brings an archived or evolved thought back to resting for settle, or core.SettleDuration when settle is zero.
+func (s *Service) ReviveFor(id int64, note *string, settle time.Duration) error {
+	if s == nil || s.store == nil {
+		return fmt.Errorf("revive: service is nil")
+	}
+	return s.store.ReviveThought(id, normalizeNote(note), settle)
+}
+
+// Release moves a thought into the trash. It can be restored until core.ReleaseGracePeriod passes.
+func (s *Service) Release(id int64, note *string) error {
+	if s == nil || s.store == nil {
+		return fmt.Errorf("release: service is nil")
+	}
+	return s.store.SoftReleaseThought(id, normalizeNote(note))
+}
+
+// Restore takes a thought out of the trash and reports the state it returned to.
+func (s *Service) Restore(id int64) (core.State, error) {
+	if s == nil || s.store == nil {
+		return "", fmt.Errorf("restore: service is nil")
+	}
+	return s.store.RestoreThought(id)
+}
+
+// Trash returns the released thoughts still waiting out their grace period.
+func (s *Service) Trash() ([]core.Thought, error) {
+	if s == nil || s.store == nil {
+		return nil, fmt.Errorf("trash: service is nil")
+	}
+	return s.store.ListReleasedThoughts()
+}
+
+// PurgeExpired permanently deletes released thoughts older than core.ReleaseGracePeriod
+// and returns how many went. IDs are left as they are.
+func (s *Service) PurgeExpired() (int, error) {
+	if s == nil || s.store == nil {
+		return 0, fmt.Errorf("purge: service is nil")
+	}
+	return s.store.PurgeReleased(time.Now().UTC().Add(-core.ReleaseGracePeriod))
+}
+
+// ReleasePermanent permanently deletes a thought and, when core.ReindexOnRelease is set, reindexes local IDs.
 func (s *Service) ReleasePermanent(id int64) error {
 	if s == nil || s.store == nil {
 		return fmt.Errorf("release: service is nil")
@@ -427,9 +633,36 @@ func (s *Service) ReleasePermanent(id int64) error {
 	if err := s.store.ReleaseThought(id); err != nil {
 		return err
 	}
+	if !core.ReindexOnRelease {
+		return nil
+	}
 	return s.store.ReindexThoughtIDs()
 }
 
+// Tag adds and removes tags on a thought and returns its resulting tags.
+func (s *Service) Tag(id int64, add, remove []string) ([]string, error) {
+	if s == nil || s.store == nil {
+		return nil, fmt.Errorf("tag: service is nil")
+	}
+	return s.store.UpdateThoughtTags(id, add, remove)
+}
+
+// Tags returns every tag in use with its thought count.
+func (s *Service) Tags() ([]storage.TagCount, error) {
+	if s == nil || s.store == nil {
+		return nil, fmt.Errorf("tags: service is nil")
+	}
+	return s.store.ListTags()
+}
+
+// ResolveID maps a numeric ID or stable public ID to the thought's current numeric ID.
+func (s *Service) ResolveID(ref string) (int64, error) {
+	if s == nil || s.store == nil {
+		return -1, fmt.Errorf("resolve: service is nil")
+	}
+	return s.store.ResolveThoughtID(ref)
+}
+
 func normalizeNote(note *string) *string {
 	if note == nil {
 		return nil
@@ -1,6 +1,7 @@
 package app
 
 import (
+	"fmt"
 	"path/filepath"
 	"testing"
 	"time"
@@ -9,7 +10,7 @@ import (
 	"github.com/divijg19/peony/internal/storage"
 )
 
-func newTestService(t *testing.T) *Service {
+func newTestService(t testing.TB) *Service {
 	t.Helper()
 	db, err := storage.Open(filepath.Join(t.TempDir(), "peony.db"))
 	if err != nil {
@@ -67,6 +68,180 @@ func TestCaptureSnapshotAndSearch(t *testing.T) {
 	}
 }
 
+func TestSnapshotSearchUnderstandsTagTokens(t *testing.T) {
+	withSettleDuration(t, 0)
+	service := newTestService(t)
+
+	workID, err := service.Capture("draft the proposal")
+	if err != nil {
+		t.Fatalf("capture work: %v", err)
+	}
+	homeID, err := service.Capture("draft a letter home")
+	if err != nil {
+		t.Fatalf("capture home: %v", err)
+	}
+	if _, err := service.Tag(workID, []string{"work"}, nil); err != nil {
+		t.Fatalf("tag work: %v", err)
+	}
+	if _, err := service.Tag(homeID, []string{"home"}, nil); err != nil {
+		t.Fatalf("tag home: %v", err)
+	}
+
+	for _, tc := range []struct {
+		query string
+		want  []int64
+	}{
+		{"#work", []int64{workID}},
+		{"#work draft", []int64{workID}},
+		{"#work letter", nil},
+		{"draft", []int64{workID, homeID}},
+		{"home", []int64{homeID}},
+		{"#nothing", nil},
+	} {
+		snapshot, err := service.SnapshotBloom(BloomFilterAll, tc.query)
+		if err != nil {
+			t.Fatalf("snapshot %q: %v", tc.query, err)
+		}
+		got := make(map[int64]bool)
+		for _, item := range snapshot.Thoughts {
+			got[item.Thought.ID] = true
+		}
+		if len(got) != len(tc.want) {
+			t.Fatalf("query %q matched %v, want %v", tc.query, got, tc.want)
+		}
+		for _, id := range tc.want {
+			if !got[id] {
+				t.Fatalf("query %q matched %v, want %v", tc.query, got, tc.want)
+			}
+		}
+	}
+}
+
+func TestSnapshotBloomSearchUsesFullTextQueries(t *testing.T) {
+	withSettleDuration(t, 0)
+	service := newTestService(t)
+
+	cabinID, err := service.Capture("build a log cabin by the lake")
+	if err != nil {
+		t.Fatalf("capture cabin: %v", err)
+	}
+	treeID, err := service.Capture("a treehouse for the garden")
+	if err != nil {
+		t.Fatalf("capture tree: %v", err)
+	}
+	note := "ask about timber"
+	if err := service.Tend(treeID, "a treehouse for the garden", &note); err != nil {
+		t.Fatalf("tend tree: %v", err)
+	}
+
+	for _, tc := range []struct {
+		query string
+		want  []int64
+	}{
+		{"cab", []int64{cabinID}},
+		{`"log cabin"`, []int64{cabinID}},
+		{`"cabin log"`, nil},
+		{"lake OR treehouse", []int64{cabinID, treeID}},
+		{"garden NOT lake", []int64{treeID}},
+		{"timber", []int64{treeID}},
+		{"tended", []int64{treeID}},
+		{"cabin AND", []int64{cabinID}},
+	} {
+		snapshot, err := service.SnapshotBloom(BloomFilterAll, tc.query)
+		if err != nil {
+			t.Fatalf("snapshot %q: %v", tc.query, err)
+		}
+		got := make(map[int64]bool)
+		for _, item := range snapshot.Thoughts {
+			got[item.Thought.ID] = true
+		}
+		if len(got) != len(tc.want) {
+			t.Fatalf("query %q matched %v, want %v", tc.query, got, tc.want)
+		}
+		for _, id := range tc.want {
+			if !got[id] {
+				t.Fatalf("query %q matched %v, want %v", tc.query, got, tc.want)
+			}
+		}
+	}
+}
+
+func TestExportFiltersByStateAndSince(t *testing.T) {
+	withSettleDuration(t, 0)
+	service := newTestService(t)
+
+	keptID, err := service.Capture("kept for export")
+	if err != nil {
+		t.Fatalf("capture kept: %v", err)
+	}
+	archivedID, err := service.Capture("archived away")
+	if err != nil {
+		t.Fatalf("capture archived: %v", err)
+	}
+	if err := service.Archive(archivedID, nil); err != nil {
+		t.Fatalf("archive: %v", err)
+	}
+	if _, err := service.Tag(keptID, []string{"work"}, nil); err != nil {
+		t.Fatalf("tag: %v", err)
+	}
+
+	doc, err := service.Export(ExportFilter{})
+	if err != nil {
+		t.Fatalf("export all: %v", err)
+	}
+	if doc.Format != "peony.export" || doc.Version != 1 || doc.SchemaVersion != storage.SchemaVersion {
+		t.Fatalf("export header = %+v", doc.Header)
+	}
+	if len(doc.Thoughts) != 2 || doc.Thoughts[0].ID != keptID {
+		t.Fatalf("export thoughts = %+v", doc.Thoughts)
+	}
+	kept := doc.Thoughts[0]
+	if kept.PublicID == "" || len(kept.Tags) != 1 || kept.Tags[0] != "work" {
+		t.Fatalf("exported thought = %+v", kept)
+	}
+	if len(kept.Events) != 2 || kept.Events[0].Kind != "captured" || kept.Events[1].Kind != "tagged" {
+		t.Fatalf("exported events = %+v", kept.Events)
+	}
+
+	doc, err = service.Export(ExportFilter{States: []core.State{core.StateArchived}})
+	if err != nil {
+		t.Fatalf("export archived: %v", err)
+	}
+	if len(doc.Thoughts) != 1 || doc.Thoughts[0].ID != archivedID {
+		t.Fatalf("archived export = %+v", doc.Thoughts)
+	}
+
+	releasedID, err := service.Capture("let go of this")
+	if err != nil {
+		t.Fatalf("capture released: %v", err)
+	}
+	if err := service.Release(releasedID, nil); err != nil {
+		t.Fatalf("release: %v", err)
+	}
+	doc, err = service.Export(ExportFilter{})
+	if err != nil {
+		t.Fatalf("export without trash: %v", err)
+	}
+	if len(doc.Thoughts) != 2 {
+		t.Fatalf("export without a state filter = %+v, want the trash left out", doc.Thoughts)
+	}
+	doc, err = service.Export(ExportFilter{States: []core.State{core.StateReleased, core.StateArchived}})
+	if err != nil {
+		t.Fatalf("export released: %v", err)
+	}
+	if len(doc.Thoughts) != 2 || doc.Thoughts[0].ID != archivedID || doc.Thoughts[1].ID != releasedID || doc.Thoughts[1].ReleasedAt == nil {
+		t.Fatalf("released export = %+v", doc.Thoughts)
+	}
+
+	doc, err = service.Export(ExportFilter{Since: time.Now().UTC().Add(time.Hour)})
+	if err != nil {
+		t.Fatalf("export since: %v", err)
+	}
+	if len(doc.Thoughts) != 0 {
+		t.Fatalf("future since export = %+v, want none", doc.Thoughts)
+	}
+}
+
 func TestBuildZonesGroupsThoughtsByBloomMeaning(t *testing.T) {
 	zones := buildZones([]GardenThought{
 		{Thought: core.Thought{ID: 1, CurrentState: core.StateCaptured}, Ready: true},
@@ -124,7 +299,7 @@ func TestSnapshotBloomFocusedQueueFiltersAndCounts(t *testing.T) {
 	if err != nil {
 		t.Fatalf("capture archived: %v", err)
 	}
-	if err := service.Archive(archivedID); err != nil {
+	if err := service.Archive(archivedID, nil); err != nil {
 		t.Fatalf("archive: %v", err)
 	}
 
@@ -207,7 +382,7 @@ func TestTendRestEvolveArchiveAndRelease(t *testing.T) {
 	if err := service.Evolve(secondID); err != nil {
 		t.Fatalf("evolve: %v", err)
 	}
-	if err := service.Archive(thirdID); err != nil {
+	if err := service.Archive(thirdID, nil); err != nil {
 		t.Fatalf("archive: %v", err)
 	}
 	if err := service.ReleasePermanent(secondID); err != nil {
@@ -229,3 +404,59 @@ func TestTendRestEvolveArchiveAndRelease(t *testing.T) {
 		t.Fatalf("ids after reindex = %#v, want 1 and 2", ids)
 	}
 }
+
+// BenchmarkSnapshotBloom measures opening Bloom on large, years-old gardens.
+func BenchmarkSnapshotBloom(b *testing.B) {
+	for _, size := range []struct {
+		name string
+		n    int
+	}{{"10k", 10_000}, {"100k", 100_000}} {
+		if size.n > 10_000 && testing.Short() {
+			continue
+		}
+		service := newTestService(b)
+		seedGarden(b, service, size.n)
+
+		b.Run(size.name, func(b *testing.B) {
+			for b.Loop() {
+				if _, err := service.SnapshotBloom(BloomFilterReady, ""); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// seedGarden imports n thoughts spread over every state, each with a captured and a tended event.
+func seedGarden(b *testing.B, service *Service, n int) {
+	b.Helper()
+	start := time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+	captured := core.StateCaptured
+	records := make([]storage.ImportRecord, 0, n)
+	for i := 0; i < n; i++ {
+		created := start.Add(time.Duration(i) * 17 * time.Minute)
+		thought := core.Thought{
+			Content:       fmt.Sprintf("synthetic thought %d", i),
+			CurrentState:  core.States[i%len(core.States)],
+			CreatedAt:     created,
+			UpdatedAt:     created.Add(time.Hour),
+			EligibilityAt: created,
+		}
+		if thought.CurrentState == core.StateReleased {
+			thought.ReleasedAt = &thought.UpdatedAt
+		}
+		if i%3 == 0 {
+			thought.Tags = []string{"later"}
+		}
+		records = append(records, storage.ImportRecord{
+			Thought: thought,
+			Events: []core.Event{
+				{Kind: "captured", At: created, NextState: &captured},
+				{Kind: "tended", At: created.Add(time.Hour)},
+			},
+		})
+	}
+	if _, err := service.store.ImportThoughts(records, storage.ImportOptions{}); err != nil {
+		b.Fatalf("seed %d thoughts: %v", n, err)
+	}
+}
@@ -0,0 +1,85 @@
+package app
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/divijg19/peony/internal/core"
+	"github.com/divijg19/peony/internal/storage"
+)
+
+// ViewSorts are the orders a view can be sorted in.
+var ViewSorts = []storage.ThoughtOrder{storage.OrderByID, storage.OrderByCreated, storage.OrderByUpdated, storage.OrderByEligibility}
+
+// ViewFilter narrows the thoughts peony view and Bloom's :view list. Every set field must match;
+// zero fields are left out.
+type ViewFilter struct {
+	States        []core.State         // only these states, by ID; without any, everything outside the trash
+	Tag           string               // only thoughts carrying this normalized tag
+	CreatedAfter  time.Time            // created at or after
+	CreatedBefore time.Time            // created strictly before
+	UpdatedAfter  time.Time            // updated at or after
+	UpdatedBefore time.Time            // updated strictly before
+	MinTends      int                  // tended at least this many times
+	Sort          storage.ThoughtOrder // one of ViewSorts; empty keeps the default order
+}
+
+// Query builds the storage query f describes.
+//
+// Without states it lists every thought outside the trash by updated time, or with a tag every
+// thought carrying it by ID; naming states lists exactly those, by ID.
+func (f ViewFilter) Query() (storage.ThoughtQuery, error) {
+	query := storage.GardenQuery()
+	if f.Tag != "" {
+		query = storage.TagQuery(f.Tag)
+	}
+	if len(f.States) > 0 {
+		query.States = f.States
+		query.Exclude = nil
+		query.Order = storage.OrderByID
+	}
+
+	query.Created = storage.TimeRange{From: f.CreatedAfter, To: before(f.CreatedBefore)}
+	query.Updated = storage.TimeRange{From: f.UpdatedAfter, To: before(f.UpdatedBefore)}
+
+	if f.MinTends < 0 {
+		return storage.ThoughtQuery{}, fmt.Errorf("tend count must be >= 0")
+	}
+	query.MinTends = f.MinTends
+
+	if f.Sort != "" {
+		known := false
+		for _, sort := range ViewSorts {
+			known = known || f.Sort == sort
+		}
+		if !known {
+			return storage.ThoughtQuery{}, fmt.Errorf("unknown order %q (use id, created, updated, or eligibility)", f.Sort)
+		}
+		query.Order = f.Sort
+	}
+	return query, nil
+}
+
+// before turns an exclusive upper bound into the inclusive one TimeRange takes.
+func before(t time.Time) time.Time {
+	if t.IsZero() {
+		return t
+	}
+	return t.Add(-time.Nanosecond)
+}
+
+// View returns the thoughts filter selects, with their tags.
+func (s *Service) View(filter ViewFilter) ([]core.Thought, error) {
+	if s == nil || s.store == nil {
+		return nil, fmt.Errorf("view: service is nil")
+	}
+	query, err := filter.Query()
+	if err != nil {
+		return nil, fmt.Errorf("view: %w", err)
+	}
+	thoughts, err := s.store.QueryThoughts(query)
+	if err != nil {
+		return nil, fmt.Errorf("view: %w", err)
+	}
+	return thoughts, nil
+}
@@ -0,0 +1,83 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/divijg19/peony/internal/command"
+	"github.com/divijg19/peony/internal/storage"
+)
+
+// cmdBackup writes a snapshot of the garden to a path, or into the backups folder beside the database.
+func cmdBackup(in command.Args) int {
+	args := in.Positional
+	if len(args) > 1 {
+		fmt.Fprintln(os.Stderr, "backup: usage: `peony backup [path]`")
+		return exitUsage
+	}
+
+	dbPath, err := storage.ResolveDBPath()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "backup: resolve db path: %v\n", err)
+		return exitCode(err)
+	}
+	if _, err := os.Stat(dbPath); errors.Is(err, fs.ErrNotExist) {
+		fmt.Fprintf(os.Stderr, "backup: there is no garden at %s yet\n", dbPath)
+		return exitFailure
+	}
+
+	path := storage.DefaultBackupPath(dbPath, time.Now())
+	if len(args) == 1 {
+		target := args[0]
+		if info, err := os.Stat(target); err == nil && info.IsDir() {
+			target = filepath.Join(target, filepath.Base(path))
+		}
+		path = target
+	}
+
+	st, closeDB, err := openStore()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "backup: %v\n", err)
+		return exitCode(err)
+	}
+	defer closeDB()
+
+	if err := st.Backup(path); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		return exitCode(err)
+	}
+	fmt.Printf("Backed up the garden to %s\n", path)
+	return exitOK
+}
+
+// dbRestore swaps a backup in for the garden, then opens it once so an older backup is migrated now.
+// The garden it replaces is not opened first, so it is neither migrated nor purged on the way out.
+func dbRestore(path string) int {
+	dbPath, err := storage.ResolveDBPath()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "db restore: resolve db path: %v\n", err)
+		return exitCode(err)
+	}
+
+	result, err := storage.RestoreBackup(path, dbPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "db restore: %v\n", err)
+		return exitCode(err)
+	}
+	fmt.Printf("Restored the garden from %s (schema version %d).\n", path, result.Schema)
+	if result.Previous != "" {
+		fmt.Printf("The garden it replaced is kept at %s\n", result.Previous)
+	}
+
+	_, closeDB, err := openStore()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "db restore: open restored garden: %v\n", err)
+		return exitCode(err)
+	}
+	closeDB()
+	return exitOK
+}
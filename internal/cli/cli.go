@@ -7,47 +7,66 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"text/template"
 	"time"
 
+	"github.com/divijg19/peony/internal/app"
+	"github.com/divijg19/peony/internal/command"
 	"github.com/divijg19/peony/internal/core"
 	"github.com/divijg19/peony/internal/storage"
 	"github.com/divijg19/peony/internal/tui"
+	"github.com/divijg19/peony/internal/version"
+	"github.com/divijg19/peony/internal/web"
 )
 
-// Version is the current CLI version string.
-const Version = "v0.4"
-
 // PrintHelp prints the CLI usage and examples.
 func PrintHelp() {
+	var specs []command.Spec
+	width := 0
+	for _, spec := range command.Specs {
+		if !spec.Hidden {
+			specs = append(specs, spec)
+			width = max(width, len(commandNames(spec)))
+		}
+	}
+
+	var commands, syntax strings.Builder
+	for _, spec := range specs {
+		fmt.Fprintf(&commands, "  %-*s   %s\n", width, commandNames(spec), spec.Summary)
+		fmt.Fprintf(&syntax, "  peony %s\n", spec.Usage[0])
+	}
+
 	fmt.Print(`Peony: a calm holding space for unfinished thoughts
 
 Usage:
   peony <command> [args]
 
 Commands:
-  help, h        Show this help or detailed help for a command
-  version, -v    Show version
-  add, a         Capture a thought
-  view, v        View the list of thoughts or a thought by id
-  tend, t        List thoughts which are ready to be tended
-  release, r     Clears a thought from peony
-  evolve, e      Passes a thought into peony wider integration
-  config, c      View and edit defaults for peony
-  tui            Open the Peony terminal garden
-
+` + commands.String() + `
 Syntax:
-  peony add [content]
-  peony view [id]
-  peony view [filter]
-  peony tend [id]
-  peony config [setting]
-  peony tui
+` + syntax.String() + `
+List flags (view, tend, evolve, archive, trash, tag, search):
+  --json         print a JSON array instead of a table
+  --limit n      at most n rows (rows per page in the pager)
+  --offset n     skip the first n rows
+  --after c      with --json, continue after the row whose cursor is c
+                 (view, tend, evolve, archive)
+  --no-pager     print once and exit, even on a terminal
+
+When stdin or stdout is not a terminal, lists print once without the
+pager and nothing prompts: commands that must ask exit with code 5.
+
+Exit codes:
+  0  done                          3  no thought has that id or ref
+  1  unexpected failure            4  the thought's state does not allow it
+  2  bad arguments or query        5  needs a terminal to ask
 
 Examples:
   peony help view
   peony add "I want to build a log cabin"
   peony view 12
   peony view --archived
+  peony tag 12 +work -later
   peony tui
   bloom  (if installed as an optional shell alias)
 
@@ -56,6 +75,11 @@ For detailed help on a command:
 `)
 }
 
+// commandNames joins a command's name and aliases for the command list.
+func commandNames(spec command.Spec) string {
+	return strings.Join(append([]string{spec.Name}, spec.Aliases...), ", ")
+}
+
 // openStore opens the SQLite-backed store and returns a close function.
 func openStore() (*storage.Store, func(), error) {
 	var err error
@@ -85,424 +109,360 @@ func openStore() (*storage.Store, func(), error) {
 }
 
 // cmdAdd captures a thought and appends the initial captured event.
-func cmdAdd(args []string) int {
-	content := strings.TrimSpace(strings.Join(args, " "))
+func cmdAdd(in command.Args) int {
+	valence, energy, err := feelingFrom(in)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "add: %v\n", err)
+		return exitUsage
+	}
+	// The feeling is checked before any prompt so a bad flag never waits on stdin.
+	if err := core.ValidateFeeling(valence, energy); err != nil {
+		fmt.Fprintf(os.Stderr, "add: %v\n", err)
+		return exitUsage
+	}
+
+	content := strings.TrimSpace(strings.Join(in.Positional, " "))
 	if content == "" {
-		fmt.Print("What would you like to hold? ")
+		if interactive() {
+			fmt.Print("What would you like to hold? ")
+		}
 		reader := bufio.NewReader(os.Stdin)
 		line, err := reader.ReadString('\n')
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "add: read: %v\n", err)
-			return 1
+			return exitCode(err)
 		}
 		content = strings.TrimSpace(line)
 	}
 
 	if content == "" {
 		fmt.Fprintln(os.Stderr, "add: content is empty")
-		return 1
+		return exitFailure
 	}
 
-	st, closeDB, err := openStore()
+	service, closeFn, err := app.OpenDefault()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "add: %v\n", err)
-		return 1
+		return exitCode(err)
 	}
-	defer closeDB()
+	defer closeFn()
 
-	var id int64
-	id, err = st.CreateThought(content)
+	id, err := service.CaptureFeeling(content, valence, energy)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "add: %v\n", err)
-		return 1
+		return exitCode(err)
 	}
 
-	next := core.StateCaptured
-	err = st.AppendEvent(id, "captured", nil, &next, nil)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "add: append event: %v\n", err)
-		return 1
+	if item, err := service.Thought(id); err == nil && item.Thought.PublicID != "" {
+		fmt.Printf("Saved as #%d (ref %s)\n", id, item.Thought.PublicID)
+		return exitOK
 	}
-
 	fmt.Printf("Saved as #%d\n", id)
-	return 0
+	return exitOK
 }
 
 // cmdView shows a paginated list of thoughts or a single thought with its event history.
-func cmdView(args []string) int {
+func cmdView(in command.Args) int {
+	opts, err := listOptionsFrom(in)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "view: %v\n", err)
+		return exitUsage
+	}
+	format, _ := in.Value("--format")
+	args := in.Positional
+	tag, tagged := in.Value("--tag")
+	if !tagged && len(args) == 2 && args[0] == "tag" {
+		tag, tagged, args = args[1], true, nil
+	}
+	single := !tagged && len(args) == 1 && core.LooksLikeThoughtRef(args[0])
+	if format != "" && !single {
+		fmt.Fprintln(os.Stderr, "view: --format prints one thought; give its id")
+		return exitUsage
+	}
+	if !single {
+		return viewList(in, args, tag, tagged, opts)
+	}
 
-	if len(args) == 0 {
-		st, closeDB, err := openStore()
-		if err != nil {
+	if command.HasViewFilter(in) {
+		fmt.Fprintln(os.Stderr, "view: filter flags narrow a list; leave out the id")
+		return exitUsage
+	}
+	if opts.limit != 0 || opts.offset != 0 || opts.noPager {
+		fmt.Fprintln(os.Stderr, "view: --limit, --offset and --no-pager list thoughts; leave out the id")
+		return exitUsage
+	}
+	if opts.json && format != "" {
+		fmt.Fprintln(os.Stderr, "view: use --json or --format, not both")
+		return exitUsage
+	}
+	var tmpl *template.Template
+	if format != "" {
+		if tmpl, err = parseThoughtTemplate(format); err != nil {
 			fmt.Fprintf(os.Stderr, "view: %v\n", err)
-			return 1
+			return exitUsage
 		}
-		defer closeDB()
+	}
 
-		reader := bufio.NewReader(os.Stdin)
-		pageSize := 10
-		page := 0
-
-		overview := func(s string) string {
-			s = strings.ReplaceAll(s, "\n", " ")
-			s = strings.TrimSpace(s)
-			const max = 80
-			if len(s) <= max {
-				return s
-			}
-			return s[:max-1] + "…"
-		}
+	st, closeDB, err := openStore()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "view: %v\n", err)
+		return exitCode(err)
+	}
+	defer closeDB()
 
-		for {
-			offset := page * pageSize
-			thoughts, err := st.ListThoughtsByPagination(pageSize, offset)
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "view: %v\n", err)
-				return 1
-			}
+	id, err := st.ResolveThoughtID(args[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "view: %v\n", err)
+		return exitCode(err)
+	}
 
-			if len(thoughts) == 0 {
-				if page == 0 {
-					fmt.Println("No thoughts yet.")
-					return 0
-				}
-				page--
-				continue
-			}
+	thought, events, err := st.GetThought(id)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "view: %v\n", err)
+		return exitCode(err)
+	}
 
-			fmt.Printf("Page %d\n", page+1)
-			fmt.Printf("%-6s %-10s %-5s %-20s %s\n", "ID", "STATE", "TEND", "UPDATED", "OVERVIEW")
-			for _, th := range thoughts {
-				fmt.Printf("%-6d %-10s %-5d %-20s %s\n",
-					th.ID,
-					th.CurrentState,
-					th.TendCounter,
-					th.UpdatedAt.UTC().Format("2006-01-02 15:04"),
-					overview(th.Content),
-				)
+	if opts.json || tmpl != nil {
+		if err := printThoughtDocument(thought, events, tmpl); err != nil {
+			fmt.Fprintf(os.Stderr, "view: %v\n", err)
+			var execErr template.ExecError
+			if errors.As(err, &execErr) {
+				return exitUsage
 			}
+			return exitFailure
+		}
+		return exitOK
+	}
 
-			fmt.Print("[n]ext, [p]rev, [q]uit: ")
-			line, err := reader.ReadString('\n')
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "view: read: %v\n", err)
-				return 1
-			}
+	fmt.Printf("#%d  %s  (tends: %d)\n", thought.ID, thought.CurrentState, thought.TendCounter)
 
-			switch strings.ToLower(strings.TrimSpace(line)) {
-			case "q":
-				return 0
-			case "p":
-				if page > 0 {
-					page--
-				}
+	now := time.Now().UTC()
+
+	formatShortUTC := func(t time.Time) string {
+		return t.UTC().Format("2006-01-02 15:04Z")
+	}
+
+	formatRelative := func(t time.Time, now time.Time) string {
+		d := t.Sub(now)
+		if d < 0 {
+			d = -d
+			switch {
+			case d < time.Minute:
+				return "just now"
+			case d < time.Hour:
+				return fmt.Sprintf("%dm ago", int(d.Minutes()))
+			case d < 24*time.Hour:
+				return fmt.Sprintf("%dh ago", int(d.Hours()))
 			default:
-				if len(thoughts) == pageSize {
-					page++
-				}
+				return fmt.Sprintf("%dd ago", int(d.Hours()/24))
 			}
 		}
+
+		switch {
+		case d < time.Minute:
+			return "in <1m"
+		case d < time.Hour:
+			return fmt.Sprintf("in %dm", int(d.Minutes()))
+		case d < 24*time.Hour:
+			return fmt.Sprintf("in %dh", int(d.Hours()))
+		default:
+			return fmt.Sprintf("in %dd", int(d.Hours()/24))
+		}
 	}
-	if len(args) == 1 {
-		if id, err := strconv.ParseInt(args[0], 10, 64); err == nil && id > 0 {
-			st, closeDB, err := openStore()
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "view: %v\n", err)
-				return 1
-			}
-			defer closeDB()
 
-			thought, events, err := st.GetThought(id)
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "view: %v\n", err)
-				return 1
-			}
+	switch thought.CurrentState {
+	case core.StateCaptured, core.StateResting:
+		eligible := core.EligibleToSurface(thought, now)
+		if eligible {
+			fmt.Println("Eligible: yes")
+		} else {
+			fmt.Printf("Eligible: %s (at %s)\n", formatRelative(thought.EligibilityAt, now), formatShortUTC(thought.EligibilityAt))
+		}
+	case core.StateTended:
+		fmt.Println("Needs resolution: rest/evolve/release/archive")
+	case core.StateReleased:
+		fmt.Printf("In the trash until %s; peony restore %d brings it back\n", formatShortUTC(core.PurgeAt(thought)), thought.ID)
+	case core.StateEvolved, core.StateArchived:
+		fmt.Printf("Terminal: %s\n", thought.CurrentState)
+	default:
+		fmt.Printf("State: %s\n", thought.CurrentState)
+	}
 
-			fmt.Printf("#%d  %s  (tends: %d)\n", thought.ID, thought.CurrentState, thought.TendCounter)
+	fmt.Println()
+	fmt.Println("CONTENT")
+	fmt.Println(thought.Content)
 
-			now := time.Now().UTC()
+	fmt.Println()
+	fmt.Println("META")
+	if thought.PublicID != "" {
+		fmt.Printf("Ref:      %s\n", thought.PublicID)
+	}
+	if len(thought.Tags) > 0 {
+		fmt.Printf("Tags:     %s\n", formatTags(thought.Tags))
+	}
+	fmt.Printf("Created:  %s (%s)\n", formatShortUTC(thought.CreatedAt), formatRelative(thought.CreatedAt, now))
+	fmt.Printf("Updated:  %s (%s)\n", formatShortUTC(thought.UpdatedAt), formatRelative(thought.UpdatedAt, now))
+	fmt.Printf("Eligible: %s (%s)\n", formatShortUTC(thought.EligibilityAt), formatRelative(thought.EligibilityAt, now))
 
-			formatShortUTC := func(t time.Time) string {
-				return t.UTC().Format("2006-01-02 15:04Z")
-			}
+	if thought.LastTendedAt != nil {
+		fmt.Printf("Last tended: %s (%s)\n", formatShortUTC(*thought.LastTendedAt), formatRelative(*thought.LastTendedAt, now))
+	}
+	if thought.Valence != nil || thought.Energy != nil {
+		fmt.Printf("Feeling:  %s\n", core.FormatFeeling(thought.Valence, thought.Energy))
+	}
 
-			formatRelative := func(t time.Time, now time.Time) string {
-				d := t.Sub(now)
-				if d < 0 {
-					d = -d
-					switch {
-					case d < time.Minute:
-						return "just now"
-					case d < time.Hour:
-						return fmt.Sprintf("%dm ago", int(d.Minutes()))
-					case d < 24*time.Hour:
-						return fmt.Sprintf("%dh ago", int(d.Hours()))
-					default:
-						return fmt.Sprintf("%dd ago", int(d.Hours()/24))
-					}
-				}
+	if len(events) > 0 {
+		fmt.Println()
+		fmt.Println("EVENTS")
+		for _, ev := range events {
+			at := formatShortUTC(ev.At)
 
-				switch {
-				case d < time.Minute:
-					return "in <1m"
-				case d < time.Hour:
-					return fmt.Sprintf("in %dm", int(d.Minutes()))
-				case d < 24*time.Hour:
-					return fmt.Sprintf("in %dh", int(d.Hours()))
-				default:
-					return fmt.Sprintf("in %dd", int(d.Hours()/24))
+			transition := ""
+			if ev.PreviousState != nil || ev.NextState != nil {
+				prevState := ""
+				nextState := ""
+				if ev.PreviousState != nil {
+					prevState = string(*ev.PreviousState)
+				}
+				if ev.NextState != nil {
+					nextState = string(*ev.NextState)
 				}
-			}
 
-			switch thought.CurrentState {
-			case core.StateCaptured, core.StateResting:
-				eligible := core.EligibleToSurface(thought, now)
-				if eligible {
-					fmt.Println("Eligible: yes")
-				} else {
-					fmt.Printf("Eligible: %s (at %s)\n", formatRelative(thought.EligibilityAt, now), formatShortUTC(thought.EligibilityAt))
+				if prevState == "" && nextState != "" {
+					transition = " " + nextState
+				} else if prevState != "" && nextState == "" {
+					transition = " " + prevState
+				} else if prevState != "" || nextState != "" {
+					transition = fmt.Sprintf(" %s → %s", prevState, nextState)
 				}
-			case core.StateTended:
-				fmt.Println("Needs resolution: rest/evolve/release/archive")
-			case core.StateEvolved, core.StateReleased, core.StateArchived:
-				fmt.Printf("Terminal: %s\n", thought.CurrentState)
-			default:
-				fmt.Printf("State: %s\n", thought.CurrentState)
 			}
 
-			fmt.Println()
-			fmt.Println("CONTENT")
-			fmt.Println(thought.Content)
-
-			fmt.Println()
-			fmt.Println("META")
-			fmt.Printf("Created:  %s (%s)\n", formatShortUTC(thought.CreatedAt), formatRelative(thought.CreatedAt, now))
-			fmt.Printf("Updated:  %s (%s)\n", formatShortUTC(thought.UpdatedAt), formatRelative(thought.UpdatedAt, now))
-			fmt.Printf("Eligible: %s (%s)\n", formatShortUTC(thought.EligibilityAt), formatRelative(thought.EligibilityAt, now))
-
-			if thought.LastTendedAt != nil {
-				fmt.Printf("Last tended: %s (%s)\n", formatShortUTC(*thought.LastTendedAt), formatRelative(*thought.LastTendedAt, now))
-			}
-			if thought.Valence != nil {
-				fmt.Printf("Valence: %d\n", *thought.Valence)
-			}
-			if thought.Energy != nil {
-				fmt.Printf("Energy: %d\n", *thought.Energy)
+			if ev.SettleFor != nil {
+				transition += " for " + core.FormatSettleDuration(*ev.SettleFor)
 			}
 
-			if len(events) > 0 {
-				fmt.Println()
-				fmt.Println("EVENTS")
-				for _, ev := range events {
-					at := formatShortUTC(ev.At)
-
-					transition := ""
-					if ev.PreviousState != nil || ev.NextState != nil {
-						prevState := ""
-						nextState := ""
-						if ev.PreviousState != nil {
-							prevState = string(*ev.PreviousState)
-						}
-						if ev.NextState != nil {
-							nextState = string(*ev.NextState)
-						}
-
-						if prevState == "" && nextState != "" {
-							transition = " " + nextState
-						} else if prevState != "" && nextState == "" {
-							transition = " " + prevState
-						} else if prevState != "" || nextState != "" {
-							transition = fmt.Sprintf(" %s → %s", prevState, nextState)
-						}
-					}
-
-					fmt.Printf("- %s  %s%s\n", at, ev.Kind, transition)
-					if ev.Note != nil && strings.TrimSpace(*ev.Note) != "" {
-						fmt.Printf("  note: %s\n", strings.TrimSpace(*ev.Note))
-					}
-				}
+			fmt.Printf("- %s  %s%s\n", at, ev.Kind, transition)
+			if ev.Note != nil && strings.TrimSpace(*ev.Note) != "" {
+				fmt.Printf("  note: %s\n", strings.TrimSpace(*ev.Note))
 			}
-			return 0
-		}
-		filter := strings.TrimSpace(strings.Join(args, " "))
-		if after, ok := strings.CutPrefix(filter, "--"); ok {
-			filter = after
 		}
+	}
+	return exitOK
+}
 
-		switch filter {
-		case "captured", "resting", "tended", "evolved", "released", "archived":
-
-		default:
+// viewList lists the thoughts that a view's positional states, --tag, and filter flags select.
+func viewList(in command.Args, args []string, tag string, tagged bool, opts listOptions) int {
+	var states []core.State
+	for _, arg := range args {
+		state, err := core.ParseState(strings.TrimPrefix(arg, "--"))
+		if err != nil {
 			fmt.Fprintln(os.Stderr, "view: invalid filter")
-			return 2
+			return exitUsage
 		}
-		st, closeDB, err := openStore()
+		states = append(states, state)
+	}
+	if tagged {
+		name, err := core.NormalizeTag(tag)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "view: %v\n", err)
-			return 1
-		}
-		defer closeDB()
-
-		reader := bufio.NewReader(os.Stdin)
-		pageSize := 10
-		page := 0
-
-		overview := func(s string) string {
-			s = strings.ReplaceAll(s, "\n", " ")
-			s = strings.TrimSpace(s)
-			const max = 80
-			if len(s) <= max {
-				return s
-			}
-			return s[:max-1] + "…"
+			return exitUsage
 		}
+		tag = name
+	}
+	filter, err := command.ViewFilter(in, states, tag)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "view: %v\n", err)
+		return exitUsage
+	}
+	query, err := filter.Query()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "view: %v\n", err)
+		return exitUsage
+	}
 
-		for {
-			offset := page * pageSize
-			thoughts, err := st.FilterViewByPagination(pageSize, offset, filter)
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "view: %v\n", err)
-				return 1
-			}
-
-			if len(thoughts) == 0 {
-				if page == 0 {
-					fmt.Println("No thoughts yet.")
-					return 0
-				}
-				page--
-				continue
-			}
-
-			fmt.Printf("Page %d\n", page+1)
-			fmt.Printf("%-6s %-10s %-5s %-20s %s\n", "ID", "STATE", "TEND", "UPDATED", "OVERVIEW")
-			for _, th := range thoughts {
-				fmt.Printf("%-6d %-10s %-5d %-20s %s\n",
-					th.ID,
-					th.CurrentState,
-					th.TendCounter,
-					th.UpdatedAt.UTC().Format("2006-01-02 15:04"),
-					overview(th.Content),
-				)
-			}
-
-			fmt.Print("[n]ext, [p]rev, [q]uit: ")
-			line, err := reader.ReadString('\n')
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "view: read: %v\n", err)
-				return 1
-			}
+	st, closeDB, err := openStore()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "view: %v\n", err)
+		return exitCode(err)
+	}
+	defer closeDB()
 
-			switch strings.ToLower(strings.TrimSpace(line)) {
-			case "q":
-				return 0
-			case "p":
-				if page > 0 {
-					page--
-				}
-			default:
-				if len(thoughts) == pageSize {
-					page++
-				}
-			}
-		}
+	list := thoughtList{
+		cmd:      "view",
+		store:    st,
+		query:    query,
+		empty:    "No thoughts yet.",
+		overview: 80,
+	}
+	switch {
+	case command.HasViewFilter(in):
+		list.empty = "No thoughts match those filters."
+	case tagged:
+		list.empty = fmt.Sprintf("No thoughts tagged #%s.", tag)
 	}
-	return 0
+	if tagged {
+		list.title = " · #" + tag
+	}
+	return listThoughts(list, opts)
 }
 
 // cmdTend lists eligible thoughts or runs the interactive tend flow for a specific thought ID.
-func cmdTend(args []string) int {
+func cmdTend(in command.Args) int {
+	args := in.Positional
+	opts, err := listOptionsFrom(in)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "tend: %v\n", err)
+		return exitUsage
+	}
+	if opts.set && len(args) > 0 {
+		fmt.Fprintln(os.Stderr, "tend: --json, --limit, --offset and --no-pager list thoughts; leave out the id")
+		return exitUsage
+	}
+
 	if len(args) == 0 {
 		st, closeDB, err := openStore()
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "tend: %v\n", err)
-			return 1
+			return exitCode(err)
 		}
 		defer closeDB()
 
-		reader := bufio.NewReader(os.Stdin)
-		pageSize := 10
-		page := 0
-
-		overview := func(s string) string {
-			s = strings.ReplaceAll(s, "\n", " ")
-			s = strings.TrimSpace(s)
-			const max = 60
-			if len(s) <= max {
-				return s
-			}
-			return s[:max-1] + "…"
-		}
-
-		for {
-			offset := page * pageSize
-			thoughts, err := st.ListTendThoughtsByPagination(pageSize, offset)
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "tend: %v\n", err)
-				return 1
-			}
-
-			if len(thoughts) == 0 {
-				if page == 0 {
-					fmt.Println("No thoughts yet.")
-					return 0
-				}
-				page--
-				continue
-			}
-
-			fmt.Printf("Page %d\n", page+1)
-			fmt.Printf("%-6s %-10s %-5s %-20s %s\n", "ID", "STATE", "TEND", "UPDATED", "OVERVIEW")
-			for _, th := range thoughts {
-				fmt.Printf("%-6d %-10s %-5d %-20s %s\n",
-					th.ID,
-					th.CurrentState,
-					th.TendCounter,
-					th.UpdatedAt.UTC().Format("2006-01-02 15:04"),
-					overview(th.Content),
-				)
-			}
-
-			fmt.Print("[n]ext, [p]rev, [q]uit: ")
-			line, err := reader.ReadString('\n')
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "tend: read: %v\n", err)
-				return 1
-			}
-
-			switch strings.ToLower(strings.TrimSpace(line)) {
-			case "q":
-				return 0
-			case "p":
-				if page > 0 {
-					page--
-				}
-			default:
-				if len(thoughts) == pageSize {
-					page++
-				}
-			}
-		}
+		return listThoughts(thoughtList{
+			cmd:      "tend",
+			store:    st,
+			query:    storage.TendQuery(time.Now().UTC()),
+			empty:    "No thoughts yet.",
+			overview: 60,
+		}, opts)
 	}
 
 	if len(args) == 1 {
-		id, err := strconv.ParseInt(args[0], 10, 64)
-		if err != nil || id <= 0 {
+		if !core.LooksLikeThoughtRef(args[0]) {
 			fmt.Fprintln(os.Stderr, "tend: invalid id")
-			return 2
+			return exitUsage
+		}
+		if !interactive() {
+			fmt.Fprintf(os.Stderr, "tend: %v\n", errNeedsTerminal)
+			return exitNeedsTerminal
 		}
 
 		st, closeDB, err := openStore()
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "tend: %v\n", err)
-			return 1
+			return exitCode(err)
 		}
 		defer closeDB()
 
+		id, err := st.ResolveThoughtID(args[0])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "tend: %v\n", err)
+			return exitCode(err)
+		}
+
 		thought, _, err := st.GetTendThought(id)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "tend: %v\n", err)
-			return 1
+			return exitCode(err)
 		}
 
 		reader := bufio.NewReader(os.Stdin)
@@ -510,46 +470,46 @@ func cmdTend(args []string) int {
 		editedContent, editedNote, err := OpenEditorWithTemplate(thought.Content, "")
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "tend: edit: %v\n", err)
-			return 1
+			return exitCode(err)
 		}
 
 		ok, err := promptYesNo(reader, "Are you satisfied with the changes?")
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "tend: %v\n", err)
-			return 1
+			return exitCode(err)
 		}
 		if !ok {
-			return 0
+			return exitOK
 		}
 
 		mark, err := promptYesNo(reader, "Do you want to mark this thought as tended? (Your note will be saved only if you say yes.)")
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "tend: %v\n", err)
-			return 1
+			return exitCode(err)
 		}
 
 		if editedContent == nil {
-			return 1
+			return exitFailure
 		}
 
 		if err := st.UpdateThoughtContent(id, *editedContent); err != nil {
 			fmt.Fprintf(os.Stderr, "tend: save: %v\n", err)
-			return 1
+			return exitCode(err)
 		}
 
 		if !mark {
-			return 0
+			return exitOK
 		}
 
 		if err := st.MarkThoughtTended(id, editedNote); err != nil {
 			fmt.Fprintf(os.Stderr, "tend: mark tended: %v\n", err)
-			return 1
+			return exitCode(err)
 		}
 
 		choice, err := promptChoice(reader, "What would you like to do next?", []string{"rest", "evolve", "release", "archive"})
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "tend: %v\n", err)
-			return 1
+			return exitCode(err)
 		}
 
 		var next core.State
@@ -564,17 +524,17 @@ func cmdTend(args []string) int {
 			next = core.StateArchived
 		default:
 			fmt.Fprintf(os.Stderr, "tend: unknown choice %q\n", choice)
-			return 2
+			return exitUsage
 		}
 
 		if err := st.TransitionPostTendResolutionStrict(id, next, nil); err != nil {
 			fmt.Fprintf(os.Stderr, "tend: %v\n", err)
-			return 1
+			return exitCode(err)
 		}
 
-		return 0
+		return exitOK
 	}
-	return 0
+	return exitOK
 }
 
 // promptYesNo asks a yes/no question on stdin and returns the user's choice.
@@ -621,370 +581,325 @@ func promptChoice(reader *bufio.Reader, question string, choices []string) (stri
 	}
 }
 
-// cmdRelease permanently removes a thought (and its event history).
-func cmdRelease(args []string) int {
-	if len(args) != 1 {
-		fmt.Fprintln(os.Stderr, "release: usage: `peony release <id>`")
-		return 2
-	}
-
-	id, err := strconv.ParseInt(args[0], 10, 64)
-	if err != nil || id <= 0 {
-		fmt.Fprintln(os.Stderr, "release: invalid id")
-		return 2
-	}
-
-	st, closeDB, err := openStore()
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "release: %v\n", err)
-		return 1
-	}
-	defer closeDB()
-
-	reader := bufio.NewReader(os.Stdin)
-	ok, err := promptYesNo(reader, fmt.Sprintf("Release thought #%d? This will delete it.", id))
+// cmdEvolve displays evolved thoughts or marks a thought as evolved.
+func cmdEvolve(in command.Args) int {
+	args := in.Positional
+	opts, err := listOptionsFrom(in)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "release: %v\n", err)
-		return 1
-	}
-	if !ok {
-		return 0
+		fmt.Fprintf(os.Stderr, "evolve: %v\n", err)
+		return exitUsage
 	}
-
-	if err := st.ReleaseThought(id); err != nil {
-		fmt.Fprintf(os.Stderr, "release: %v\n", err)
-		return 1
+	if opts.set && len(args) > 0 {
+		fmt.Fprintln(os.Stderr, "evolve: --json, --limit, --offset and --no-pager list thoughts; leave out the id")
+		return exitUsage
 	}
 
-	if err := st.ReindexThoughtIDs(); err != nil {
-		fmt.Fprintf(os.Stderr, "release: reindex ids: %v\n", err)
-		return 1
-	}
-
-	fmt.Printf("Released #%d.\n", id)
-	return 0
-}
-
-// cmdEvolve displays evolved thoughts or marks a thought as evolved.
-func cmdEvolve(args []string) int {
 	if len(args) == 0 {
 		st, closeDB, err := openStore()
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "evolve: %v\n", err)
-			return 1
+			return exitCode(err)
 		}
 		defer closeDB()
 
-		reader := bufio.NewReader(os.Stdin)
-		pageSize := 10
-		page := 0
-
-		overview := func(s string) string {
-			s = strings.ReplaceAll(s, "\n", " ")
-			s = strings.TrimSpace(s)
-			const max = 60
-			if len(s) <= max {
-				return s
-			}
-			return s[:max-1] + "…"
-		}
-
-		for {
-			offset := page * pageSize
-			filter := "evolved"
-			thoughts, err := st.FilterViewByPagination(pageSize, offset, filter)
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "evolve: %v\n", err)
-				return 1
-			}
-
-			if len(thoughts) == 0 {
-				if page == 0 {
-					fmt.Println("No thoughts yet.")
-					return 0
-				}
-				page--
-				continue
-			}
-
-			fmt.Printf("Page %d\n", page+1)
-			fmt.Printf("%-6s %-10s %-5s %-20s %s\n", "ID", "STATE", "TEND", "UPDATED", "OVERVIEW")
-			for _, th := range thoughts {
-				fmt.Printf("%-6d %-10s %-5d %-20s %s\n",
-					th.ID,
-					th.CurrentState,
-					th.TendCounter,
-					th.UpdatedAt.UTC().Format("2006-01-02 15:04"),
-					overview(th.Content),
-				)
-			}
-
-			fmt.Print("[n]ext, [p]rev, [q]uit: ")
-			line, err := reader.ReadString('\n')
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "evolve: read: %v\n", err)
-				return 1
-			}
-
-			switch strings.ToLower(strings.TrimSpace(line)) {
-			case "q":
-				return 0
-			case "p":
-				if page > 0 {
-					page--
-				}
-			default:
-				if len(thoughts) == pageSize {
-					page++
-				}
-			}
-		}
+		return listThoughts(thoughtList{
+			cmd:      "evolve",
+			store:    st,
+			query:    storage.StateQuery(core.StateEvolved),
+			empty:    "No thoughts yet.",
+			overview: 60,
+		}, opts)
 	}
 	if len(args) == 1 {
-		id, err := strconv.ParseInt(args[0], 10, 64)
-		if err != nil || id <= 0 {
+		if !core.LooksLikeThoughtRef(args[0]) {
 			fmt.Fprintln(os.Stderr, "evolve: invalid id")
-			return 2
+			return exitUsage
 		}
 
 		st, closeDB, err := openStore()
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "evolve: %v\n", err)
-			return 1
+			return exitCode(err)
 		}
 		defer closeDB()
 
+		id, err := st.ResolveThoughtID(args[0])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "evolve: %v\n", err)
+			return exitCode(err)
+		}
+
 		if err := st.ToEvolve(id); err != nil {
 			fmt.Fprintf(os.Stderr, "evolve: %v\n", err)
-			return 1
+			return exitCode(err)
 		}
 
 		fmt.Printf("Evolved #%d.\n", id)
 	}
-	return 0
+	return exitOK
 }
 
-func cmdHelp(args []string) int {
-	if len(args) == 0 {
-		PrintHelp()
-		return 0
+// cmdTag lists tags in use, shows a thought's tags, or adds (+name) and removes (-name) tags on a thought.
+func cmdTag(in command.Args) int {
+	st, closeDB, err := openStore()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "tag: %v\n", err)
+		return exitCode(err)
 	}
+	defer closeDB()
 
-	filter := strings.TrimPrefix(args[0], "--")
-
-	switch filter {
-	case "add", "--add":
-		fmt.Print(`peony add — capture a thought
-
-Description:
-  Captures a new thought and stores it in the captured state.
-  The thought will rest for a configured duration before becoming eligible to tend.
-
-Syntax:
-  peony add [content]
-  peony a [content]
-
-Examples:
-  peony add "I wonder if I should learn Rust"
-  peony add
-  (prompts interactively if no content provided)
-
-`)
-
-	case "view", "--view":
-		fmt.Print(`peony view — view thoughts
-
-Description:
-  View a paginated list of thoughts, a single thought by ID, or filter by state.
-  Without arguments, shows all non-archived thoughts.
-
-Syntax:
-  peony view [id]
-  peony view [--filter | filter]
-  peony v [id]
-
-Filters:
-  captured, resting, tended, evolved, released, archived
-
-Examples:
-  peony view
-  peony view 12
-  peony view --archived
-  peony view captured
-
-`)
-
-	case "tend", "--tend":
-		fmt.Print(`peony tend — work with thoughts ready for tending
-
-Description:
-  Lists thoughts that are eligible to tend, or opens an interactive editor
-  to tend a specific thought by ID.
-
-Syntax:
-  peony tend [id]
-  peony t [id]
-
-Examples:
-  peony tend
-  peony tend 5
-
-`)
-
-	case "release", "--release":
-		fmt.Print(`peony release — remove a thought permanently
-
-Description:
-  Permanently deletes a thought and its event history from Peony.
-  This action cannot be undone.
-
-Syntax:
-  peony release <id>
-  peony r <id>
-
-Examples:
-  peony release 8
-  peony r 3
-
-`)
-
-	case "evolve", "--evolve":
-		fmt.Print(`peony evolve — mark a thought as evolved
-
-Description:
-  Transitions a thought into the evolved state, indicating it has been
-  integrated into your wider workflow (e.g., a task manager or notes app).
-
-Syntax:
-  peony evolve [id]
-  peony e [id]
-
-Examples:
-  peony evolve 7
-  peony e
-  (lists evolved thoughts if no ID provided)
-
-`)
-
-	case "config", "--config":
-		fmt.Print(`peony config — view and configure defaults
-
-Description:
-  View or update configuration settings like editor and settle duration.
+	args := in.Positional
+	opts, err := listOptionsFrom(in)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "tag: %v\n", err)
+		return exitUsage
+	}
+	if opts.set && len(args) > 0 {
+		fmt.Fprintln(os.Stderr, "tag: --json, --limit, --offset and --no-pager list tags; leave out the id")
+		return exitUsage
+	}
 
-Syntax:
-  peony config
-  peony c
-  peony config [--editor | editor]
-  peony config [--settleDuration | settleDuration] 
+	if len(args) == 0 {
+		tags, err := st.ListTags()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "tag: %v\n", err)
+			return exitCode(err)
+		}
+		tags = window(tags, opts)
+		if opts.json {
+			rows := make([]listedTag, 0, len(tags))
+			for _, tag := range tags {
+				rows = append(rows, listedTag{Name: tag.Name, Thoughts: tag.Count})
+			}
+			if err := printJSON(rows); err != nil {
+				fmt.Fprintf(os.Stderr, "tag: %v\n", err)
+				return exitFailure
+			}
+			return exitOK
+		}
+		if len(tags) == 0 {
+			fmt.Println("No tags yet.")
+			return exitOK
+		}
+		fmt.Printf("%-24s %s\n", "TAG", "THOUGHTS")
+		for _, tag := range tags {
+			fmt.Printf("%-24s %d\n", "#"+tag.Name, tag.Count)
+		}
+		return exitOK
+	}
 
-Examples:
-  peony config
-  peony config --editor
-  peony config settleDuration 24h
-  peony c settleDuration
+	if !core.LooksLikeThoughtRef(args[0]) {
+		fmt.Fprintln(os.Stderr, "tag: invalid id")
+		return exitUsage
+	}
 
-`)
+	var add, remove []string
+	for _, arg := range args[1:] {
+		switch {
+		case strings.HasPrefix(arg, "+"):
+			add = append(add, arg[1:])
+		case strings.HasPrefix(arg, "-"):
+			remove = append(remove, arg[1:])
+		default:
+			add = append(add, arg)
+		}
+	}
 
-	case "tui", "--tui":
-		fmt.Print(`peony tui — open Bloom, the terminal garden
+	id, err := st.ResolveThoughtID(args[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "tag: %v\n", err)
+		return exitCode(err)
+	}
 
-Description:
-  Opens Bloom, Peony's full-screen Bubble Tea interface for browsing,
-  tending, searching, filtering, and resolving thoughts.
+	var tags []string
+	if len(add) == 0 && len(remove) == 0 {
+		if _, _, err := st.GetThought(id); err != nil {
+			fmt.Fprintf(os.Stderr, "tag: %v\n", err)
+			return exitCode(err)
+		}
+		tags, err = st.ThoughtTags(id)
+	} else {
+		tags, err = st.UpdateThoughtTags(id, add, remove)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "tag: %v\n", err)
+		return exitCode(err)
+	}
 
-Syntax:
-  peony tui
+	if len(tags) == 0 {
+		fmt.Printf("#%d has no tags.\n", id)
+		return exitOK
+	}
+	fmt.Printf("#%d tags: %s\n", id, formatTags(tags))
+	return exitOK
+}
 
-Optional shell alias:
-  bloom
+// listedTag is one row of peony tag --json.
+type listedTag struct {
+	Name     string `json:"name"`
+	Thoughts int    `json:"thoughts"`
+}
 
-Examples:
-  peony tui
-  bloom  (if installed with install.sh --alias)
+// formatTags renders tag names as space-separated #chips.
+func formatTags(tags []string) string {
+	chips := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		chips = append(chips, "#"+tag)
+	}
+	return strings.Join(chips, " ")
+}
 
-`)
+// cmdHelp prints the command list, or the help page of one command.
+func cmdHelp(in command.Args) int {
+	args := in.Positional
+	if len(args) == 0 {
+		PrintHelp()
+		return exitOK
+	}
 
-	default:
+	spec, ok := command.Lookup(strings.TrimPrefix(args[0], "--"))
+	if !ok || spec.Hidden {
 		fmt.Fprintf(os.Stderr, "No help available for: %s\n", args[0])
 		PrintHelp()
-		return 2
+		return exitUsage
+	}
+	fmt.Print(spec.Page())
+	return exitOK
+}
+
+// cmdVersion prints the version, or with --verbose everything known about the build.
+func cmdVersion(in command.Args) int {
+	if len(in.Positional) > 0 {
+		fmt.Fprintf(os.Stderr, "version: unknown argument %s\n", in.Positional[0])
+		return exitUsage
+	}
+	if !in.Has("--verbose") {
+		fmt.Println("Peony " + version.String())
+		return exitOK
+	}
+	for _, line := range version.Get().Lines() {
+		fmt.Println(line)
 	}
-	return 0
+	fmt.Printf("Schema:  %d\n", storage.SchemaVersion)
+	return exitOK
 }
 
 // TUIRunner is the function used by RunPeony to start Bloom.
 // Tests may replace it to verify dispatch without starting an interactive program.
 var TUIRunner = tui.Run
 
-// RunPeony dispatches CLI commands to their corresponding handlers.
-func RunPeony(args []string) int {
-	if len(args) == 0 {
-		PrintHelp()
-		return 0
-	}
-
-	_, _ = loadRuntimeConfig()
-
-	cmd := args[0]
-	rest := args[1:]
+// WebRunner is the function used by RunPeony to serve the read-only WebUI.
+// Tests may replace it to verify dispatch without binding a port.
+var WebRunner = web.Run
 
-	// Print only when the eligible count changes, and only for classic commands.
-	shouldPrintNotice := false
-	switch cmd {
-	case "view", "v", "release", "r", "evolve", "e", "configure", "config", "c":
-		shouldPrintNotice = true
+// cmdWeb parses `peony web` flags and starts the read-only WebUI.
+func cmdWeb(in command.Args) int {
+	if len(in.Positional) > 0 {
+		fmt.Fprintf(os.Stderr, "web: unknown argument %s\n", in.Positional[0])
+		return exitUsage
 	}
-	if shouldPrintNotice {
-		st, closeDB, err := openStore()
-		if err == nil {
-			defer closeDB()
-			n, err := st.CountTendReady()
-			if err == nil && n > 0 {
-				changed := st.DidCountTendChange(n)
-				if changed {
-					fmt.Fprintf(os.Stderr, "🌱 %d thoughts feel ready for tending. Run: peony tend\n", n)
-				}
-			}
+
+	port := web.DefaultPort
+	if value, ok := in.Value("--port"); ok {
+		n, err := strconv.Atoi(value)
+		if err != nil || n <= 0 || n > 65535 {
+			fmt.Fprintln(os.Stderr, "web: invalid port")
+			return exitUsage
 		}
+		port = n
 	}
+	return WebRunner(port)
+}
 
-	switch cmd {
-	case "help", "h":
-		return cmdHelp(rest)
+// cmdTUI opens Bloom.
+func cmdTUI(in command.Args) int {
+	if len(in.Positional) != 0 {
+		fmt.Fprintln(os.Stderr, "tui: this command does not accept arguments yet")
+		return exitUsage
+	}
+	return TUIRunner()
+}
 
-	case "version", "-v":
-		fmt.Println("Peony " + Version)
-		return 0
+// cliCommand is how the command line runs one of command.Specs.
+type cliCommand struct {
+	run    func(command.Args) int
+	notice bool // say how many thoughts are ready, when that number changed
+	purge  bool // empty expired releases out of the trash first
+}
 
-	case "add", "a":
-		return cmdAdd(rest)
+// cliCommands binds every command in command.Specs to its handler.
+var cliCommands = map[string]cliCommand{
+	"help":       {run: cmdHelp},
+	"version":    {run: cmdVersion},
+	"add":        {run: cmdAdd},
+	"view":       {run: cmdView, notice: true, purge: true},
+	"tend":       {run: cmdTend, purge: true},
+	"release":    {run: cmdRelease, notice: true, purge: true},
+	"evolve":     {run: cmdEvolve, notice: true},
+	"rest":       {run: cmdRest},
+	"archive":    {run: cmdArchive},
+	"revive":     {run: cmdRevive},
+	"trash":      {run: cmdTrash, purge: true},
+	"restore":    {run: cmdRestore, purge: true},
+	"tag":        {run: cmdTag},
+	"feel":       {run: cmdFeel},
+	"search":     {run: cmdSearch},
+	"config":     {run: cmdConfigure, notice: true},
+	"export":     {run: cmdExport},
+	"import":     {run: cmdImport},
+	"backup":     {run: cmdBackup},
+	"db":         {run: cmdDB},
+	"tui":        {run: cmdTUI, purge: true},
+	"web":        {run: cmdWeb},
+	"completion": {run: cmdCompletion},
+	"__complete": {run: cmdComplete},
+}
 
-	case "view", "v":
-		return cmdView(rest)
+// RunPeony looks the command up in command.Specs, parses its flags, and runs its handler.
+func RunPeony(args []string) int {
+	if len(args) == 0 {
+		PrintHelp()
+		return exitOK
+	}
 
-	case "tend", "t":
-		return cmdTend(rest)
+	_, _ = loadRuntimeConfig()
 
-	case "release", "r":
-		return cmdRelease(rest)
+	spec, ok := command.Lookup(args[0])
+	cmd, bound := cliCommands[spec.Name]
+	if !ok || !bound {
+		fmt.Fprintf(os.Stderr, "Unknown command: %s\n", args[0])
+		if suggestion := command.Suggest(args[0]); suggestion != "" {
+			fmt.Fprintf(os.Stderr, "Did you mean: peony %s?\n", suggestion)
+		}
+		PrintHelp()
+		return exitUsage
+	}
 
-	case "evolve", "e":
-		return cmdEvolve(rest)
+	in, err := spec.Parse(args[1:])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", spec.Name, err)
+		return exitUsage
+	}
 
-	case "configure", "config", "c":
-		return cmdConfigure(rest)
+	if cmd.purge {
+		purgeExpiredReleases()
+	}
+	if cmd.notice {
+		printReadyNotice()
+	}
+	return cmd.run(in)
+}
 
-	case "tui":
-		if len(rest) != 0 {
-			fmt.Fprintln(os.Stderr, "tui: this command does not accept arguments yet")
-			return 2
-		}
-		return TUIRunner()
+// printReadyNotice mentions thoughts ready for tending, only when their number has changed.
+func printReadyNotice() {
+	st, closeDB, err := openStore()
+	if err != nil {
+		return
+	}
+	defer closeDB()
 
-	default:
-		fmt.Fprintf(os.Stderr, "Unknown command: %s\n", cmd)
-		PrintHelp()
-		return 2
+	n, err := st.CountTendReady()
+	if err == nil && n > 0 && st.DidCountTendChange(n) {
+		fmt.Fprintf(os.Stderr, "🌱 %d thoughts feel ready for tending. Run: peony tend\n", n)
 	}
 }
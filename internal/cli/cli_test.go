@@ -1,11 +1,25 @@
 package cli
 
 import (
+	"bufio"
 	"bytes"
+	"encoding/json"
+	"fmt"
 	"io"
 	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/divijg19/peony/internal/command"
+	"github.com/divijg19/peony/internal/config"
+	"github.com/divijg19/peony/internal/core"
+	"github.com/divijg19/peony/internal/exchange"
+	"github.com/divijg19/peony/internal/storage"
+	"github.com/divijg19/peony/internal/version"
 )
 
 func TestRunPeonyTUILaunchesRunner(t *testing.T) {
@@ -60,6 +74,30 @@ func TestRunPeonyHelpTUI(t *testing.T) {
 	}
 }
 
+func TestRunPeonyVersionReportsBuildAndSchema(t *testing.T) {
+	output := captureStdout(t, func() {
+		if code := RunPeony([]string{"version"}); code != exitOK {
+			t.Fatalf("version exit code = %d, want %d", code, exitOK)
+		}
+	})
+	if output != "Peony "+version.String()+"\n" {
+		t.Fatalf("version output = %q", output)
+	}
+
+	output = captureStdout(t, func() {
+		if code := RunPeony([]string{"-v", "--verbose"}); code != exitOK {
+			t.Fatalf("version --verbose exit code = %d, want %d", code, exitOK)
+		}
+	})
+	if !strings.HasPrefix(output, "Peony "+version.String()+"\n") || !strings.Contains(output, fmt.Sprintf("Schema:  %d\n", storage.SchemaVersion)) {
+		t.Fatalf("version --verbose output = %q", output)
+	}
+
+	if code := RunPeony([]string{"version", "--short"}); code != exitUsage {
+		t.Fatalf("version --short exit code = %d, want %d", code, exitUsage)
+	}
+}
+
 func TestRunPeonyBloomIsNotACommand(t *testing.T) {
 	previous := TUIRunner
 	defer func() {
@@ -105,3 +143,1261 @@ func captureStdout(t *testing.T, fn func()) string {
 	}
 	return buf.String()
 }
+
+func TestRunPeonyWebParsesPortAndLaunchesRunner(t *testing.T) {
+	previous := WebRunner
+	defer func() {
+		WebRunner = previous
+	}()
+
+	var gotPort int
+	WebRunner = func(port int) int {
+		gotPort = port
+		return 0
+	}
+
+	if code := RunPeony([]string{"web", "--port", "8081"}); code != 0 {
+		t.Fatalf("exit code = %d, want 0", code)
+	}
+	if gotPort != 8081 {
+		t.Fatalf("port = %d, want 8081", gotPort)
+	}
+
+	gotPort = 0
+	if code := RunPeony([]string{"web", "--port", "nope"}); code != 2 {
+		t.Fatalf("invalid port exit code = %d, want 2", code)
+	}
+	if gotPort != 0 {
+		t.Fatal("runner should not start with an invalid port")
+	}
+}
+
+// useTempGarden points the CLI at a throwaway database and config directory.
+func useTempGarden(t *testing.T) {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("PEONY_DB_PATH", filepath.Join(dir, "peony.db"))
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(dir, "config"))
+	t.Setenv("HOME", dir)
+}
+
+func TestRunPeonyTagAddsRemovesAndListsTags(t *testing.T) {
+	useTempGarden(t)
+
+	captureStdout(t, func() {
+		if code := RunPeony([]string{"add", "tag me gently"}); code != 0 {
+			t.Fatalf("add exit code = %d, want 0", code)
+		}
+	})
+
+	output := captureStdout(t, func() {
+		if code := RunPeony([]string{"tag", "1", "+Work", "+later"}); code != 0 {
+			t.Fatalf("tag exit code = %d, want 0", code)
+		}
+	})
+	if !strings.Contains(output, "#1 tags: #later #work") {
+		t.Fatalf("tag output = %q", output)
+	}
+
+	output = captureStdout(t, func() {
+		if code := RunPeony([]string{"tag", "1", "-later"}); code != 0 {
+			t.Fatalf("untag exit code = %d, want 0", code)
+		}
+	})
+	if !strings.Contains(output, "#1 tags: #work") {
+		t.Fatalf("untag output = %q", output)
+	}
+
+	output = captureStdout(t, func() {
+		if code := RunPeony([]string{"tag"}); code != 0 {
+			t.Fatalf("list exit code = %d, want 0", code)
+		}
+	})
+	if !strings.Contains(output, "#work") || strings.Contains(output, "#later") {
+		t.Fatalf("tag list output = %q", output)
+	}
+
+	if code := RunPeony([]string{"tag", "1", "+9lives"}); code != 1 {
+		t.Fatalf("invalid tag exit code = %d, want 1", code)
+	}
+	if code := RunPeony([]string{"view", "--tag", "!"}); code != 2 {
+		t.Fatalf("invalid view tag exit code = %d, want 2", code)
+	}
+}
+
+func TestRunPeonyDBStatusReportsPendingWithoutMigrating(t *testing.T) {
+	useTempGarden(t)
+
+	output := captureStdout(t, func() {
+		if code := RunPeony([]string{"db", "status"}); code != 0 {
+			t.Fatalf("db status exit code = %d, want 0", code)
+		}
+	})
+	if !strings.Contains(output, "Not created yet") || !strings.Contains(output, "pending") {
+		t.Fatalf("fresh db status output = %q", output)
+	}
+	if _, err := os.Stat(os.Getenv("PEONY_DB_PATH")); !os.IsNotExist(err) {
+		t.Fatalf("db status should not create the database, stat err = %v", err)
+	}
+
+	captureStdout(t, func() {
+		if code := RunPeony([]string{"add", "make the schema"}); code != 0 {
+			t.Fatalf("add exit code = %d, want 0", code)
+		}
+	})
+	output = captureStdout(t, func() {
+		if code := RunPeony([]string{"db", "status"}); code != 0 {
+			t.Fatalf("db status exit code = %d, want 0", code)
+		}
+	})
+	if strings.Contains(output, "pending") || !strings.Contains(output, "applied") {
+		t.Fatalf("migrated db status output = %q", output)
+	}
+
+	if code := RunPeony([]string{"db", "later"}); code != 2 {
+		t.Fatalf("unknown db subcommand exit code = %d, want 2", code)
+	}
+}
+
+func TestRunPeonyExportWritesVersionedJSONAndNDJSON(t *testing.T) {
+	useTempGarden(t)
+
+	captureStdout(t, func() {
+		for _, content := range []string{"first export", "second export"} {
+			if code := RunPeony([]string{"add", content}); code != 0 {
+				t.Fatalf("add exit code = %d, want 0", code)
+			}
+		}
+	})
+
+	output := captureStdout(t, func() {
+		if code := RunPeony([]string{"export"}); code != 0 {
+			t.Fatalf("export exit code = %d, want 0", code)
+		}
+	})
+	var doc struct {
+		Format   string `json:"format"`
+		Version  int    `json:"version"`
+		Thoughts []struct {
+			ID     int64  `json:"id"`
+			State  string `json:"state"`
+			Events []struct {
+				Kind string `json:"kind"`
+			} `json:"events"`
+		} `json:"thoughts"`
+	}
+	if err := json.Unmarshal([]byte(output), &doc); err != nil {
+		t.Fatalf("decode json export: %v\n%s", err, output)
+	}
+	if doc.Format != "peony.export" || doc.Version != 1 || len(doc.Thoughts) != 2 {
+		t.Fatalf("json export = %+v", doc)
+	}
+	if doc.Thoughts[0].State != "captured" || len(doc.Thoughts[0].Events) != 1 || doc.Thoughts[0].Events[0].Kind != "captured" {
+		t.Fatalf("json export thought = %+v", doc.Thoughts[0])
+	}
+
+	output = captureStdout(t, func() {
+		if code := RunPeony([]string{"export", "--format=ndjson", "--state", "captured"}); code != 0 {
+			t.Fatalf("ndjson export exit code = %d, want 0", code)
+		}
+	})
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	lines := 0
+	for scanner.Scan() {
+		var line map[string]any
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			t.Fatalf("decode ndjson line %d: %v", lines, err)
+		}
+		if lines == 0 && line["format"] != "peony.export" {
+			t.Fatalf("ndjson header = %v", line)
+		}
+		if lines > 0 && line["content"] == nil {
+			t.Fatalf("ndjson thought line = %v", line)
+		}
+		lines++
+	}
+	if lines != 3 {
+		t.Fatalf("ndjson lines = %d, want header plus 2 thoughts", lines)
+	}
+
+	for _, args := range [][]string{
+		{"export", "--format", "xml"},
+		{"export", "--state", "later"},
+		{"export", "--since", "yesterday"},
+		{"export", "--since"},
+	} {
+		if code := RunPeony(args); code != 2 {
+			t.Fatalf("%v exit code = %d, want 2", args, code)
+		}
+	}
+}
+
+func TestRunPeonyImportRoundTripsAnExport(t *testing.T) {
+	useTempGarden(t)
+
+	captureStdout(t, func() {
+		if code := RunPeony([]string{"add", "carry me over"}); code != 0 {
+			t.Fatalf("add exit code = %d, want 0", code)
+		}
+		if code := RunPeony([]string{"tag", "1", "+moving"}); code != 0 {
+			t.Fatalf("tag exit code = %d, want 0", code)
+		}
+	})
+	exported := captureStdout(t, func() {
+		if code := RunPeony([]string{"export", "--format", "ndjson"}); code != 0 {
+			t.Fatalf("export exit code = %d, want 0", code)
+		}
+	})
+	file := filepath.Join(t.TempDir(), "garden.ndjson")
+	if err := os.WriteFile(file, []byte(exported), 0o644); err != nil {
+		t.Fatalf("write export: %v", err)
+	}
+
+	// Move to a new machine: a fresh database.
+	t.Setenv("PEONY_DB_PATH", filepath.Join(t.TempDir(), "new.db"))
+	captureStdout(t, func() {
+		if code := RunPeony([]string{"add", "already on the new machine"}); code != 0 {
+			t.Fatalf("add exit code = %d, want 0", code)
+		}
+	})
+
+	output := captureStdout(t, func() {
+		if code := RunPeony([]string{"import", file, "--dry-run"}); code != 0 {
+			t.Fatalf("dry-run exit code = %d, want 0", code)
+		}
+	})
+	if !strings.Contains(output, "Would import 1 thoughts with 2 events.") || !strings.Contains(output, "#1 -> #2") {
+		t.Fatalf("dry-run output = %q", output)
+	}
+
+	output = captureStdout(t, func() {
+		if code := RunPeony([]string{"import", file, "--skip-duplicates"}); code != 0 {
+			t.Fatalf("import exit code = %d, want 0", code)
+		}
+	})
+	if !strings.Contains(output, "Imported 1 thoughts") {
+		t.Fatalf("import output = %q", output)
+	}
+
+	output = captureStdout(t, func() {
+		if code := RunPeony([]string{"import", file, "--skip-duplicates"}); code != 0 {
+			t.Fatalf("second import exit code = %d, want 0", code)
+		}
+	})
+	if !strings.Contains(output, "Imported 0 thoughts") || !strings.Contains(output, "Skipped 1 duplicates: #1") {
+		t.Fatalf("second import output = %q", output)
+	}
+
+	output = captureStdout(t, func() {
+		if code := RunPeony([]string{"tag", "2"}); code != 0 {
+			t.Fatalf("tag exit code = %d, want 0", code)
+		}
+	})
+	if !strings.Contains(output, "#moving") {
+		t.Fatalf("imported tags output = %q", output)
+	}
+
+	if code := RunPeony([]string{"import"}); code != 2 {
+		t.Fatalf("missing file exit code = %d, want 2", code)
+	}
+	notExport := filepath.Join(t.TempDir(), "other.json")
+	if err := os.WriteFile(notExport, []byte(`{"format":"elsewhere","version":1}`), 0o644); err != nil {
+		t.Fatalf("write other: %v", err)
+	}
+	if code := RunPeony([]string{"import", notExport}); code != 1 {
+		t.Fatalf("foreign file exit code = %d, want 1", code)
+	}
+}
+
+func TestRunPeonyMarkdownExportAndImportRoundTrip(t *testing.T) {
+	useTempGarden(t)
+
+	captureStdout(t, func() {
+		if code := RunPeony([]string{"add", "I want to build a log cabin"}); code != 0 {
+			t.Fatalf("add exit code = %d, want 0", code)
+		}
+		if code := RunPeony([]string{"tag", "1", "+cabin", "+someday"}); code != 0 {
+			t.Fatalf("tag exit code = %d, want 0", code)
+		}
+	})
+	before := captureStdout(t, func() {
+		if code := RunPeony([]string{"export"}); code != 0 {
+			t.Fatalf("export exit code = %d, want 0", code)
+		}
+	})
+
+	dir := filepath.Join(t.TempDir(), "notes")
+	output := captureStdout(t, func() {
+		if code := RunPeony([]string{"export", "--format", "markdown", "--dir", dir}); code != 0 {
+			t.Fatalf("markdown export exit code = %d, want 0", code)
+		}
+	})
+	if !strings.Contains(output, "Wrote 1 thoughts to "+dir) {
+		t.Fatalf("markdown export output = %q", output)
+	}
+	files, err := filepath.Glob(filepath.Join(dir, "*.md"))
+	if err != nil || len(files) != 1 {
+		t.Fatalf("markdown files = %v, %v; want one", files, err)
+	}
+	if !strings.HasSuffix(files[0], "-i-want-to-build-a-log-cabin.md") {
+		t.Fatalf("markdown file name = %q", files[0])
+	}
+	data, err := os.ReadFile(files[0])
+	if err != nil {
+		t.Fatalf("read markdown: %v", err)
+	}
+	text := string(data)
+	for _, want := range []string{"---\nid: 1\n", "state: captured\n", "valence: null\n", "tend_counter: 0\n", "tags: [cabin, someday]\n", "\n\nI want to build a log cabin\n\n## History\n\n", " captured ( → captured)\n", " tagged\n  > +cabin +someday\n"} {
+		if !strings.Contains(text, want) {
+			t.Fatalf("markdown file missing %q:\n%s", want, text)
+		}
+	}
+
+	if code := RunPeony([]string{"export", "--format", "markdown"}); code != 2 {
+		t.Fatalf("markdown without --dir exit code = %d, want 2", code)
+	}
+	if code := RunPeony([]string{"export", "--dir", dir}); code != 2 {
+		t.Fatalf("--dir with json exit code = %d, want 2", code)
+	}
+
+	// A hand-written note without front matter sits next to the export.
+	if err := os.WriteFile(filepath.Join(dir, "loose.md"), []byte("a loose note\n"), 0o644); err != nil {
+		t.Fatalf("write loose note: %v", err)
+	}
+
+	t.Setenv("PEONY_DB_PATH", filepath.Join(t.TempDir(), "new.db"))
+	output = captureStdout(t, func() {
+		if code := RunPeony([]string{"import", "--format", "markdown", dir}); code != 0 {
+			t.Fatalf("markdown import exit code = %d, want 0", code)
+		}
+	})
+	if !strings.Contains(output, "Imported 2 thoughts with 3 events.") {
+		t.Fatalf("markdown import output = %q", output)
+	}
+
+	after := captureStdout(t, func() {
+		if code := RunPeony([]string{"export"}); code != 0 {
+			t.Fatalf("export exit code = %d, want 0", code)
+		}
+	})
+	var want, got exchange.Document
+	if err := json.Unmarshal([]byte(before), &want); err != nil {
+		t.Fatalf("decode original export: %v", err)
+	}
+	if err := json.Unmarshal([]byte(after), &got); err != nil {
+		t.Fatalf("decode round-trip export: %v", err)
+	}
+	if len(got.Thoughts) != 2 {
+		t.Fatalf("round-trip thoughts = %d, want 2", len(got.Thoughts))
+	}
+	original, restored := want.Thoughts[0], got.Thoughts[0]
+	original.Events, restored.Events = stripEventIDs(original.Events), stripEventIDs(restored.Events)
+	if !reflect.DeepEqual(original, restored) {
+		t.Fatalf("round-trip thought =\n%+v\nwant\n%+v", restored, original)
+	}
+	if loose := got.Thoughts[1]; loose.Content != "a loose note" || loose.State != core.StateCaptured || len(loose.Events) != 1 {
+		t.Fatalf("loose note = %+v", loose)
+	}
+}
+
+func stripEventIDs(events []exchange.Event) []exchange.Event {
+	out := make([]exchange.Event, len(events))
+	for i, event := range events {
+		event.ID = 0
+		out[i] = event
+	}
+	return out
+}
+
+func TestRunPeonySearchRanksAndHighlights(t *testing.T) {
+	useTempGarden(t)
+
+	captureStdout(t, func() {
+		for _, content := range []string{"a cabin, a cabin, a log cabin", "paint the cabin door", "a treehouse"} {
+			if code := RunPeony([]string{"add", content}); code != 0 {
+				t.Fatalf("add exit code = %d, want 0", code)
+			}
+		}
+	})
+
+	output := captureStdout(t, func() {
+		if code := RunPeony([]string{"search", "cabin"}); code != 0 {
+			t.Fatalf("search exit code = %d, want 0", code)
+		}
+	})
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], "#1 ") || !strings.HasPrefix(lines[1], "#2 ") {
+		t.Fatalf("search output = %q, want #1 ranked above #2", output)
+	}
+	if !strings.Contains(lines[1], "paint the [cabin] door") || !strings.Contains(lines[1], "captured") {
+		t.Fatalf("search line = %q, want highlighted snippet and state", lines[1])
+	}
+
+	output = captureStdout(t, func() {
+		if code := RunPeony([]string{"search", `"log cabin"`, "OR", "tree*", "--limit", "5"}); code != 0 {
+			t.Fatalf("boolean search exit code = %d, want 0", code)
+		}
+	})
+	if !strings.Contains(output, "#1 ") || !strings.Contains(output, "#3 ") || strings.Contains(output, "#2 ") {
+		t.Fatalf("boolean search output = %q", output)
+	}
+
+	output = captureStdout(t, func() {
+		if code := RunPeony([]string{"search", "igloo"}); code != 0 {
+			t.Fatalf("empty search exit code = %d, want 0", code)
+		}
+	})
+	if !strings.Contains(output, "Nothing in the garden matches that.") {
+		t.Fatalf("empty search output = %q", output)
+	}
+
+	if code := RunPeony([]string{"search"}); code != 2 {
+		t.Fatalf("missing query exit code = %d, want 2", code)
+	}
+	if code := RunPeony([]string{"search", "--limit", "0", "cabin"}); code != 2 {
+		t.Fatalf("bad limit exit code = %d, want 2", code)
+	}
+}
+
+func TestRunPeonyAddAndFeelRecordValenceAndEnergy(t *testing.T) {
+	useTempGarden(t)
+
+	captureStdout(t, func() {
+		if code := RunPeony([]string{"add", "--valence", "-2", "a", "hard", "week", "--energy=1"}); code != 0 {
+			t.Fatalf("add exit code = %d, want 0", code)
+		}
+	})
+
+	output := captureStdout(t, func() {
+		if code := RunPeony([]string{"feel", "1"}); code != 0 {
+			t.Fatalf("feel exit code = %d, want 0", code)
+		}
+	})
+	if strings.TrimSpace(output) != "#1 valence -2, energy 1" {
+		t.Fatalf("feel output = %q", output)
+	}
+
+	output = captureStdout(t, func() {
+		if code := RunPeony([]string{"feel", "1", "--valence", "+1"}); code != 0 {
+			t.Fatalf("feel set exit code = %d, want 0", code)
+		}
+	})
+	if strings.TrimSpace(output) != "#1 now feels valence +1, energy 1" {
+		t.Fatalf("feel set output = %q", output)
+	}
+
+	output = captureStdout(t, func() {
+		if code := RunPeony([]string{"view", "1"}); code != 0 {
+			t.Fatalf("view exit code = %d, want 0", code)
+		}
+	})
+	if !strings.Contains(output, "a hard week") || !strings.Contains(output, "Feeling:  valence +1, energy 1") {
+		t.Fatalf("view output = %q", output)
+	}
+	if strings.Count(output, "feel\n") != 2 || !strings.Contains(output, "note: valence -2, energy 1") {
+		t.Fatalf("view should list both feel events:\n%s", output)
+	}
+
+	if code := RunPeony([]string{"add", "too bright", "--valence", "3"}); code != 2 {
+		t.Fatalf("out of range valence exit code = %d, want 2", code)
+	}
+	if code := RunPeony([]string{"feel", "1", "--energy", "lots"}); code != 2 {
+		t.Fatalf("non-numeric energy exit code = %d, want 2", code)
+	}
+	if code := RunPeony([]string{"feel"}); code != 2 {
+		t.Fatalf("missing id exit code = %d, want 2", code)
+	}
+}
+
+func TestRunPeonyConfigSetsConnectionOptions(t *testing.T) {
+	useTempGarden(t)
+	previous := storage.Connection
+	t.Cleanup(func() { storage.Connection = previous })
+
+	output := captureStdout(t, func() {
+		if code := RunPeony([]string{"config", "journalMode", "DELETE", "busyTimeout", "1500ms"}); code != exitOK {
+			t.Fatalf("config exit code = %d, want %d", code, exitOK)
+		}
+	})
+	if !strings.Contains(output, "JournalMode: delete\n") || !strings.Contains(output, "BusyTimeout: 1.5s\n") {
+		t.Fatalf("config output = %q", output)
+	}
+	if want := (storage.Options{JournalMode: "delete", BusyTimeout: 1500 * time.Millisecond}); storage.Connection != want {
+		t.Fatalf("connection = %+v, want %+v", storage.Connection, want)
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+	if cfg.JournalMode != "delete" || cfg.BusyTimeout != "1.5s" {
+		t.Fatalf("saved config = %+v", cfg)
+	}
+
+	for _, args := range [][]string{
+		{"config", "journalMode", "memory"},
+		{"config", "busyTimeout", "-1s"},
+		{"config", "busyTimeout", "soon"},
+	} {
+		if code := RunPeony(args); code != exitUsage {
+			t.Fatalf("%q exit code = %d, want %d", args, code, exitUsage)
+		}
+	}
+}
+
+func TestRunPeonyConfigSettleDurationTakesDaysAndWeeks(t *testing.T) {
+	useTempGarden(t)
+	previous := core.SettleDuration
+	t.Cleanup(func() { core.SettleDuration = previous })
+
+	captureStdout(t, func() {
+		if code := RunPeony([]string{"config", "settleDuration", "1d12h"}); code != exitOK {
+			t.Fatalf("config exit code = %d, want %d", code, exitOK)
+		}
+	})
+	if core.SettleDuration != 36*time.Hour {
+		t.Fatalf("settle duration = %v, want 36h", core.SettleDuration)
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+	if cfg.SettleDuration != "1d12h" || config.SettleDuration(cfg) != 36*time.Hour {
+		t.Fatalf("saved settle duration = %q", cfg.SettleDuration)
+	}
+
+	for _, value := range []string{"0s", "soon"} {
+		if code := RunPeony([]string{"config", "settleDuration", value}); code != exitUsage {
+			t.Fatalf("settleDuration %q exit code = %d, want %d", value, code, exitUsage)
+		}
+	}
+}
+
+func TestRunPeonyRestForChoosesHowLongAThoughtSettles(t *testing.T) {
+	useTempGarden(t)
+
+	captureStdout(t, func() {
+		if code := RunPeony([]string{"add", "a", "slow", "idea"}); code != 0 {
+			t.Fatalf("add exit code = %d, want 0", code)
+		}
+	})
+	st, closeDB, err := openStore()
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	if err := st.MarkThoughtTended(1, nil); err != nil {
+		t.Fatalf("mark tended: %v", err)
+	}
+	closeDB()
+
+	if code := RunPeony([]string{"rest", "1", "--for", "soon"}); code != 2 {
+		t.Fatalf("bad duration exit code = %d, want 2", code)
+	}
+
+	before := time.Now().UTC()
+	output := captureStdout(t, func() {
+		if code := RunPeony([]string{"rest", "1", "--for", "3d"}); code != 0 {
+			t.Fatalf("rest exit code = %d, want 0", code)
+		}
+	})
+	if !strings.HasPrefix(output, "#1 rests for 3d, until ") {
+		t.Fatalf("rest output = %q", output)
+	}
+
+	st, closeDB, err = openStore()
+	if err != nil {
+		t.Fatalf("reopen store: %v", err)
+	}
+	defer closeDB()
+	thought, _, err := st.GetThought(1)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if got := thought.EligibilityAt.Sub(before); got < 72*time.Hour || got > 72*time.Hour+time.Minute {
+		t.Fatalf("eligible %v after rest, want about 72h", got)
+	}
+
+	output = captureStdout(t, func() {
+		if code := RunPeony([]string{"view", "1"}); code != 0 {
+			t.Fatalf("view exit code = %d, want 0", code)
+		}
+	})
+	if !strings.Contains(output, "state_change tended → resting for 3d") {
+		t.Fatalf("view should show the chosen rest:\n%s", output)
+	}
+
+	output = captureStdout(t, func() {
+		if code := RunPeony([]string{"export"}); code != 0 {
+			t.Fatalf("export exit code = %d, want 0", code)
+		}
+	})
+	if !strings.Contains(output, `"settle_seconds": 259200`) {
+		t.Fatalf("export should carry the chosen rest:\n%s", output)
+	}
+
+	if code := RunPeony([]string{"rest", "1"}); code != exitConflict {
+		t.Fatalf("resting an already resting thought exit code = %d, want %d", code, exitConflict)
+	}
+}
+
+func TestRunPeonyRestAndArchiveKeepNotes(t *testing.T) {
+	useTempGarden(t)
+
+	captureStdout(t, func() {
+		for _, content := range []string{"first", "second"} {
+			if code := RunPeony([]string{"add", content}); code != 0 {
+				t.Fatalf("add %s exit code = %d, want 0", content, code)
+			}
+		}
+	})
+	st, closeDB, err := openStore()
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	if err := st.MarkThoughtTended(1, nil); err != nil {
+		t.Fatalf("mark tended: %v", err)
+	}
+	closeDB()
+
+	captureStdout(t, func() {
+		if code := RunPeony([]string{"rest", "1", "--note", "after the move"}); code != 0 {
+			t.Fatalf("rest exit code = %d, want 0", code)
+		}
+	})
+	output := captureStdout(t, func() {
+		if code := RunPeony([]string{"archive", "2", "--note=not this year"}); code != 0 {
+			t.Fatalf("archive exit code = %d, want 0", code)
+		}
+	})
+	if strings.TrimSpace(output) != "Archived #2." {
+		t.Fatalf("archive output = %q", output)
+	}
+
+	for _, tc := range []struct{ id, transition, note string }{
+		{"1", "tended → resting", "note: after the move"},
+		{"2", "captured → archived", "note: not this year"},
+	} {
+		output = captureStdout(t, func() {
+			if code := RunPeony([]string{"view", tc.id}); code != 0 {
+				t.Fatalf("view %s exit code = %d, want 0", tc.id, code)
+			}
+		})
+		if !strings.Contains(output, tc.transition) || !strings.Contains(output, tc.note) {
+			t.Fatalf("view %s should show %q and %q:\n%s", tc.id, tc.transition, tc.note, output)
+		}
+	}
+
+	if code := RunPeony([]string{"archive", "2"}); code != exitConflict {
+		t.Fatalf("archiving twice exit code = %d, want %d", code, exitConflict)
+	}
+	if code := RunPeony([]string{"archive", "1", "--for", "3d"}); code != 2 {
+		t.Fatalf("archive --for exit code = %d, want 2", code)
+	}
+	if code := RunPeony([]string{"rest", "1", "--note"}); code != 2 {
+		t.Fatalf("rest with a bare --note exit code = %d, want 2", code)
+	}
+	help := captureStdout(t, func() {
+		if code := RunPeony([]string{"help", "archive"}); code != 0 {
+			t.Fatalf("help archive exit code = %d, want 0", code)
+		}
+	})
+	if !strings.Contains(help, "peony archive [id|ref] [--note text]") {
+		t.Fatalf("archive help = %q", help)
+	}
+}
+
+func TestRunPeonyReviveBringsAnArchivedThoughtBack(t *testing.T) {
+	useTempGarden(t)
+
+	captureStdout(t, func() {
+		if code := RunPeony([]string{"add", "a", "winter", "plan"}); code != 0 {
+			t.Fatalf("add exit code = %d, want 0", code)
+		}
+		if code := RunPeony([]string{"archive", "1"}); code != 0 {
+			t.Fatalf("archive exit code = %d, want 0", code)
+		}
+	})
+
+	output := captureStdout(t, func() {
+		if code := RunPeony([]string{"revive", "1", "--for", "1w", "--note", "spring again"}); code != 0 {
+			t.Fatalf("revive exit code = %d, want 0", code)
+		}
+	})
+	if !strings.HasPrefix(output, "Revived #1. It rests for 1w, until ") {
+		t.Fatalf("revive output = %q", output)
+	}
+
+	output = captureStdout(t, func() {
+		if code := RunPeony([]string{"view", "1"}); code != 0 {
+			t.Fatalf("view exit code = %d, want 0", code)
+		}
+	})
+	for _, want := range []string{"state_change captured → archived", "revived archived → resting for 1w", "note: spring again"} {
+		if !strings.Contains(output, want) {
+			t.Fatalf("view should show %q:\n%s", want, output)
+		}
+	}
+
+	if code := RunPeony([]string{"revive", "1"}); code != exitConflict {
+		t.Fatalf("reviving a resting thought exit code = %d, want %d", code, exitConflict)
+	}
+	if code := RunPeony([]string{"revive"}); code != 2 {
+		t.Fatalf("missing id exit code = %d, want 2", code)
+	}
+}
+
+func TestRunPeonyReleaseMovesToTrashAndRestoreBringsItBack(t *testing.T) {
+	useTempGarden(t)
+
+	captureStdout(t, func() {
+		if code := RunPeony([]string{"add", "an", "old", "worry"}); code != 0 {
+			t.Fatalf("add exit code = %d, want 0", code)
+		}
+		if code := RunPeony([]string{"add", "still", "here"}); code != 0 {
+			t.Fatalf("add exit code = %d, want 0", code)
+		}
+	})
+
+	output := captureStdout(t, func() {
+		if code := RunPeony([]string{"release", "1", "--note", "no longer true"}); code != 0 {
+			t.Fatalf("release exit code = %d, want 0", code)
+		}
+	})
+	if !strings.HasPrefix(output, "Released #1. It stays in the trash for 30d") {
+		t.Fatalf("release output = %q", output)
+	}
+
+	output = captureStdout(t, func() {
+		if code := RunPeony([]string{"trash"}); code != 0 {
+			t.Fatalf("trash exit code = %d, want 0", code)
+		}
+	})
+	if !strings.Contains(output, "1 released, kept for 30d") || !strings.Contains(output, "#1  released ") || !strings.Contains(output, "an old worry") {
+		t.Fatalf("trash output = %q", output)
+	}
+
+	output = captureStdout(t, func() {
+		if code := RunPeony([]string{"view", "1"}); code != 0 {
+			t.Fatalf("view exit code = %d, want 0", code)
+		}
+	})
+	for _, want := range []string{"released", "In the trash until ", "captured → released", "note: no longer true"} {
+		if !strings.Contains(output, want) {
+			t.Fatalf("view should show %q:\n%s", want, output)
+		}
+	}
+
+	output = captureStdout(t, func() {
+		if code := RunPeony([]string{"restore", "1"}); code != 0 {
+			t.Fatalf("restore exit code = %d, want 0", code)
+		}
+	})
+	if output != "Restored #1. It is captured again.\n" {
+		t.Fatalf("restore output = %q", output)
+	}
+	if code := RunPeony([]string{"restore", "1"}); code != exitConflict {
+		t.Fatalf("restoring a captured thought exit code = %d, want %d", code, exitConflict)
+	}
+
+	output = captureStdout(t, func() {
+		if code := RunPeony([]string{"trash"}); code != 0 {
+			t.Fatalf("trash exit code = %d, want 0", code)
+		}
+	})
+	if output != "The trash is empty.\n" {
+		t.Fatalf("empty trash output = %q", output)
+	}
+
+	if code := RunPeony([]string{"release", "1", "--now", "--note", "x"}); code != 2 {
+		t.Fatalf("--now with --note exit code = %d, want 2", code)
+	}
+}
+
+func TestRunPeonyBackupAndRestoreAGarden(t *testing.T) {
+	useTempGarden(t)
+	dbPath := os.Getenv("PEONY_DB_PATH")
+
+	if code := RunPeony([]string{"backup"}); code != exitFailure {
+		t.Fatalf("backup without a garden exit code = %d, want %d", code, exitFailure)
+	}
+	if _, err := os.Stat(dbPath); !os.IsNotExist(err) {
+		t.Fatalf("backup without a garden should not create one: %v", err)
+	}
+
+	dir := t.TempDir()
+	output := captureStdout(t, func() {
+		if code := RunPeony([]string{"add", "a cabin in the woods"}); code != exitOK {
+			t.Fatalf("add exit code = %d", code)
+		}
+		if code := RunPeony([]string{"backup", dir}); code != exitOK {
+			t.Fatalf("backup exit code = %d", code)
+		}
+	})
+	backups, err := filepath.Glob(filepath.Join(dir, "peony-*.db"))
+	if err != nil || len(backups) != 1 || !strings.Contains(output, backups[0]) {
+		t.Fatalf("backups = %q (%v), output = %q", backups, err, output)
+	}
+	if code := RunPeony([]string{"backup", backups[0]}); code != exitFailure {
+		t.Fatalf("backup over an existing file exit code = %d, want %d", code, exitFailure)
+	}
+
+	output = captureStdout(t, func() {
+		if code := RunPeony([]string{"add", "paint the door"}); code != exitOK {
+			t.Fatalf("add exit code = %d", code)
+		}
+		if code := RunPeony([]string{"db", "restore", backups[0]}); code != exitOK {
+			t.Fatalf("db restore exit code = %d", code)
+		}
+	})
+	if !strings.Contains(output, "Restored the garden from "+backups[0]) || !strings.Contains(output, filepath.Join(filepath.Dir(dbPath), "backups", "pre-restore-")) {
+		t.Fatalf("restore output = %q", output)
+	}
+
+	output = captureStdout(t, func() {
+		if code := RunPeony([]string{"view", "--json"}); code != exitOK {
+			t.Fatalf("view exit code = %d", code)
+		}
+	})
+	if !strings.Contains(output, "a cabin in the woods") || strings.Contains(output, "paint the door") {
+		t.Fatalf("restored garden = %s", output)
+	}
+
+	if code := RunPeony([]string{"db", "restore", filepath.Join(dir, "missing.db")}); code != exitFailure {
+		t.Fatalf("restore of a missing backup exit code = %d, want %d", code, exitFailure)
+	}
+	if code := RunPeony([]string{"restore", backups[0]}); code != exitUsage {
+		t.Fatalf("restore of a backup path exit code = %d, want %d", code, exitUsage)
+	}
+
+	// Any file name works once the backup has its own command.
+	renamed := filepath.Join(dir, "garden.bak")
+	if err := os.Rename(backups[0], renamed); err != nil {
+		t.Fatalf("rename backup: %v", err)
+	}
+	st, closeDB, err := openStore()
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	if code := RunPeony([]string{"db", "restore", renamed}); code != exitFailure {
+		t.Fatalf("restore while the garden is open exit code = %d, want %d", code, exitFailure)
+	}
+	if _, _, err := st.GetThought(1); err != nil {
+		t.Fatalf("garden after a refused restore: %v", err)
+	}
+	closeDB()
+	captureStdout(t, func() {
+		if code := RunPeony([]string{"db", "restore", renamed}); code != exitOK {
+			t.Fatalf("db restore of garden.bak exit code = %d", code)
+		}
+	})
+}
+
+func TestRunPeonyViewCombinesFilters(t *testing.T) {
+	useTempGarden(t)
+	previous := interactive
+	interactive = func() bool { return false }
+	t.Cleanup(func() { interactive = previous })
+
+	captureStdout(t, func() {
+		for _, content := range []string{"rested seed", "tended seed", "fresh seed", "archived seed"} {
+			if code := RunPeony([]string{"add", content}); code != exitOK {
+				t.Fatalf("add exit code = %d, want %d", code, exitOK)
+			}
+		}
+		if code := RunPeony([]string{"archive", "4"}); code != exitOK {
+			t.Fatalf("archive exit code = %d, want %d", code, exitOK)
+		}
+	})
+	st, closeDB, err := openStore()
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	for _, id := range []int64{1, 2} {
+		if err := st.MarkThoughtTended(id, nil); err != nil {
+			t.Fatalf("mark #%d tended: %v", id, err)
+		}
+	}
+	if err := st.TransitionPostTendResolutionStrict(1, core.StateResting, nil); err != nil {
+		t.Fatalf("rest #1: %v", err)
+	}
+	closeDB()
+
+	view := func(args ...string) []int64 {
+		t.Helper()
+		output := captureStdout(t, func() {
+			if code := RunPeony(append([]string{"view", "--json"}, args...)); code != exitOK {
+				t.Fatalf("view --json %q exit code = %d, want %d", args, code, exitOK)
+			}
+		})
+		var rows []listedThought
+		if err := json.Unmarshal([]byte(output), &rows); err != nil {
+			t.Fatalf("view --json output is not JSON: %v\n%s", err, output)
+		}
+		ids := []int64{}
+		for _, row := range rows {
+			ids = append(ids, row.ID)
+		}
+		return ids
+	}
+
+	today := time.Now().UTC().Format("2006-01-02")
+	tomorrow := time.Now().UTC().AddDate(0, 0, 1).Format("2006-01-02")
+	for _, tc := range []struct {
+		args []string
+		want []int64
+	}{
+		{[]string{"--state", "resting,captured"}, []int64{1, 3}},
+		{[]string{"--state", "resting,captured", "--created-after", today, "--tended-more-than", "0", "--sort", "updated"}, []int64{1}},
+		{[]string{"--state", "captured", "--state", "archived"}, []int64{3, 4}},
+		{[]string{"archived", "--state", "tended"}, []int64{2, 4}},
+		{[]string{"--created-before", today}, []int64{}},
+		{[]string{"--created-before", tomorrow, "--sort", "id"}, []int64{1, 2, 3, 4}},
+		{[]string{"--tended-more-than", "1"}, []int64{}},
+	} {
+		if got := view(tc.args...); !reflect.DeepEqual(got, tc.want) {
+			t.Fatalf("view %q = %v, want %v", tc.args, got, tc.want)
+		}
+	}
+
+	for _, args := range [][]string{
+		{"view", "--sort", "sideways"},
+		{"view", "--state", "blooming"},
+		{"view", "--created-after", "last week"},
+		{"view", "--tended-more-than", "-1"},
+		{"view", "1", "--state", "captured"},
+	} {
+		if code := RunPeony(args); code != exitUsage {
+			t.Fatalf("%q exit code = %d, want %d", args, code, exitUsage)
+		}
+	}
+}
+
+func TestRunPeonyViewContinuesAfterACursor(t *testing.T) {
+	useTempGarden(t)
+	previous := interactive
+	interactive = func() bool { return false }
+	t.Cleanup(func() { interactive = previous })
+
+	captureStdout(t, func() {
+		for _, content := range []string{"first seed", "second seed", "third seed", "fourth seed"} {
+			if code := RunPeony([]string{"add", content}); code != exitOK {
+				t.Fatalf("add exit code = %d, want %d", code, exitOK)
+			}
+		}
+	})
+	list := func(args ...string) []listedThought {
+		t.Helper()
+		output := captureStdout(t, func() {
+			if code := RunPeony(append([]string{"view", "--json"}, args...)); code != exitOK {
+				t.Fatalf("view --json %q exit code = %d, want %d", args, code, exitOK)
+			}
+		})
+		var rows []listedThought
+		if err := json.Unmarshal([]byte(output), &rows); err != nil {
+			t.Fatalf("view --json output is not JSON: %v\n%s", err, output)
+		}
+		return rows
+	}
+
+	first := list("--limit", "2")
+	if len(first) != 2 || first[1].Cursor == "" {
+		t.Fatalf("first page = %+v", first)
+	}
+
+	// The first thought moves to the end of the list between pages; the next page must not
+	// skip the third, as an offset of 2 would.
+	captureStdout(t, func() {
+		if code := RunPeony([]string{"feel", "1", "--valence", "1"}); code != exitOK {
+			t.Fatalf("feel exit code = %d", code)
+		}
+	})
+
+	rest := list("--after", first[1].Cursor)
+	var ids []int64
+	for _, row := range rest {
+		ids = append(ids, row.ID)
+	}
+	if !reflect.DeepEqual(ids, []int64{3, 4, 1}) {
+		t.Fatalf("page after #2 = %v, want [3 4 1]", ids)
+	}
+
+	for _, args := range [][]string{
+		{"view", "--after", first[1].Cursor},
+		{"view", "--json", "--after", first[1].Cursor, "--offset", "1"},
+		{"view", "--json", "--after", "not-a-cursor"},
+		{"evolve", "--json", "--after", first[1].Cursor},
+	} {
+		if code := RunPeony(args); code != exitUsage {
+			t.Fatalf("%q exit code = %d, want %d", args, code, exitUsage)
+		}
+	}
+}
+
+func TestRunPeonyListsForScriptsAndExitsByErrorClass(t *testing.T) {
+	useTempGarden(t)
+	previous := interactive
+	interactive = func() bool { return false }
+	t.Cleanup(func() { interactive = previous })
+
+	captureStdout(t, func() {
+		for _, content := range []string{"first seed", "second seed", "third seed"} {
+			if code := RunPeony([]string{"add", content}); code != 0 {
+				t.Fatalf("add exit code = %d, want 0", code)
+			}
+		}
+	})
+
+	output := captureStdout(t, func() {
+		if code := RunPeony([]string{"view", "--json", "--limit", "1", "--offset=1"}); code != 0 {
+			t.Fatalf("view --json exit code = %d, want 0", code)
+		}
+	})
+	var rows []listedThought
+	if err := json.Unmarshal([]byte(output), &rows); err != nil {
+		t.Fatalf("view --json output is not JSON: %v\n%s", err, output)
+	}
+	if len(rows) != 1 || rows[0].ID != 2 || rows[0].Content != "second seed" || rows[0].State != core.StateCaptured {
+		t.Fatalf("view --json rows = %+v, want only #2", rows)
+	}
+
+	output = captureStdout(t, func() {
+		if code := RunPeony([]string{"view"}); code != 0 {
+			t.Fatalf("view exit code = %d, want 0", code)
+		}
+	})
+	if strings.Contains(output, "[n]ext") || strings.Count(output, " seed") != 3 {
+		t.Fatalf("view without a terminal should print every row once:\n%s", output)
+	}
+
+	output = captureStdout(t, func() {
+		if code := RunPeony([]string{"search", "seed", "--json", "-n", "1"}); code != 0 {
+			t.Fatalf("search --json exit code = %d, want 0", code)
+		}
+	})
+	var hits []searchedThought
+	if err := json.Unmarshal([]byte(output), &hits); err != nil || len(hits) != 1 || !strings.Contains(hits[0].Snippet, "[seed]") {
+		t.Fatalf("search --json = %+v (%v)\n%s", hits, err, output)
+	}
+
+	cases := []struct {
+		args []string
+		want int
+	}{
+		{[]string{"view", "42"}, exitNotFound},
+		{[]string{"view", "--limit", "none"}, exitUsage},
+		{[]string{"view", "1", "--limit", "1"}, exitUsage},
+		{[]string{"revive", "1"}, exitConflict},
+		{[]string{"tend", "1"}, exitNeedsTerminal},
+		{[]string{"release", "1", "--now"}, exitNeedsTerminal},
+		{[]string{"release", "1", "--yes"}, exitUsage},
+	}
+	for _, tc := range cases {
+		if code := RunPeony(tc.args); code != tc.want {
+			t.Fatalf("%v exit code = %d, want %d", tc.args, code, tc.want)
+		}
+	}
+
+	output = captureStdout(t, func() {
+		if code := RunPeony([]string{"release", "1", "--now", "--yes"}); code != 0 {
+			t.Fatalf("release --now --yes exit code = %d, want 0", code)
+		}
+	})
+	if !strings.Contains(output, "Released #1 permanently.") {
+		t.Fatalf("release --now --yes output = %q", output)
+	}
+}
+
+func TestRunPeonyViewPrintsOneThoughtAsJSONOrTemplate(t *testing.T) {
+	useTempGarden(t)
+
+	captureStdout(t, func() {
+		if code := RunPeony([]string{"add", "a", "log", "cabin", "--valence", "1"}); code != 0 {
+			t.Fatalf("add exit code = %d, want 0", code)
+		}
+		if code := RunPeony([]string{"tag", "1", "+home"}); code != 0 {
+			t.Fatalf("tag exit code = %d, want 0", code)
+		}
+		if code := RunPeony([]string{"archive", "1", "--note", "not this year"}); code != 0 {
+			t.Fatalf("archive exit code = %d, want 0", code)
+		}
+	})
+
+	output := captureStdout(t, func() {
+		if code := RunPeony([]string{"view", "1", "--json"}); code != 0 {
+			t.Fatalf("view --json exit code = %d, want 0", code)
+		}
+	})
+	var thought exchange.Thought
+	if err := json.Unmarshal([]byte(output), &thought); err != nil {
+		t.Fatalf("view --json output is not JSON: %v\n%s", err, output)
+	}
+	if thought.ID != 1 || thought.Content != "a log cabin" || thought.State != core.StateArchived || thought.PublicID == "" {
+		t.Fatalf("view --json thought = %+v", thought)
+	}
+	if !reflect.DeepEqual(thought.Tags, []string{"home"}) || thought.Valence == nil || *thought.Valence != 1 {
+		t.Fatalf("view --json tags and feeling = %v %v", thought.Tags, thought.Valence)
+	}
+	if len(thought.Events) < 2 || thought.Events[0].Kind != "captured" {
+		t.Fatalf("view --json events = %+v, want the full history", thought.Events)
+	}
+	last := thought.Events[len(thought.Events)-1]
+	if last.NextState == nil || *last.NextState != core.StateArchived || last.Note == nil || *last.Note != "not this year" {
+		t.Fatalf("view --json last event = %+v", last)
+	}
+
+	output = captureStdout(t, func() {
+		if code := RunPeony([]string{"view", thought.PublicID, "--format", "{{.ID}} {{.State}} {{join .Tags \",\"}} {{len .Events}}"}); code != 0 {
+			t.Fatalf("view --format exit code = %d, want 0", code)
+		}
+	})
+	if want := fmt.Sprintf("1 archived home %d\n", len(thought.Events)); output != want {
+		t.Fatalf("view --format output = %q, want %q", output, want)
+	}
+
+	output = captureStdout(t, func() {
+		if code := RunPeony([]string{"view", "1", "--format={{range .Events}}{{.Kind}};{{end}}"}); code != 0 {
+			t.Fatalf("view --format= exit code = %d, want 0", code)
+		}
+	})
+	if !strings.HasPrefix(output, "captured;") || !strings.HasSuffix(output, ";\n") {
+		t.Fatalf("view --format range output = %q", output)
+	}
+
+	cases := []struct {
+		args []string
+		want int
+	}{
+		{[]string{"view", "1", "--format", "{{.Nope"}, exitUsage},
+		{[]string{"view", "1", "--format", "{{.Nope}}"}, exitUsage},
+		{[]string{"view", "1", "--json", "--format", "{{.ID}}"}, exitUsage},
+		{[]string{"view", "--format", "{{.ID}}"}, exitUsage},
+		{[]string{"view", "9", "--json"}, exitNotFound},
+	}
+	for _, tc := range cases {
+		if code := RunPeony(tc.args); code != tc.want {
+			t.Fatalf("%v exit code = %d, want %d", tc.args, code, tc.want)
+		}
+	}
+}
+
+func TestRunPeonyCompletesCommandsFiltersAndThoughtIDs(t *testing.T) {
+	useTempGarden(t)
+
+	complete := func(words ...string) []string {
+		t.Helper()
+		output := captureStdout(t, func() {
+			if code := RunPeony(append([]string{"__complete"}, words...)); code != 0 {
+				t.Fatalf("__complete %v exit code = %d, want 0", words, code)
+			}
+		})
+		return strings.Split(strings.TrimSuffix(output, "\n"), "\n")
+	}
+
+	if got := complete(""); !strings.Contains(strings.Join(got, "\n"), "view\t") || !strings.Contains(strings.Join(got, "\n"), "v\t") {
+		t.Fatalf("command completions = %q", got)
+	}
+	if got := complete("re"); !reflect.DeepEqual(got, []string{"release\tMove a thought to the trash, or delete it with --now", "rest\tLet a tended thought rest, optionally for a chosen time", "revive\tBring an archived or evolved thought back to rest", "restore\tTake a released thought out of the trash"}) {
+		t.Fatalf("re completions = %q", got)
+	}
+	if _, err := os.Stat(os.Getenv("PEONY_DB_PATH")); !os.IsNotExist(err) {
+		t.Fatalf("completing ids without a garden should not create one: %v", err)
+	}
+
+	captureStdout(t, func() {
+		if code := RunPeony([]string{"add", "a cabin\nin the woods, far from the road and the town"}); code != 0 {
+			t.Fatalf("add exit code = %d, want 0", code)
+		}
+		if code := RunPeony([]string{"add", "paint the door"}); code != 0 {
+			t.Fatalf("add exit code = %d, want 0", code)
+		}
+		if code := RunPeony([]string{"tag", "2", "+home"}); code != 0 {
+			t.Fatalf("tag exit code = %d, want 0", code)
+		}
+		if code := RunPeony([]string{"archive", "2"}); code != 0 {
+			t.Fatalf("archive exit code = %d, want 0", code)
+		}
+	})
+
+	if got := complete("v", "re"); !reflect.DeepEqual(got, []string{"resting", "released"}) {
+		t.Fatalf("view filter completions = %q", got)
+	}
+	st, closeDB, err := openStore()
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	first, _, err := st.GetThought(1)
+	if err != nil {
+		t.Fatalf("get #1: %v", err)
+	}
+	second, _, err := st.GetThought(2)
+	if err != nil {
+		t.Fatalf("get #2: %v", err)
+	}
+	closeDB()
+
+	got := complete("t", "")
+	if len(got) != 2 || got[0] != first.PublicID+"\t#1 a cabin in the woods, far from the road…" || got[1] != second.PublicID+"\t#2 paint the door" {
+		t.Fatalf("tend ref completions = %q", got)
+	}
+	if got := complete("revive", ""); !reflect.DeepEqual(got, []string{second.PublicID + "\t#2 paint the door"}) {
+		t.Fatalf("revive ref completions = %q", got)
+	}
+	if got := complete("view", "--tag", ""); !reflect.DeepEqual(got, []string{"home"}) {
+		t.Fatalf("tag completions = %q", got)
+	}
+	if got := complete("config", "reindexOnRelease", ""); !reflect.DeepEqual(got, []string{"true", "false"}) {
+		t.Fatalf("config value completions = %q", got)
+	}
+	if got := complete("config", "journalMode", ""); !reflect.DeepEqual(got, storage.JournalModes) {
+		t.Fatalf("journal mode completions = %q", got)
+	}
+
+	for _, shell := range []string{"bash", "zsh", "fish"} {
+		output := captureStdout(t, func() {
+			if code := RunPeony([]string{"completion", shell}); code != 0 {
+				t.Fatalf("completion %s exit code = %d, want 0", shell, code)
+			}
+		})
+		if !strings.Contains(output, "peony __complete") {
+			t.Fatalf("%s completion script does not call peony __complete:\n%s", shell, output)
+		}
+	}
+	if code := RunPeony([]string{"completion", "powershell"}); code != exitUsage {
+		t.Fatalf("unknown shell exit code = %d, want %d", code, exitUsage)
+	}
+}
+
+func TestRunPeonyDispatchesEveryRegisteredCommand(t *testing.T) {
+	var names []string
+	for _, spec := range command.Specs {
+		names = append(names, spec.Name)
+	}
+	var bound []string
+	for name := range cliCommands {
+		bound = append(bound, name)
+	}
+	sort.Strings(names)
+	sort.Strings(bound)
+	if !reflect.DeepEqual(bound, names) {
+		t.Fatalf("bound commands = %q, registered = %q", bound, names)
+	}
+
+	useTempGarden(t)
+	output := captureStdout(t, func() {
+		if code := RunPeony([]string{"help", "r"}); code != exitOK {
+			t.Fatalf("help r exit code = %d, want %d", code, exitOK)
+		}
+	})
+	if !strings.Contains(output, "peony release — move a thought to the trash") || !strings.Contains(output, "--note text") {
+		t.Fatalf("help r output = %q", output)
+	}
+
+	captureStdout(t, func() {
+		if code := RunPeony([]string{"relase", "1"}); code != exitUsage {
+			t.Fatalf("misspelled command exit code = %d, want %d", code, exitUsage)
+		}
+	})
+
+	if code := RunPeony([]string{"release", "1", "--note"}); code != exitUsage {
+		t.Fatalf("release --note without a value exit code = %d, want %d", code, exitUsage)
+	}
+}
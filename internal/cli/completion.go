@@ -0,0 +1,323 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/divijg19/peony/internal/command"
+	"github.com/divijg19/peony/internal/core"
+	"github.com/divijg19/peony/internal/storage"
+)
+
+// completionConfigKeys are the settings peony config accepts.
+var completionConfigKeys = []string{"editor", "settleDuration", "reindexOnRelease", "releaseGracePeriod", "journalMode", "busyTimeout"}
+
+// completionPreviewWidth is how much of a thought's content follows its id in a completion.
+const completionPreviewWidth = 40
+
+// cmdCompletion prints the completion script for a shell.
+func cmdCompletion(in command.Args) int {
+	args := in.Positional
+	if len(args) != 1 {
+		fmt.Fprintln(os.Stderr, "completion: usage: `peony completion bash|zsh|fish`")
+		return exitUsage
+	}
+
+	switch args[0] {
+	case "bash":
+		fmt.Print(bashCompletion)
+	case "zsh":
+		fmt.Print(zshCompletion)
+	case "fish":
+		fmt.Print(fishCompletion)
+	default:
+		fmt.Fprintf(os.Stderr, "completion: unknown shell %q (use bash, zsh, or fish)\n", args[0])
+		return exitUsage
+	}
+	return exitOK
+}
+
+// cmdComplete backs the completion scripts: args are the words after "peony", the last one being
+// the word under the cursor. It prints one "value<TAB>description" candidate per line and never fails,
+// so a broken or missing garden only means fewer candidates.
+func cmdComplete(in command.Args) int {
+	args := in.Positional
+	current := ""
+	if len(args) > 0 {
+		current = args[len(args)-1]
+		args = args[:len(args)-1]
+	}
+
+	for _, candidate := range completionCandidates(args, current) {
+		value, _, _ := strings.Cut(candidate, "\t")
+		if strings.HasPrefix(value, current) {
+			fmt.Println(candidate)
+		}
+	}
+	return exitOK
+}
+
+// completionCandidates lists everything that may follow words, before filtering by the current prefix.
+// Commands, aliases, and flags come from the command registry.
+func completionCandidates(words []string, current string) []string {
+	if len(words) == 0 {
+		return commandCandidates()
+	}
+
+	name := command.Canonical(words[0])
+	spec, _ := command.Lookup(name)
+	previous := words[len(words)-1]
+	position := len(words) // 1 for the first argument after the command
+
+	switch name {
+	case "help":
+		if position == 1 {
+			return commandCandidates()
+		}
+	case "completion":
+		if position == 1 {
+			return []string{"bash", "zsh", "fish"}
+		}
+	case "db":
+		if position == 1 {
+			return []string{"status\tShow database schema status", "restore\tSwap a backup in for the garden"}
+		}
+	case "config":
+		switch {
+		case position == 1:
+			return completionConfigKeys
+		case position == 2 && strings.TrimPrefix(previous, "--") == "reindexOnRelease":
+			return []string{"true", "false"}
+		case position == 2 && strings.TrimPrefix(previous, "--") == "journalMode":
+			return storage.JournalModes
+		}
+	case "export":
+		switch previous {
+		case "--format", "-f":
+			return []string{"json", "ndjson", "markdown"}
+		case "--state":
+			return stateCandidates("")
+		}
+		return flagCandidates(spec)
+	case "view":
+		if previous == "--tag" || previous == "tag" {
+			return tagCandidates()
+		}
+		if strings.HasPrefix(current, "-") {
+			return append(stateCandidates("--"), flagCandidates(spec)...)
+		}
+		if position == 1 {
+			return append(stateCandidates(""), thoughtCandidates(name)...)
+		}
+	case "tend", "evolve", "archive", "trash", "search":
+		if strings.HasPrefix(current, "-") {
+			return flagCandidates(spec)
+		}
+		if position == 1 && name != "trash" && name != "search" {
+			return thoughtCandidates(name)
+		}
+	case "release", "rest", "revive", "restore", "feel":
+		if position == 1 {
+			return thoughtCandidates(name)
+		}
+		return flagCandidates(spec)
+	case "tag":
+		if position == 1 {
+			return thoughtCandidates(name)
+		}
+		var candidates []string
+		for _, tag := range tagCandidates() {
+			candidates = append(candidates, "+"+tag, "-"+tag)
+		}
+		return candidates
+	}
+	return nil
+}
+
+// commandCandidates lists every visible command and alias with its summary.
+func commandCandidates() []string {
+	var candidates []string
+	for _, spec := range command.Specs {
+		if spec.Hidden {
+			continue
+		}
+		candidates = append(candidates, spec.Name+"\t"+spec.Summary)
+		for _, alias := range spec.Aliases {
+			candidates = append(candidates, alias+"\t"+spec.Summary)
+		}
+	}
+	return candidates
+}
+
+// flagCandidates lists a command's long flags with their help.
+func flagCandidates(spec command.Spec) []string {
+	candidates := make([]string, 0, len(spec.Flags))
+	for _, flag := range spec.Flags {
+		candidates = append(candidates, flag.Name+"\t"+flag.Help)
+	}
+	return candidates
+}
+
+func stateCandidates(prefix string) []string {
+	candidates := make([]string, 0, len(core.States))
+	for _, state := range core.States {
+		candidates = append(candidates, prefix+string(state))
+	}
+	return candidates
+}
+
+// thoughtCandidates lists the refs of the thoughts the named command can act on, each described by its
+// current numeric id and a short preview of its content. Refs are offered rather than ids because ids
+// are renumbered after a permanent release. The garden is opened read-only, so completing never
+// creates or migrates a database.
+func thoughtCandidates(name string) []string {
+	st, closeDB, err := openReadOnlyStore()
+	if err != nil {
+		return nil
+	}
+	defer closeDB()
+
+	const max = 200
+	var thoughts []core.Thought
+	switch name {
+	case "restore":
+		thoughts, err = st.ListReleasedThoughts()
+	case "revive":
+		for _, state := range []core.State{core.StateArchived, core.StateEvolved} {
+			var page []core.Thought
+			page, err = st.FilterViewByPagination(max, 0, string(state))
+			if err != nil {
+				break
+			}
+			thoughts = append(thoughts, page...)
+		}
+	default:
+		thoughts, err = st.ListThoughtsByPagination(max, 0)
+	}
+	if err != nil {
+		return nil
+	}
+
+	candidates := make([]string, 0, len(thoughts))
+	for _, th := range thoughts {
+		ref := th.PublicID
+		if ref == "" {
+			ref = strconv.FormatInt(th.ID, 10)
+		}
+		candidates = append(candidates, fmt.Sprintf("%s\t#%d %s", ref, th.ID, completionPreview(th.Content)))
+	}
+	return candidates
+}
+
+func tagCandidates() []string {
+	st, closeDB, err := openReadOnlyStore()
+	if err != nil {
+		return nil
+	}
+	defer closeDB()
+
+	tags, err := st.ListTags()
+	if err != nil {
+		return nil
+	}
+	candidates := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		candidates = append(candidates, tag.Name)
+	}
+	return candidates
+}
+
+// completionPreview flattens content onto one line and cuts it to completionPreviewWidth runes.
+func completionPreview(content string) string {
+	preview := []rune(strings.Join(strings.Fields(content), " "))
+	if len(preview) <= completionPreviewWidth {
+		return string(preview)
+	}
+	return string(preview[:completionPreviewWidth-1]) + "…"
+}
+
+// openReadOnlyStore opens the configured database without creating, migrating, or writing to it.
+func openReadOnlyStore() (*storage.Store, func(), error) {
+	dbPath, err := storage.ResolveDBPath()
+	if err != nil {
+		return nil, nil, fmt.Errorf("resolve db path: %w", err)
+	}
+
+	sqlDB, err := storage.OpenReadOnly(dbPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("open db: %w", err)
+	}
+
+	st, err := storage.New(sqlDB)
+	if err != nil {
+		_ = sqlDB.Close()
+		return nil, nil, fmt.Errorf("new store: %w", err)
+	}
+	return st, func() { _ = sqlDB.Close() }, nil
+}
+
+// bashCompletion shows previews only while more than one candidate is left, so the one that is
+// finally inserted is the bare value.
+const bashCompletion = `# bash completion for peony
+# Load it with: source <(peony completion bash)
+
+_peony() {
+    local cur="${COMP_WORDS[COMP_CWORD]}"
+    local IFS=$'\n'
+    local -a lines
+    lines=($(peony __complete "${COMP_WORDS[@]:1:COMP_CWORD-1}" "$cur" 2>/dev/null))
+
+    COMPREPLY=()
+    local line
+    if [[ ${#lines[@]} -eq 1 ]]; then
+        COMPREPLY=("${lines[0]%%$'\t'*}")
+        return
+    fi
+    for line in "${lines[@]}"; do
+        if [[ $line == *$'\t'?* ]]; then
+            COMPREPLY+=("${line%%$'\t'*}  (${line#*$'\t'})")
+        else
+            COMPREPLY+=("${line%%$'\t'*}")
+        fi
+    done
+}
+
+complete -o default -F _peony peony
+`
+
+const zshCompletion = `#compdef peony
+# zsh completion for peony
+# Load it with: source <(peony completion zsh), after compinit
+
+_peony() {
+    local -a candidates
+    local line
+    for line in "${(@f)$(peony __complete "${(@)words[2,CURRENT-1]}" "${words[CURRENT]}" 2>/dev/null)}"; do
+        [[ -z $line ]] && continue
+        candidates+=("${${line%%$'\t'*}//:/\\:}:${line#*$'\t'}")
+    done
+    _describe -t peony 'peony' candidates || _files
+}
+
+if [[ "${funcstack[1]}" == "_peony" ]]; then
+    _peony "$@"
+else
+    compdef _peony peony
+fi
+`
+
+const fishCompletion = `# fish completion for peony
+# Load it with: peony completion fish > ~/.config/fish/completions/peony.fish
+
+function __peony_complete
+    set -l words (commandline -opc)
+    set -e words[1]
+    set -l current (commandline -ct)
+    peony __complete $words "$current" 2>/dev/null
+end
+
+complete -c peony -f -n 'not __fish_seen_subcommand_from import backup' -a '(__peony_complete)'
+complete -c peony -F -n '__fish_seen_subcommand_from import backup'
+`
@@ -7,10 +7,11 @@ import (
 	"strconv"
 	"strings"
 	"sync"
-	"time"
 
+	"github.com/divijg19/peony/internal/command"
 	"github.com/divijg19/peony/internal/config"
 	"github.com/divijg19/peony/internal/core"
+	"github.com/divijg19/peony/internal/storage"
 )
 
 var (
@@ -24,6 +25,9 @@ func loadRuntimeConfig() (config.Config, error) {
 	runtimeConfigOnce.Do(func() {
 		runtimeConfig, runtimeConfigErr = config.Load()
 		core.SettleDuration = config.SettleDuration(runtimeConfig)
+		core.ReindexOnRelease = config.ReindexOnRelease(runtimeConfig)
+		core.ReleaseGracePeriod = config.ReleaseGracePeriod(runtimeConfig)
+		storage.Connection = config.Connection(runtimeConfig)
 	})
 	return runtimeConfig, runtimeConfigErr
 }
@@ -42,7 +46,12 @@ func printConfig(cfg config.Config) int {
 		fmt.Printf("Editor: %s\n", cfg.Editor)
 	}
 	fmt.Printf("SettleDuration: %s\n", config.SettleDuration(cfg))
-	return 0
+	fmt.Printf("ReindexOnRelease: %t\n", config.ReindexOnRelease(cfg))
+	fmt.Printf("ReleaseGracePeriod: %s\n", core.FormatSettleDuration(config.ReleaseGracePeriod(cfg)))
+	connection := config.Connection(cfg)
+	fmt.Printf("JournalMode: %s\n", connection.JournalMode)
+	fmt.Printf("BusyTimeout: %s\n", connection.BusyTimeout)
+	return exitOK
 }
 
 // configureEditor scans for editors and saves the selected one.
@@ -50,7 +59,7 @@ func configureEditor(cfg config.Config) (config.Config, int) {
 	editors := availableEditors()
 	if len(editors) == 0 {
 		fmt.Fprintln(os.Stderr, "config: no editors found on PATH")
-		return cfg, 1
+		return cfg, exitFailure
 	}
 
 	fmt.Println("Available editors:")
@@ -58,54 +67,175 @@ func configureEditor(cfg config.Config) (config.Config, int) {
 		fmt.Printf("[%d] %s\n", idx, editor)
 	}
 
+	if !interactive() {
+		fmt.Fprintf(os.Stderr, "config: %v\n", errNeedsTerminal)
+		return cfg, exitNeedsTerminal
+	}
 	fmt.Print("Select editor by index: ")
 	reader := bufio.NewReader(os.Stdin)
 	line, err := reader.ReadString('\n')
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "config: read: %v\n", err)
-		return cfg, 1
+		return cfg, exitCode(err)
 	}
 	line = strings.TrimSpace(line)
 	if line == "" {
 		fmt.Fprintln(os.Stderr, "config: no selection provided")
-		return cfg, 1
+		return cfg, exitFailure
 	}
 	idx, err := strconv.Atoi(line)
 	if err != nil || idx < 0 || idx >= len(editors) {
 		fmt.Fprintln(os.Stderr, "config: invalid editor index")
-		return cfg, 2
+		return cfg, exitUsage
 	}
 
 	cfg.Editor = editors[idx]
-	return cfg, 0
+	return cfg, exitOK
 }
 
 // configureSettleDuration prompts for and sets the settle duration.
 func configureSettleDuration(cfg config.Config, durationValue string) (config.Config, int) {
 	if strings.TrimSpace(durationValue) == "" {
-		fmt.Print("Settle duration (e.g. 18h, 2h30m): ")
+		if !interactive() {
+			fmt.Fprintf(os.Stderr, "config: %v\n", errNeedsTerminal)
+			return cfg, exitNeedsTerminal
+		}
+		fmt.Print("Settle duration (e.g. 18h, 3d, 1w): ")
 		reader := bufio.NewReader(os.Stdin)
 		line, err := reader.ReadString('\n')
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "config: read: %v\n", err)
-			return cfg, 1
+			return cfg, exitCode(err)
 		}
 		durationValue = strings.TrimSpace(line)
 	}
 
-	dur, err := time.ParseDuration(strings.TrimSpace(durationValue))
+	dur, err := core.ParseSettleDuration(durationValue)
 	if err != nil {
 		fmt.Fprintln(os.Stderr, "config: invalid settle duration")
-		return cfg, 2
+		return cfg, exitUsage
 	}
 
-	cfg.SettleDuration = dur.String()
+	cfg.SettleDuration = core.FormatSettleDuration(dur)
 	core.SettleDuration = dur
-	return cfg, 0
+	return cfg, exitOK
+}
+
+// configureReindexOnRelease prompts for and sets whether numeric IDs are renumbered after release.
+func configureReindexOnRelease(cfg config.Config, value string) (config.Config, int) {
+	if strings.TrimSpace(value) == "" {
+		if !interactive() {
+			fmt.Fprintf(os.Stderr, "config: %v\n", errNeedsTerminal)
+			return cfg, exitNeedsTerminal
+		}
+		fmt.Print("Renumber IDs after a permanent release? (true/false): ")
+		reader := bufio.NewReader(os.Stdin)
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "config: read: %v\n", err)
+			return cfg, exitCode(err)
+		}
+		value = strings.TrimSpace(line)
+	}
+
+	reindex, err := strconv.ParseBool(strings.TrimSpace(value))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "config: reindexOnRelease must be true or false")
+		return cfg, exitUsage
+	}
+
+	cfg.ReindexOnRelease = &reindex
+	core.ReindexOnRelease = reindex
+	return cfg, exitOK
+}
+
+// configureReleaseGracePeriod prompts for and sets how long released thoughts stay in the trash.
+func configureReleaseGracePeriod(cfg config.Config, value string) (config.Config, int) {
+	if strings.TrimSpace(value) == "" {
+		if !interactive() {
+			fmt.Fprintf(os.Stderr, "config: %v\n", errNeedsTerminal)
+			return cfg, exitNeedsTerminal
+		}
+		fmt.Print("Keep released thoughts in the trash for (e.g. 30d, 2w): ")
+		reader := bufio.NewReader(os.Stdin)
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "config: read: %v\n", err)
+			return cfg, exitCode(err)
+		}
+		value = strings.TrimSpace(line)
+	}
+
+	grace, err := core.ParseSettleDuration(value)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "config: invalid release grace period")
+		return cfg, exitUsage
+	}
+
+	cfg.ReleaseGracePeriod = core.FormatSettleDuration(grace)
+	core.ReleaseGracePeriod = grace
+	return cfg, exitOK
+}
+
+// configureJournalMode prompts for and sets the SQLite journal mode used from the next open on.
+func configureJournalMode(cfg config.Config, value string) (config.Config, int) {
+	if strings.TrimSpace(value) == "" {
+		if !interactive() {
+			fmt.Fprintf(os.Stderr, "config: %v\n", errNeedsTerminal)
+			return cfg, exitNeedsTerminal
+		}
+		fmt.Printf("Journal mode (%s): ", strings.Join(storage.JournalModes, ", "))
+		reader := bufio.NewReader(os.Stdin)
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "config: read: %v\n", err)
+			return cfg, exitCode(err)
+		}
+		value = strings.TrimSpace(line)
+	}
+
+	mode, err := config.ParseJournalMode(value)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "config: %v\n", err)
+		return cfg, exitUsage
+	}
+
+	cfg.JournalMode = mode
+	storage.Connection.JournalMode = mode
+	return cfg, exitOK
+}
+
+// configureBusyTimeout prompts for and sets how long Peony waits on a database another process holds.
+func configureBusyTimeout(cfg config.Config, value string) (config.Config, int) {
+	if strings.TrimSpace(value) == "" {
+		if !interactive() {
+			fmt.Fprintf(os.Stderr, "config: %v\n", errNeedsTerminal)
+			return cfg, exitNeedsTerminal
+		}
+		fmt.Print("Wait on a busy database for (e.g. 5s, 500ms): ")
+		reader := bufio.NewReader(os.Stdin)
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "config: read: %v\n", err)
+			return cfg, exitCode(err)
+		}
+		value = strings.TrimSpace(line)
+	}
+
+	timeout, err := config.ParseBusyTimeout(value)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "config: %v\n", err)
+		return cfg, exitUsage
+	}
+
+	cfg.BusyTimeout = timeout.String()
+	storage.Connection.BusyTimeout = timeout
+	return cfg, exitOK
 }
 
 // cmdConfigure handles `peony config`.
-func cmdConfigure(args []string) int {
+func cmdConfigure(in command.Args) int {
+	args := in.Positional
 	cfg, cfgErr := loadRuntimeConfig()
 	if cfgErr != nil {
 		fmt.Fprintf(os.Stderr, "config: %v\n", cfgErr)
@@ -119,6 +249,14 @@ func cmdConfigure(args []string) int {
 		setEditor       bool
 		setSettle       bool
 		settleValue     string
+		setReindex      bool
+		reindexValue    string
+		setGrace        bool
+		graceValue      string
+		setJournal      bool
+		journalValue    string
+		setBusy         bool
+		busyValue       string
 		unrecognizedArg string
 	)
 
@@ -133,6 +271,30 @@ func cmdConfigure(args []string) int {
 				settleValue = args[i+1]
 				i++
 			}
+		case "--reindexOnRelease", "reindexOnRelease":
+			setReindex = true
+			if i+1 < len(args) && !strings.HasPrefix(args[i+1], "--") {
+				reindexValue = args[i+1]
+				i++
+			}
+		case "--releaseGracePeriod", "releaseGracePeriod":
+			setGrace = true
+			if i+1 < len(args) && !strings.HasPrefix(args[i+1], "--") {
+				graceValue = args[i+1]
+				i++
+			}
+		case "--journalMode", "journalMode":
+			setJournal = true
+			if i+1 < len(args) && !strings.HasPrefix(args[i+1], "--") {
+				journalValue = args[i+1]
+				i++
+			}
+		case "--busyTimeout", "busyTimeout":
+			setBusy = true
+			if i+1 < len(args) && !strings.HasPrefix(args[i+1], "--") {
+				busyValue = args[i+1]
+				i++
+			}
 		default:
 			unrecognizedArg = arg
 		}
@@ -140,13 +302,13 @@ func cmdConfigure(args []string) int {
 
 	if unrecognizedArg != "" {
 		fmt.Fprintf(os.Stderr, "config: unknown argument %s\n", unrecognizedArg)
-		return 2
+		return exitUsage
 	}
 
 	if setEditor {
 		var code int
 		cfg, code = configureEditor(cfg)
-		if code != 0 {
+		if code != exitOK {
 			return code
 		}
 	}
@@ -154,14 +316,46 @@ func cmdConfigure(args []string) int {
 	if setSettle {
 		var code int
 		cfg, code = configureSettleDuration(cfg, settleValue)
-		if code != 0 {
+		if code != exitOK {
+			return code
+		}
+	}
+
+	if setReindex {
+		var code int
+		cfg, code = configureReindexOnRelease(cfg, reindexValue)
+		if code != exitOK {
+			return code
+		}
+	}
+
+	if setGrace {
+		var code int
+		cfg, code = configureReleaseGracePeriod(cfg, graceValue)
+		if code != exitOK {
+			return code
+		}
+	}
+
+	if setJournal {
+		var code int
+		cfg, code = configureJournalMode(cfg, journalValue)
+		if code != exitOK {
+			return code
+		}
+	}
+
+	if setBusy {
+		var code int
+		cfg, code = configureBusyTimeout(cfg, busyValue)
+		if code != exitOK {
 			return code
 		}
 	}
 
 	if err := config.Save(cfg); err != nil {
 		fmt.Fprintf(os.Stderr, "config: %v\n", err)
-		return 1
+		return exitCode(err)
 	}
 
 	return printConfig(cfg)
@@ -0,0 +1,77 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/divijg19/peony/internal/command"
+	"github.com/divijg19/peony/internal/storage"
+)
+
+// cmdDB runs database maintenance subcommands.
+func cmdDB(in command.Args) int {
+	args := in.Positional
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "db: usage: `peony db status` or `peony db restore <backup>`")
+		return exitUsage
+	}
+
+	switch args[0] {
+	case "status":
+		if len(args) != 1 {
+			fmt.Fprintln(os.Stderr, "db status: this command does not accept arguments")
+			return exitUsage
+		}
+		return dbStatus()
+	case "restore":
+		if len(args) != 2 {
+			fmt.Fprintln(os.Stderr, "db restore: usage: `peony db restore <backup>`")
+			return exitUsage
+		}
+		return dbRestore(args[1])
+	default:
+		fmt.Fprintf(os.Stderr, "db: unknown subcommand %s\n", args[0])
+		return exitUsage
+	}
+}
+
+// dbStatus reports applied and pending schema versions without migrating the database.
+func dbStatus() int {
+	dbPath, err := storage.ResolveDBPath()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "db status: resolve db path: %v\n", err)
+		return exitCode(err)
+	}
+
+	status, err := storage.ReadSchemaStatus(dbPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "db status: %v\n", err)
+		return exitCode(err)
+	}
+
+	fmt.Printf("Database: %s\n", dbPath)
+	if !status.Exists {
+		fmt.Println("Not created yet. It will be set up the first time Peony opens it.")
+	}
+	fmt.Printf("Schema version: %d (this peony supports %d)\n", status.Current, status.Latest)
+	fmt.Println()
+	fmt.Printf("%-8s %-9s %s\n", "VERSION", "STATUS", "MIGRATION")
+	for _, step := range status.Steps {
+		state := "pending"
+		if step.Applied {
+			state = "applied"
+		}
+		fmt.Printf("%-8d %-9s %s\n", step.Version, state, step.Name)
+	}
+
+	if status.TooNew() {
+		fmt.Println()
+		fmt.Fprintf(os.Stderr, "db status: database is at version %d, newer than this peony supports (%d). Upgrade peony before using it.\n", status.Current, status.Latest)
+		return exitFailure
+	}
+	if pending := status.Pending(); pending > 0 {
+		fmt.Println()
+		fmt.Printf("%d pending. They apply automatically the next time Peony opens the database.\n", pending)
+	}
+	return exitOK
+}
@@ -0,0 +1,54 @@
+package cli
+
+import (
+	"errors"
+	"os"
+
+	"github.com/divijg19/peony/internal/core"
+	"github.com/divijg19/peony/internal/storage"
+)
+
+// Exit codes are part of the CLI's contract: scripts can branch on them, so a class never changes number.
+const (
+	exitOK            = 0 // done
+	exitFailure       = 1 // anything unexpected: storage, I/O, a broken editor or config
+	exitUsage         = 2 // bad arguments, flags, or query syntax
+	exitNotFound      = 3 // no thought has that id or ref
+	exitConflict      = 4 // the thought's state does not allow the change
+	exitNeedsTerminal = 5 // the command has to ask something and there is no terminal to ask on
+)
+
+// errNeedsTerminal is returned when a prompt would be shown without a terminal to answer it.
+var errNeedsTerminal = errors.New("needs a terminal to ask; pass the answer as an argument instead")
+
+// exitCode maps an error to the exit code of its class.
+func exitCode(err error) int {
+	var transitionErr *core.TransitionError
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.Is(err, storage.ErrNotFound):
+		return exitNotFound
+	case errors.As(err, &transitionErr):
+		return exitConflict
+	case errors.Is(err, storage.ErrInvalidSearch), errors.Is(err, storage.ErrInvalidCursor):
+		return exitUsage
+	case errors.Is(err, errNeedsTerminal):
+		return exitNeedsTerminal
+	}
+	return exitFailure
+}
+
+// interactive reports whether Peony may page and prompt: both stdin and stdout must be terminals.
+// Tests replace it.
+var interactive = func() bool {
+	return isTerminal(os.Stdin) && isTerminal(os.Stdout)
+}
+
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,101 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/divijg19/peony/internal/app"
+	"github.com/divijg19/peony/internal/command"
+	"github.com/divijg19/peony/internal/core"
+	"github.com/divijg19/peony/internal/exchange"
+)
+
+// cmdExport writes thoughts and their event history to stdout in a versioned format,
+// or to one Markdown file per thought with --format markdown --dir <dir>.
+func cmdExport(in command.Args) int {
+	if len(in.Positional) > 0 {
+		fmt.Fprintf(os.Stderr, "export: unknown argument %s\n", in.Positional[0])
+		return exitUsage
+	}
+
+	format := "json"
+	if value, ok := in.Value("--format"); ok {
+		format = strings.ToLower(strings.TrimSpace(value))
+	}
+	dir, _ := in.Value("--dir")
+
+	var filter app.ExportFilter
+	for _, value := range in.Values("--state") {
+		states, err := core.ParseStates(value)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "export: %v\n", err)
+			return exitUsage
+		}
+		filter.States = append(filter.States, states...)
+	}
+	if value, ok := in.Value("--since"); ok {
+		since, err := core.ParseTime(value)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "export: %v\n", err)
+			return exitUsage
+		}
+		filter.Since = since
+	}
+
+	switch format {
+	case "json", "ndjson":
+		if dir != "" {
+			fmt.Fprintf(os.Stderr, "export: --dir is only used with --format markdown\n")
+			return exitUsage
+		}
+	case "markdown", "md":
+		format = "markdown"
+		if strings.TrimSpace(dir) == "" {
+			fmt.Fprintln(os.Stderr, "export: --format markdown needs --dir <dir>")
+			return exitUsage
+		}
+	default:
+		fmt.Fprintf(os.Stderr, "export: unknown format %q (use json, ndjson, or markdown)\n", format)
+		return exitUsage
+	}
+
+	service, closeFn, err := app.OpenDefault()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "export: %v\n", err)
+		return exitCode(err)
+	}
+	defer closeFn()
+
+	doc, err := service.Export(filter)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "export: %v\n", err)
+		return exitCode(err)
+	}
+
+	if format == "markdown" {
+		paths, err := exchange.WriteMarkdown(dir, doc)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "export: %v\n", err)
+			return exitCode(err)
+		}
+		fmt.Printf("Wrote %d thoughts to %s\n", len(paths), dir)
+		return exitOK
+	}
+
+	out := bufio.NewWriter(os.Stdout)
+	if format == "ndjson" {
+		err = exchange.WriteNDJSON(out, doc)
+	} else {
+		err = exchange.WriteJSON(out, doc)
+	}
+	if err == nil {
+		err = out.Flush()
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "export: %v\n", err)
+		return exitCode(err)
+	}
+	return exitOK
+}
@@ -0,0 +1,104 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/divijg19/peony/internal/command"
+	"github.com/divijg19/peony/internal/core"
+)
+
+// cmdFeel shows or changes a thought's valence and energy. Unspecified fields keep their value.
+func cmdFeel(in command.Args) int {
+	args := in.Positional
+	if len(args) == 0 || !core.LooksLikeThoughtRef(args[0]) {
+		fmt.Fprintln(os.Stderr, "feel: usage: `peony feel <id|ref> [--valence -2..2|none] [--energy 1..5|none]`")
+		return exitUsage
+	}
+
+	if len(args) > 1 {
+		fmt.Fprintf(os.Stderr, "feel: unknown argument %s\n", args[1])
+		return exitUsage
+	}
+	valenceArg, energyArg, err := feelingArgsFrom(in)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "feel: %v\n", err)
+		return exitUsage
+	}
+
+	st, closeDB, err := openStore()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "feel: %v\n", err)
+		return exitCode(err)
+	}
+	defer closeDB()
+
+	id, err := st.ResolveThoughtID(args[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "feel: %v\n", err)
+		return exitCode(err)
+	}
+	thought, _, err := st.GetThought(id)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "feel: %v\n", err)
+		return exitCode(err)
+	}
+
+	if !valenceArg.set && !energyArg.set {
+		fmt.Printf("#%d %s\n", id, core.FormatFeeling(thought.Valence, thought.Energy))
+		return exitOK
+	}
+
+	valence, energy := thought.Valence, thought.Energy
+	if valenceArg.set {
+		valence = valenceArg.value
+	}
+	if energyArg.set {
+		energy = energyArg.value
+	}
+	if err := core.ValidateFeeling(valence, energy); err != nil {
+		fmt.Fprintf(os.Stderr, "feel: %v\n", err)
+		return exitUsage
+	}
+
+	changed, err := st.SetThoughtFeeling(id, valence, energy)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "feel: %v\n", err)
+		return exitCode(err)
+	}
+	if !changed {
+		fmt.Printf("#%d already feels %s\n", id, core.FormatFeeling(valence, energy))
+		return exitOK
+	}
+	fmt.Printf("#%d now feels %s\n", id, core.FormatFeeling(valence, energy))
+	return exitOK
+}
+
+// feelingArg is one --valence or --energy flag. value is nil when the flag was "none".
+type feelingArg struct {
+	set   bool
+	value *int
+}
+
+// feelingArgsFrom reads --valence and --energy from parsed arguments.
+func feelingArgsFrom(in command.Args) (feelingArg, feelingArg, error) {
+	var args [2]feelingArg
+	for i, name := range []string{"--valence", "--energy"} {
+		value, ok := in.Value(name)
+		if !ok {
+			continue
+		}
+		parsed, err := core.ParseFeelingValue(value)
+		if err != nil {
+			return feelingArg{}, feelingArg{}, err
+		}
+		args[i] = feelingArg{set: true, value: parsed}
+	}
+	return args[0], args[1], nil
+}
+
+// feelingFrom is feelingArgsFrom for commands that only ever set a feeling.
+func feelingFrom(in command.Args) (*int, *int, error) {
+	valence, energy, err := feelingArgsFrom(in)
+	return valence.value, energy.value, err
+}
@@ -0,0 +1,50 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+
+	"github.com/divijg19/peony/internal/core"
+	"github.com/divijg19/peony/internal/exchange"
+)
+
+// thoughtTemplateFuncs are the helpers --format templates may call besides the text/template builtins.
+var thoughtTemplateFuncs = template.FuncMap{
+	"join": strings.Join,
+	"json": func(v any) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+}
+
+// parseThoughtTemplate checks a --format template before anything is read from the garden.
+func parseThoughtTemplate(text string) (*template.Template, error) {
+	tmpl, err := template.New("format").Funcs(thoughtTemplateFuncs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("format: %w", err)
+	}
+	return tmpl, nil
+}
+
+// printThoughtDocument writes one thought with its full history as JSON, or through tmpl when given.
+// Both see the same fields as one thought in peony export.
+func printThoughtDocument(thought core.Thought, events []core.Event, tmpl *template.Template) error {
+	doc := exchange.FromCore(thought, events)
+	if tmpl == nil {
+		return printJSON(doc)
+	}
+
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, doc); err != nil {
+		return fmt.Errorf("format: %w", err)
+	}
+	if out.Len() > 0 && !bytes.HasSuffix(out.Bytes(), []byte("\n")) {
+		out.WriteByte('\n')
+	}
+	_, err := os.Stdout.Write(out.Bytes())
+	return err
+}
@@ -0,0 +1,121 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/divijg19/peony/internal/app"
+	"github.com/divijg19/peony/internal/command"
+	"github.com/divijg19/peony/internal/exchange"
+	"github.com/divijg19/peony/internal/storage"
+)
+
+// cmdImport reads a JSON or NDJSON export, or a directory of Markdown thoughts,
+// and recreates its thoughts with fresh local IDs.
+func cmdImport(in command.Args) int {
+	opts := storage.ImportOptions{
+		SkipDuplicates: in.Has("--skip-duplicates"),
+		DryRun:         in.Has("--dry-run"),
+	}
+	format := ""
+	if value, ok := in.Value("--format"); ok {
+		format = strings.ToLower(strings.TrimSpace(value))
+	}
+
+	path := ""
+	for _, arg := range in.Positional {
+		if path != "" || (len(arg) > 1 && arg[0] == '-') {
+			fmt.Fprintf(os.Stderr, "import: unknown argument %s\n", arg)
+			return exitUsage
+		}
+		path = arg
+	}
+	if path == "" {
+		fmt.Fprintln(os.Stderr, "import: usage: `peony import <file|dir> [--format json|ndjson|markdown] [--skip-duplicates] [--dry-run]`")
+		return exitUsage
+	}
+	if format == "" {
+		format = "json"
+		if info, err := os.Stat(path); err == nil && info.IsDir() {
+			format = "markdown"
+		}
+	}
+
+	var doc exchange.Document
+	var err error
+	switch format {
+	case "json", "ndjson":
+		doc, err = readExport(path)
+	case "markdown", "md":
+		if path == "-" {
+			fmt.Fprintln(os.Stderr, "import: markdown import reads a directory, not stdin")
+			return exitUsage
+		}
+		doc, err = exchange.ReadMarkdown(path)
+	default:
+		fmt.Fprintf(os.Stderr, "import: unknown format %q (use json, ndjson, or markdown)\n", format)
+		return exitUsage
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "import: %v\n", err)
+		return exitCode(err)
+	}
+
+	service, closeFn, err := app.OpenDefault()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "import: %v\n", err)
+		return exitCode(err)
+	}
+	defer closeFn()
+
+	result, err := service.Import(doc, opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "import: %v\n", err)
+		return exitCode(err)
+	}
+
+	printImportResult(result)
+	return exitOK
+}
+
+// readExport reads a JSON or NDJSON export from path, or from stdin when path is "-".
+func readExport(path string) (exchange.Document, error) {
+	var input io.Reader = os.Stdin
+	if path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			return exchange.Document{}, err
+		}
+		defer file.Close()
+		input = file
+	}
+	return exchange.Read(input)
+}
+
+// printImportResult reports what an import changed, or would change for a dry run.
+func printImportResult(result storage.ImportResult) {
+	verb := "Imported"
+	if result.DryRun {
+		verb = "Would import"
+	}
+	fmt.Printf("%s %d thoughts with %d events.\n", verb, len(result.Imported), result.Events)
+	for _, item := range result.Imported {
+		fmt.Printf("  #%d -> #%d (ref %s)\n", item.SourceID, item.ID, item.PublicID)
+	}
+	if len(result.Skipped) > 0 {
+		skipVerb := "Skipped"
+		if result.DryRun {
+			skipVerb = "Would skip"
+		}
+		fmt.Printf("%s %d duplicates:", skipVerb, len(result.Skipped))
+		for _, id := range result.Skipped {
+			fmt.Printf(" #%d", id)
+		}
+		fmt.Println()
+	}
+	if result.DryRun {
+		fmt.Println("Dry run: nothing was changed.")
+	}
+}
@@ -98,3 +98,29 @@ func TestInstallScriptAliasBlockIsIdempotent(t *testing.T) {
 		t.Fatalf("alias block count = %d, want 1\n%s", count, data)
 	}
 }
+
+func TestInstallScriptCompletionBlockIsIdempotentAndMatchesShell(t *testing.T) {
+	if _, err := exec.LookPath("bash"); err != nil {
+		t.Skip("bash not available")
+	}
+
+	script := filepath.Join("..", "..", "install.sh")
+	for _, shell := range []string{"bash", "zsh"} {
+		rcFile := filepath.Join(t.TempDir(), "shellrc")
+		cmd := exec.Command("bash", "-c", `source "$1"; append_completion_block "$2" "$3"; append_completion_block "$2" "$3"`, "bash", script, rcFile, shell)
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("append %s completion block failed: %v\n%s", shell, err, output)
+		}
+
+		data, err := os.ReadFile(rcFile)
+		if err != nil {
+			t.Fatalf("read rc file: %v", err)
+		}
+		if count := strings.Count(string(data), "# >>> peony completion >>>"); count != 1 {
+			t.Fatalf("%s completion block count = %d, want 1\n%s", shell, count, data)
+		}
+		if !strings.Contains(string(data), "peony completion "+shell) {
+			t.Fatalf("%s completion block does not load %s completions:\n%s", shell, shell, data)
+		}
+	}
+}
@@ -0,0 +1,233 @@
+package cli
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/divijg19/peony/internal/command"
+	"github.com/divijg19/peony/internal/core"
+	"github.com/divijg19/peony/internal/storage"
+)
+
+// defaultPageSize is how many rows the interactive pager shows at a time.
+const defaultPageSize = 10
+
+// listOptions are the flags every list command accepts.
+type listOptions struct {
+	json    bool   // print a JSON array instead of a table
+	limit   int    // rows per page when paging, rows in total otherwise; 0 means the default
+	offset  int    // rows to skip first
+	after   string // cursor of the row to continue after, in place of offset
+	noPager bool   // print once and exit, even on a terminal
+	set     bool   // any of the above was given
+}
+
+// paged reports whether the list should run the interactive [n]ext/[p]rev pager.
+func (o listOptions) paged() bool {
+	return !o.json && !o.noPager && interactive()
+}
+
+// listOptionsFrom reads the list flags a command was given.
+func listOptionsFrom(args command.Args) (listOptions, error) {
+	opts := listOptions{
+		json:    args.Has("--json"),
+		noPager: args.Has("--no-pager"),
+	}
+	if value, ok := args.Value("--limit"); ok {
+		n, err := strconv.Atoi(value)
+		if err != nil || n <= 0 {
+			return opts, fmt.Errorf("invalid limit %q", value)
+		}
+		opts.limit = n
+	}
+	if value, ok := args.Value("--offset"); ok {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return opts, fmt.Errorf("invalid offset %q", value)
+		}
+		opts.offset = n
+	}
+	if value, ok := args.Value("--after"); ok {
+		switch {
+		case value == "":
+			return opts, fmt.Errorf("--after needs a cursor")
+		case !opts.json:
+			return opts, fmt.Errorf("--after continues --json output; add --json")
+		case args.Has("--offset"):
+			return opts, fmt.Errorf("use --after or --offset, not both")
+		}
+		opts.after = value
+	}
+	opts.set = opts.json || opts.noPager || args.Has("--limit") || args.Has("--offset") || args.Has("--after")
+	return opts, nil
+}
+
+// window applies --offset and --limit to a list that was loaded whole.
+func window[T any](items []T, opts listOptions) []T {
+	if opts.offset >= len(items) {
+		return items[:0]
+	}
+	items = items[opts.offset:]
+	if opts.limit > 0 && opts.limit < len(items) {
+		items = items[:opts.limit]
+	}
+	return items
+}
+
+// printJSON writes v as indented JSON, the same shape peony export uses.
+func printJSON(v any) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+// listedThought is one row of a --json thought list. Field names match peony export.
+type listedThought struct {
+	ID          int64      `json:"id"`
+	PublicID    string     `json:"public_id,omitempty"`
+	Content     string     `json:"content"`
+	State       core.State `json:"state"`
+	TendCounter int        `json:"tend_counter"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	Cursor      string     `json:"cursor,omitempty"` // pass to --after to continue after this row
+}
+
+func listedThoughts(thoughts []core.Thought) []listedThought {
+	rows := make([]listedThought, 0, len(thoughts))
+	for _, th := range thoughts {
+		rows = append(rows, listedThought{
+			ID:          th.ID,
+			PublicID:    th.PublicID,
+			Content:     th.Content,
+			State:       th.CurrentState,
+			TendCounter: th.TendCounter,
+			UpdatedAt:   th.UpdatedAt.UTC(),
+		})
+	}
+	return rows
+}
+
+// thoughtList describes one list command: which thoughts it lists and how it reads when empty.
+type thoughtList struct {
+	cmd      string               // command name for error messages
+	store    *storage.Store       // where the rows come from
+	query    storage.ThoughtQuery // which rows, in which order
+	title    string               // appended to "Page N" in the pager
+	empty    string               // printed when there is nothing to list
+	overview int                  // content width in the table
+}
+
+// listThoughts prints a list command's rows: through the pager on a terminal, otherwise once,
+// as a table or as JSON. JSON rows carry the cursor --after continues from.
+func listThoughts(list thoughtList, opts listOptions) int {
+	if opts.paged() {
+		return pageThoughts(list, opts)
+	}
+
+	query := list.query
+	query.Offset = opts.offset
+	thoughts, _, err := list.store.PageThoughts(query, opts.limit, opts.after)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", list.cmd, err)
+		return exitCode(err)
+	}
+	if opts.json {
+		rows := listedThoughts(thoughts)
+		for i := range rows {
+			rows[i].Cursor = storage.EncodeCursor(query.Order, thoughts[i])
+		}
+		if err := printJSON(rows); err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", list.cmd, err)
+			return exitFailure
+		}
+		return exitOK
+	}
+	if len(thoughts) == 0 {
+		fmt.Println(list.empty)
+		return exitOK
+	}
+	printThoughtTable(thoughts, list.overview)
+	return exitOK
+}
+
+// pageThoughts is the interactive [n]ext/[p]rev/[q]uit pager. Each page continues after the last
+// row of the one before it, so thoughts that change state while paging are neither skipped nor
+// shown twice.
+func pageThoughts(list thoughtList, opts listOptions) int {
+	reader := bufio.NewReader(os.Stdin)
+	pageSize := defaultPageSize
+	if opts.limit > 0 {
+		pageSize = opts.limit
+	}
+	query := list.query
+	query.Offset = opts.offset
+	starts := []string{""} // the cursor each page continues after; the first starts at --offset
+
+	for {
+		page := len(starts) - 1
+		thoughts, next, err := list.store.PageThoughts(query, pageSize, starts[page])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", list.cmd, err)
+			return exitCode(err)
+		}
+
+		if len(thoughts) == 0 {
+			if page == 0 {
+				fmt.Println(list.empty)
+				return exitOK
+			}
+			starts = starts[:page]
+			continue
+		}
+
+		fmt.Printf("Page %d%s\n", page+1, list.title)
+		printThoughtTable(thoughts, list.overview)
+
+		fmt.Print("[n]ext, [p]rev, [q]uit: ")
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: read: %v\n", list.cmd, err)
+			return exitFailure
+		}
+
+		switch strings.ToLower(strings.TrimSpace(line)) {
+		case "q":
+			return exitOK
+		case "p":
+			if page > 0 {
+				starts = starts[:page]
+			}
+		default:
+			if next != "" {
+				starts = append(starts, next)
+			}
+		}
+	}
+}
+
+func printThoughtTable(thoughts []core.Thought, overviewMax int) {
+	overview := func(s string) string {
+		s = strings.ReplaceAll(s, "\n", " ")
+		s = strings.TrimSpace(s)
+		if len(s) <= overviewMax {
+			return s
+		}
+		return s[:overviewMax-1] + "…"
+	}
+
+	fmt.Printf("%-6s %-10s %-5s %-20s %s\n", "ID", "STATE", "TEND", "UPDATED", "OVERVIEW")
+	for _, th := range thoughts {
+		fmt.Printf("%-6d %-10s %-5d %-20s %s\n",
+			th.ID,
+			th.CurrentState,
+			th.TendCounter,
+			th.UpdatedAt.UTC().Format("2006-01-02 15:04"),
+			overview(th.Content),
+		)
+	}
+}
@@ -0,0 +1,177 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/divijg19/peony/internal/app"
+	"github.com/divijg19/peony/internal/command"
+	"github.com/divijg19/peony/internal/core"
+)
+
+// cmdRest returns a tended thought to resting, for the configured settle duration or as long as --for says.
+func cmdRest(in command.Args) int {
+	args := in.Positional
+	if len(args) == 0 || !core.LooksLikeThoughtRef(args[0]) {
+		fmt.Fprintln(os.Stderr, "rest: usage: `peony rest <id|ref> [--for 3d] [--note text]`")
+		return exitUsage
+	}
+
+	flags, err := resolutionFlagsFrom(in)
+	if err == nil && len(args) > 1 {
+		err = fmt.Errorf("unknown argument %s", args[1])
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "rest: %v\n", err)
+		return exitUsage
+	}
+
+	service, closeFn, err := app.OpenDefault()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "rest: %v\n", err)
+		return exitCode(err)
+	}
+	defer closeFn()
+
+	id, err := service.ResolveID(args[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "rest: %v\n", err)
+		return exitCode(err)
+	}
+	if err := service.RestFor(id, flags.note, flags.settle); err != nil {
+		fmt.Fprintf(os.Stderr, "rest: %v\n", err)
+		return exitCode(err)
+	}
+
+	item, err := service.Thought(id)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "rest: %v\n", err)
+		return exitCode(err)
+	}
+	fmt.Printf("#%d rests for %s, until %s.\n", id, core.FormatSettleDuration(core.SettleFor(flags.settle)), item.Thought.EligibilityAt.UTC().Format("2006-01-02 15:04Z"))
+	return exitOK
+}
+
+// cmdArchive lists archived thoughts (taking the list flags) or archives one, keeping an optional note with it.
+func cmdArchive(in command.Args) int {
+	args := in.Positional
+	opts, err := listOptionsFrom(in)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "archive: %v\n", err)
+		return exitUsage
+	}
+	if len(args) == 0 {
+		if in.Has("--note") {
+			fmt.Fprintln(os.Stderr, "archive: --note needs the id of the thought to archive")
+			return exitUsage
+		}
+		return cmdView(in.Prepend("--archived"))
+	}
+	if opts.set {
+		fmt.Fprintln(os.Stderr, "archive: --json, --limit, --offset and --no-pager list thoughts; leave out the id")
+		return exitUsage
+	}
+	if !core.LooksLikeThoughtRef(args[0]) {
+		fmt.Fprintln(os.Stderr, "archive: usage: `peony archive <id|ref> [--note text]`")
+		return exitUsage
+	}
+
+	flags, err := resolutionFlagsFrom(in)
+	if err == nil && len(args) > 1 {
+		err = fmt.Errorf("unknown argument %s", args[1])
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "archive: %v\n", err)
+		return exitUsage
+	}
+
+	service, closeFn, err := app.OpenDefault()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "archive: %v\n", err)
+		return exitCode(err)
+	}
+	defer closeFn()
+
+	id, err := service.ResolveID(args[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "archive: %v\n", err)
+		return exitCode(err)
+	}
+	if err := service.Archive(id, flags.note); err != nil {
+		fmt.Fprintf(os.Stderr, "archive: %v\n", err)
+		return exitCode(err)
+	}
+
+	fmt.Printf("Archived #%d.\n", id)
+	return exitOK
+}
+
+// cmdRevive brings an archived or evolved thought back to resting with a fresh settle period.
+func cmdRevive(in command.Args) int {
+	args := in.Positional
+	if len(args) == 0 || !core.LooksLikeThoughtRef(args[0]) {
+		fmt.Fprintln(os.Stderr, "revive: usage: `peony revive <id|ref> [--for 3d] [--note text]`")
+		return exitUsage
+	}
+
+	flags, err := resolutionFlagsFrom(in)
+	if err == nil && len(args) > 1 {
+		err = fmt.Errorf("unknown argument %s", args[1])
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "revive: %v\n", err)
+		return exitUsage
+	}
+
+	service, closeFn, err := app.OpenDefault()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "revive: %v\n", err)
+		return exitCode(err)
+	}
+	defer closeFn()
+
+	id, err := service.ResolveID(args[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "revive: %v\n", err)
+		return exitCode(err)
+	}
+	if err := service.ReviveFor(id, flags.note, flags.settle); err != nil {
+		fmt.Fprintf(os.Stderr, "revive: %v\n", err)
+		return exitCode(err)
+	}
+
+	item, err := service.Thought(id)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "revive: %v\n", err)
+		return exitCode(err)
+	}
+	fmt.Printf("Revived #%d. It rests for %s, until %s.\n", id, core.FormatSettleDuration(core.SettleFor(flags.settle)), item.Thought.EligibilityAt.UTC().Format("2006-01-02 15:04Z"))
+	return exitOK
+}
+
+// resolutionFlags are the options shared by rest, archive, and revive.
+type resolutionFlags struct {
+	note   *string
+	settle time.Duration
+}
+
+// resolutionFlagsFrom reads --note and --for; the command's spec decides which of them it takes.
+func resolutionFlagsFrom(in command.Args) (resolutionFlags, error) {
+	var flags resolutionFlags
+	if value, ok := in.Value("--note"); ok {
+		if strings.TrimSpace(value) == "" {
+			return flags, fmt.Errorf("--note is empty")
+		}
+		flags.note = &value
+	}
+	if value, ok := in.Value("--for"); ok {
+		d, err := core.ParseSettleDuration(value)
+		if err != nil {
+			return flags, err
+		}
+		flags.settle = d
+	}
+	return flags, nil
+}
@@ -0,0 +1,91 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/divijg19/peony/internal/command"
+	"github.com/divijg19/peony/internal/core"
+)
+
+// defaultSearchLimit is how many hits search prints without --limit.
+const defaultSearchLimit = 20
+
+// cmdSearch prints thoughts whose content or notes match a full-text query, best match first.
+func cmdSearch(in command.Args) int {
+	opts, err := listOptionsFrom(in)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "search: %v\n", err)
+		return exitUsage
+	}
+	if opts.limit == 0 {
+		opts.limit = defaultSearchLimit
+	}
+
+	query := strings.TrimSpace(strings.Join(in.Positional, " "))
+	if query == "" {
+		fmt.Fprintln(os.Stderr, "search: usage: `peony search <query> [--limit n] [--offset n] [--json]`")
+		return exitUsage
+	}
+
+	st, closeDB, err := openStore()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "search: %v\n", err)
+		return exitCode(err)
+	}
+	defer closeDB()
+
+	hits, err := st.SearchThoughts(query, opts.offset+opts.limit)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		return exitCode(err)
+	}
+	hits = window(hits, opts)
+
+	if opts.json {
+		rows := make([]searchedThought, 0, len(hits))
+		for _, hit := range hits {
+			rows = append(rows, searchedThought{
+				ID:        hit.ID,
+				PublicID:  hit.PublicID,
+				Content:   hit.Content,
+				State:     hit.State,
+				UpdatedAt: hit.UpdatedAt.UTC(),
+				Snippet:   hit.Snippet,
+				FromNote:  hit.FromNote,
+			})
+		}
+		if err := printJSON(rows); err != nil {
+			fmt.Fprintf(os.Stderr, "search: %v\n", err)
+			return exitFailure
+		}
+		return exitOK
+	}
+
+	if len(hits) == 0 {
+		fmt.Println("Nothing in the garden matches that.")
+		return exitOK
+	}
+
+	for _, hit := range hits {
+		snippet := strings.Join(strings.Fields(hit.Snippet), " ")
+		if hit.FromNote {
+			snippet = "note: " + snippet
+		}
+		fmt.Printf("#%-4d %-9s %s\n", hit.ID, hit.State, snippet)
+	}
+	return exitOK
+}
+
+// searchedThought is one row of peony search --json, best match first.
+type searchedThought struct {
+	ID        int64      `json:"id"`
+	PublicID  string     `json:"public_id,omitempty"`
+	Content   string     `json:"content"`
+	State     core.State `json:"state"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	Snippet   string     `json:"snippet"`
+	FromNote  bool       `json:"from_note"`
+}
@@ -0,0 +1,221 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/divijg19/peony/internal/app"
+	"github.com/divijg19/peony/internal/command"
+	"github.com/divijg19/peony/internal/core"
+	"github.com/divijg19/peony/internal/storage"
+)
+
+// cmdRelease moves a thought into the trash. With --now it asks first and deletes the thought
+// and its history for good.
+func cmdRelease(in command.Args) int {
+	args := in.Positional
+	if len(args) == 0 || !core.LooksLikeThoughtRef(args[0]) {
+		fmt.Fprintln(os.Stderr, "release: usage: `peony release <id|ref> [--note text] [--now [--yes]]`")
+		return exitUsage
+	}
+
+	now, yes := in.Has("--now"), in.Has("--yes")
+	flags, err := resolutionFlagsFrom(in)
+	if err == nil && len(args) > 1 {
+		err = fmt.Errorf("unknown argument %s", args[1])
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "release: %v\n", err)
+		return exitUsage
+	}
+	if now && flags.note != nil {
+		fmt.Fprintln(os.Stderr, "release: --note is kept with the history, which --now deletes")
+		return exitUsage
+	}
+	if yes && !now {
+		fmt.Fprintln(os.Stderr, "release: --yes only confirms --now")
+		return exitUsage
+	}
+
+	service, closeFn, err := app.OpenDefault()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "release: %v\n", err)
+		return exitCode(err)
+	}
+	defer closeFn()
+
+	id, err := service.ResolveID(args[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "release: %v\n", err)
+		return exitCode(err)
+	}
+
+	if !now {
+		if err := service.Release(id, flags.note); err != nil {
+			fmt.Fprintf(os.Stderr, "release: %v\n", err)
+			return exitCode(err)
+		}
+		fmt.Printf("Released #%d. It stays in the trash for %s; `peony restore %d` brings it back.\n", id, core.FormatSettleDuration(core.ReleaseGracePeriod), id)
+		return exitOK
+	}
+
+	if !yes {
+		if !interactive() {
+			fmt.Fprintf(os.Stderr, "release: %v (--yes)\n", errNeedsTerminal)
+			return exitNeedsTerminal
+		}
+		reader := bufio.NewReader(os.Stdin)
+		ok, err := promptYesNo(reader, fmt.Sprintf("Release thought #%d now? This deletes it and its history for good.", id))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "release: %v\n", err)
+			return exitCode(err)
+		}
+		if !ok {
+			return exitOK
+		}
+	}
+
+	if err := service.ReleasePermanent(id); err != nil {
+		fmt.Fprintf(os.Stderr, "release: %v\n", err)
+		return exitCode(err)
+	}
+
+	fmt.Printf("Released #%d permanently.\n", id)
+	return exitOK
+}
+
+// cmdTrash lists released thoughts and when each one will be purged.
+func cmdTrash(in command.Args) int {
+	opts, err := listOptionsFrom(in)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "trash: %v\n", err)
+		return exitUsage
+	}
+	if rest := in.Positional; len(rest) > 0 {
+		fmt.Fprintf(os.Stderr, "trash: unknown argument %s\n", rest[0])
+		return exitUsage
+	}
+
+	service, closeFn, err := app.OpenDefault()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "trash: %v\n", err)
+		return exitCode(err)
+	}
+	defer closeFn()
+
+	thoughts, err := service.Trash()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "trash: %v\n", err)
+		return exitCode(err)
+	}
+	total := len(thoughts)
+	thoughts = window(thoughts, opts)
+
+	if opts.json {
+		rows := make([]trashedThought, 0, len(thoughts))
+		for _, t := range thoughts {
+			rows = append(rows, trashedThought{
+				listedThought: listedThoughts([]core.Thought{t})[0],
+				ReleasedAt:    t.ReleasedAt.UTC(),
+				PurgeAt:       core.PurgeAt(t).UTC(),
+			})
+		}
+		if err := printJSON(rows); err != nil {
+			fmt.Fprintf(os.Stderr, "trash: %v\n", err)
+			return exitFailure
+		}
+		return exitOK
+	}
+
+	if len(thoughts) == 0 {
+		fmt.Println("The trash is empty.")
+		return exitOK
+	}
+
+	overview := func(s string) string {
+		s = strings.ReplaceAll(s, "\n", " ")
+		s = strings.TrimSpace(s)
+		const max = 60
+		if len(s) <= max {
+			return s
+		}
+		return s[:max-1] + "…"
+	}
+
+	fmt.Printf("%d released, kept for %s:\n", total, core.FormatSettleDuration(core.ReleaseGracePeriod))
+	for _, t := range thoughts {
+		fmt.Printf("#%d  released %s  purged %s  %s\n",
+			t.ID,
+			t.ReleasedAt.UTC().Format("2006-01-02 15:04Z"),
+			core.PurgeAt(t).UTC().Format("2006-01-02 15:04Z"),
+			overview(t.Content),
+		)
+	}
+	return exitOK
+}
+
+// trashedThought is one row of peony trash --json.
+type trashedThought struct {
+	listedThought
+	ReleasedAt time.Time `json:"released_at"`
+	PurgeAt    time.Time `json:"purge_at"`
+}
+
+// cmdRestore takes a released thought out of the trash.
+func cmdRestore(in command.Args) int {
+	args := in.Positional
+	if len(args) != 1 || !core.LooksLikeThoughtRef(args[0]) {
+		fmt.Fprintln(os.Stderr, "restore: usage: `peony restore <id|ref>`; `peony db restore <backup>` restores a backup")
+		return exitUsage
+	}
+
+	service, closeFn, err := app.OpenDefault()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "restore: %v\n", err)
+		return exitCode(err)
+	}
+	defer closeFn()
+
+	id, err := service.ResolveID(args[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "restore: %v\n", err)
+		return exitCode(err)
+	}
+	state, err := service.Restore(id)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "restore: %v\n", err)
+		return exitCode(err)
+	}
+
+	fmt.Printf("Restored #%d. It is %s again.\n", id, state)
+	return exitOK
+}
+
+// purgeExpiredReleases empties the trash of anything past the grace period and says so on stderr.
+// A garden that does not exist yet has nothing to purge and is not created.
+func purgeExpiredReleases() {
+	if dbPath, err := storage.ResolveDBPath(); err != nil {
+		return
+	} else if _, err := os.Stat(dbPath); err != nil {
+		return
+	}
+
+	service, closeFn, err := app.OpenDefault()
+	if err != nil {
+		return
+	}
+	defer closeFn()
+
+	n, err := service.PurgeExpired()
+	if err != nil || n == 0 {
+		return
+	}
+	if n == 1 {
+		fmt.Fprintln(os.Stderr, "🍂 1 released thought passed its grace period and was purged.")
+		return
+	}
+	fmt.Fprintf(os.Stderr, "🍂 %d released thoughts passed their grace period and were purged.\n", n)
+}
@@ -0,0 +1,692 @@
+// Package command describes Peony's commands once: names and aliases, usage, flags, and help.
+// The CLI and Bloom's command bar both dispatch from it, and help, shell completion, and
+// suggestions are all read from the same table. Flags that both front ends read the same way,
+// such as view's filters, are parsed here too.
+package command
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/divijg19/peony/internal/app"
+	"github.com/divijg19/peony/internal/core"
+	"github.com/divijg19/peony/internal/storage"
+)
+
+// Flag is one option a command accepts.
+type Flag struct {
+	Name    string // long form, such as "--note"
+	Short   string // optional short form, such as "-n"
+	Value   string // placeholder for the value, such as "text"; empty for a switch
+	Help    string
+	CLIOnly bool // not accepted from Bloom's command bar
+}
+
+// Spec is one command. Usage lines are written without the leading "peony"; examples are whole
+// command lines.
+type Spec struct {
+	Name     string
+	Aliases  []string
+	Summary  string   // one line for command lists
+	Usage    []string // syntax, most common form first
+	Help     string   // the description shown by peony help <command>
+	Flags    []Flag
+	Examples []string
+	Bloom    string // usage in Bloom's command bar; empty when the command is CLI-only
+	Hidden   bool   // left out of help, completion, and suggestions
+}
+
+var listFlags = []Flag{
+	{Name: "--json", Help: "print a JSON array instead of a table", CLIOnly: true},
+	{Name: "--limit", Value: "n", Help: "at most n rows (rows per page in the pager)", CLIOnly: true},
+	{Name: "--offset", Value: "n", Help: "skip the first n rows", CLIOnly: true},
+	{Name: "--no-pager", Help: "print once and exit, even on a terminal", CLIOnly: true},
+}
+
+// viewFilterFlags narrow peony view and Bloom's :view alike.
+var viewFilterFlags = []Flag{
+	{Name: "--state", Value: "states", Help: "only thoughts in these states; repeat or comma-separate"},
+	{Name: "--created-after", Value: "date", Help: "only thoughts created at or after 2006-01-02 or an RFC 3339 time"},
+	{Name: "--created-before", Value: "date", Help: "only thoughts created before this time"},
+	{Name: "--updated-after", Value: "date", Help: "only thoughts updated at or after this time"},
+	{Name: "--updated-before", Value: "date", Help: "only thoughts updated before this time"},
+	{Name: "--tended-more-than", Value: "n", Help: "only thoughts tended more than n times"},
+	{Name: "--sort", Value: "order", Help: "id, created, updated, or eligibility"},
+}
+
+// HasViewFilter reports whether in gives any of the flags that narrow a view.
+func HasViewFilter(in Args) bool {
+	for _, flag := range viewFilterFlags {
+		if in.Has(flag.Name) {
+			return true
+		}
+	}
+	return false
+}
+
+// ViewFilter reads the filter flags shared by peony view and Bloom's :view on top of the states
+// and normalized tag given as arguments. --tended-more-than n becomes at least n+1 tends.
+func ViewFilter(in Args, states []core.State, tag string) (app.ViewFilter, error) {
+	filter := app.ViewFilter{States: states, Tag: tag}
+	for _, value := range in.Values("--state") {
+		parsed, err := core.ParseStates(value)
+		if err != nil {
+			return app.ViewFilter{}, fmt.Errorf("--state: %w", err)
+		}
+		filter.States = append(filter.States, parsed...)
+	}
+	for _, bound := range []struct {
+		flag string
+		at   *time.Time
+	}{
+		{"--created-after", &filter.CreatedAfter},
+		{"--created-before", &filter.CreatedBefore},
+		{"--updated-after", &filter.UpdatedAfter},
+		{"--updated-before", &filter.UpdatedBefore},
+	} {
+		value, ok := in.Value(bound.flag)
+		if !ok {
+			continue
+		}
+		t, err := core.ParseTime(value)
+		if err != nil {
+			return app.ViewFilter{}, fmt.Errorf("%s: %w", bound.flag, err)
+		}
+		*bound.at = t
+	}
+	if value, ok := in.Value("--tended-more-than"); ok {
+		n, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil || n < 0 {
+			return app.ViewFilter{}, fmt.Errorf("--tended-more-than: invalid count %q", value)
+		}
+		filter.MinTends = n + 1
+	}
+	if value, ok := in.Value("--sort"); ok {
+		filter.Sort = storage.ThoughtOrder(strings.ToLower(strings.TrimSpace(value)))
+	}
+	return filter, nil
+}
+
+// afterFlag continues a --json list where an earlier one stopped. It is offered by the lists the
+// store pages by cursor.
+var afterFlag = Flag{Name: "--after", Value: "cursor", Help: "with --json, continue after the row carrying this cursor", CLIOnly: true}
+
+var noteFlag = Flag{Name: "--note", Value: "text", Help: "a note to keep in the thought's history"}
+
+var forFlag = Flag{Name: "--for", Value: "duration", Help: "how long to rest: 90m, 18h, 3d, 1w, or 1d12h"}
+
+var feelingFlags = []Flag{
+	{Name: "--valence", Value: "-2..2", Help: "how heavy (-2) or light (+2) it feels"},
+	{Name: "--energy", Value: "1..5", Help: "how low (1) or high (5) its energy is"},
+}
+
+func withFlags(groups ...[]Flag) []Flag {
+	var flags []Flag
+	for _, group := range groups {
+		flags = append(flags, group...)
+	}
+	return flags
+}
+
+// Specs lists every command in the order help shows them.
+var Specs = []Spec{
+	{
+		Name:    "help",
+		Aliases: []string{"h"},
+		Summary: "Show this help or detailed help for a command",
+		Usage:   []string{"help [command]"},
+		Help:    "Shows the list of commands, or everything about one command.",
+		Examples: []string{
+			"peony help",
+			"peony help view",
+		},
+		Bloom: "help [command]",
+	},
+	{
+		Name:    "version",
+		Aliases: []string{"-v"},
+		Summary: "Show version",
+		Usage:   []string{"version [--verbose]"},
+		Help: `Prints the Peony version. With --verbose, also prints the commit and
+date it was built from, the Go version, and the database schema
+version this peony reads and writes.`,
+		Flags: []Flag{
+			{Name: "--verbose", Help: "add commit, build date, Go, and schema versions"},
+		},
+		Examples: []string{"peony version", "peony version --verbose"},
+		Bloom:    "version",
+	},
+	{
+		Name:    "add",
+		Aliases: []string{"a"},
+		Summary: "Capture a thought",
+		Usage:   []string{"add [content] [--valence -2..2] [--energy 1..5]", "a [content]"},
+		Help: `Captures a new thought and stores it in the captured state.
+The thought will rest for a configured duration before becoming eligible to tend.
+--valence (-2 heavy .. +2 light) and --energy (1 low .. 5 high) name how
+it feels as it arrives; both are optional. Without content, asks for it
+on a terminal or reads one line from stdin otherwise.`,
+		Flags: feelingFlags,
+		Examples: []string{
+			`peony add "I wonder if I should learn Rust"`,
+			`peony add "the garden in spring" --valence 2 --energy 4`,
+			"peony add",
+		},
+		Bloom: "add [content] [--valence -2..2] [--energy 1..5]",
+	},
+	{
+		Name:    "view",
+		Aliases: []string{"v"},
+		Summary: "View the list of thoughts or a thought by id",
+		Usage: []string{
+			"view [id|ref] [--json | --format template]",
+			"view [--filter | filter] [list flags]",
+			"view --tag <tag> [list flags]",
+			"view [--state s[,s]] [--created-after date] [--tended-more-than n] [--sort order] [filters]",
+			"v [id|ref]",
+		},
+		Help: `View a paginated list of thoughts, a single thought by ID, or filter by state.
+Without arguments, shows all non-archived thoughts.
+
+Filters are captured, resting, tended, evolved, released, and archived;
+--tag <tag> shows thoughts carrying that tag.
+
+The filter flags combine, and every one must match: --state takes several
+states, --created-after and --updated-after keep thoughts from that time
+on, the -before flags keep those before it, --tended-more-than n keeps
+thoughts tended more than n times, and --sort orders the list. Bloom's
+:view reads the same flags.
+
+Every thought has a numeric ID and a stable ref (for example k3f09a1c2d4).
+Numeric IDs may be renumbered after a release; refs never change.
+
+With an id, --json prints that thought and its full history as one JSON
+object, and --format runs it through a Go text/template instead. Both see
+the fields of one thought in peony export (.ID, .PublicID, .Content,
+.State, .Tags, .Events, ...); join and json are available as functions.
+
+Each row of a --json list carries a cursor. Pass the last one to --after
+to read the next page: it picks up after that row even when thoughts
+have changed state in between, which --offset cannot promise.`,
+		Flags: withFlags([]Flag{
+			{Name: "--tag", Value: "tag", Help: "only thoughts carrying this tag"},
+			{Name: "--format", Value: "template", Help: "with an id, print the thought through a Go text/template", CLIOnly: true},
+		}, viewFilterFlags, listFlags, []Flag{afterFlag}),
+		Examples: []string{
+			"peony view",
+			"peony view 12",
+			"peony view k3f09a1c2d4",
+			"peony view --archived",
+			"peony view captured",
+			"peony view --tag work",
+			"peony view --state resting,captured --created-after 2026-01-01 --tended-more-than 3 --sort updated",
+			"peony view --json --limit 20 --offset 40",
+			"peony view --json --limit 20 --after <cursor>",
+			"peony view 12 --json",
+			`peony view 12 --format '{{.State}} {{join .Tags ","}}'`,
+		},
+		Bloom: "view [id|ref|state] [--tag tag] [--state s[,s]] [--created-after date] [--tended-more-than n] [--sort order]",
+	},
+	{
+		Name:    "tend",
+		Aliases: []string{"t"},
+		Summary: "List thoughts which are ready to be tended",
+		Usage:   []string{"tend [id|ref]", "tend [list flags]", "t [id|ref]"},
+		Help: `Lists thoughts that are eligible to tend, or opens an interactive editor
+to tend a specific thought by ID. Tending asks questions, so it needs a
+terminal; without one it exits with code 5.`,
+		Flags: withFlags(listFlags, []Flag{afterFlag}),
+		Examples: []string{
+			"peony tend",
+			"peony tend 5",
+			"peony tend --json",
+		},
+		Bloom: "tend [id|ref]",
+	},
+	{
+		Name:    "release",
+		Aliases: []string{"r"},
+		Summary: "Move a thought to the trash, or delete it with --now",
+		Usage:   []string{"release <id|ref> [--note text]", "release <id|ref> --now [--yes]", "r <id|ref>"},
+		Help: `Moves a thought into the trash. It leaves every list, keeps its
+history, and can be brought back with peony restore until the
+release grace period (30d unless configured) is over; then it is
+purged for good.
+
+With --now, asks first and deletes the thought and its history
+immediately. That cannot be undone. Without a terminal to ask on,
+--now needs --yes. Numeric IDs are renumbered afterwards unless
+reindexOnRelease is set to false; refs never change.`,
+		Flags: []Flag{
+			noteFlag,
+			{Name: "--now", Help: "delete permanently instead of moving to the trash", CLIOnly: true},
+			{Name: "--yes", Short: "-y", Help: "confirm --now without asking", CLIOnly: true},
+		},
+		Examples: []string{
+			"peony release 8",
+			`peony r 3 --note "no longer true"`,
+			"peony release 8 --now",
+		},
+		Bloom: "release <id|ref> [--note text]",
+	},
+	{
+		Name:    "evolve",
+		Aliases: []string{"e"},
+		Summary: "Pass a thought into your wider workflow",
+		Usage:   []string{"evolve [id|ref]", "evolve [list flags]", "e [id|ref]"},
+		Help: `Transitions a thought into the evolved state, indicating it has been
+integrated into your wider workflow (e.g., a task manager or notes app).
+Without an ID, lists evolved thoughts.`,
+		Flags: withFlags(listFlags, []Flag{afterFlag}),
+		Examples: []string{
+			"peony evolve 7",
+			"peony e",
+		},
+		Bloom: "evolve [id|ref]",
+	},
+	{
+		Name:    "rest",
+		Summary: "Let a tended thought rest, optionally for a chosen time",
+		Usage:   []string{"rest <id|ref> [--for duration] [--note text]"},
+		Help: `Returns a tended thought to resting. It becomes ready to tend again
+after the settle duration from config, or after --for when given.
+The chosen length is kept in the thought's history.`,
+		Flags: []Flag{forFlag, noteFlag},
+		Examples: []string{
+			"peony rest 4",
+			"peony rest 4 --for 3d",
+			`peony rest k4f09c2a1b7 --for 1w --note "after the move"`,
+		},
+	},
+	{
+		Name:    "archive",
+		Summary: "Set a thought aside, kept but out of the way",
+		Usage:   []string{"archive [id|ref] [--note text]", "archive [list flags]"},
+		Help: `Archives a thought that is not done but no longer needs tending.
+Archived thoughts leave Bloom and the tend queue and are kept with
+their history. Without an ID, pages through archived thoughts like
+peony view --archived.`,
+		Flags: withFlags([]Flag{noteFlag}, listFlags, []Flag{afterFlag}),
+		Examples: []string{
+			"peony archive",
+			"peony archive 6",
+			`peony archive 6 --note "not this year"`,
+		},
+	},
+	{
+		Name:    "revive",
+		Summary: "Bring an archived or evolved thought back to rest",
+		Usage:   []string{"revive <id|ref> [--for duration] [--note text]"},
+		Help: `Moves an archived or evolved thought back to resting. It settles
+again from now, for the configured settle duration or --for, and
+then surfaces for tending like any other thought. The revive is
+added to its history; nothing earlier is lost.`,
+		Flags: []Flag{forFlag, noteFlag},
+		Examples: []string{
+			"peony revive 6",
+			`peony revive 6 --for 1w --note "spring again"`,
+		},
+		Bloom: "revive <id|ref> [--for 3d] [--note text]",
+	},
+	{
+		Name:    "trash",
+		Summary: "List released thoughts waiting to be purged",
+		Usage:   []string{"trash [list flags]"},
+		Help: `Lists released thoughts with when each was released and when it will
+be purged. Anything past the grace period is purged before listing.`,
+		Flags: listFlags,
+		Examples: []string{
+			"peony trash",
+			"peony trash --json",
+		},
+		Bloom: "trash",
+	},
+	{
+		Name:    "restore",
+		Summary: "Take a released thought out of the trash",
+		Usage:   []string{"restore <id|ref>"},
+		Help: `Returns a released thought to the state it was released from and
+adds the restore to its history. peony db restore puts a backup of
+the whole garden back instead.`,
+		Examples: []string{"peony restore 8"},
+		Bloom:    "restore <id|ref>",
+	},
+	{
+		Name:    "tag",
+		Summary: "Add or remove tags on a thought",
+		Usage:   []string{"tag [list flags]", "tag <id|ref>", "tag <id|ref> [+tag ...] [-tag ...]"},
+		Help: `Adds (+name) or removes (-name) tags on a thought. A bare name is added.
+With only an ID, shows the thought's tags; with no arguments, lists
+every tag in use. Tags are lowercase, start with a letter, and may
+contain letters, digits, '-', '_', or '/'.`,
+		Flags: listFlags,
+		Examples: []string{
+			"peony tag",
+			"peony tag --json",
+			"peony tag 12 +work -later",
+			"peony view --tag work",
+		},
+		Bloom: "tag [id|ref [+tag] [-tag]|#tag]",
+	},
+	{
+		Name:    "feel",
+		Summary: "Name how a thought feels",
+		Usage:   []string{"feel <id|ref>", "feel <id|ref> [--valence -2..2|none] [--energy 1..5|none]"},
+		Help: `Shows or sets a thought's valence and energy. Valence runs from -2
+(heavy) to +2 (light); energy runs from 1 (low) to 5 (high). Either
+may be left unnamed, and "none" clears one. Each change is kept in
+the thought's history as a feel event, so you can see how it has
+felt over time.`,
+		Flags: feelingFlags,
+		Examples: []string{
+			"peony feel 3",
+			"peony feel 3 --valence -1 --energy 2",
+			"peony feel 3 --energy none",
+		},
+	},
+	{
+		Name:    "search",
+		Summary: "Search thoughts and notes",
+		Usage:   []string{"search <query> [--limit n] [--offset n] [--json]"},
+		Help: `Searches thought content and the notes left on them, best match first,
+with matched words shown in [brackets]. Words must all appear; use
+"quotes" for a phrase, a trailing * for a prefix, and AND, OR, NOT,
+and parentheses to combine terms.`,
+		Flags: []Flag{
+			{Name: "--limit", Short: "-n", Value: "n", Help: "at most n results (default 20)"},
+			{Name: "--offset", Value: "n", Help: "skip the first n results"},
+			{Name: "--json", Help: "print a JSON array of hits"},
+			{Name: "--no-pager", Help: "accepted for symmetry; search never pages"},
+		},
+		Examples: []string{
+			"peony search cabin",
+			`peony search '"log cabin" OR treehouse'`,
+			"peony search 'garden* NOT weeds'",
+		},
+	},
+	{
+		Name:    "config",
+		Aliases: []string{"configure", "c"},
+		Summary: "View and edit defaults for peony",
+		Usage: []string{
+			"config",
+			"config [--editor | editor]",
+			"config [--settleDuration | settleDuration] <duration>",
+			"config [--reindexOnRelease | reindexOnRelease] <true|false>",
+			"config [--releaseGracePeriod | releaseGracePeriod] <duration>",
+			"config [--journalMode | journalMode] <wal|delete|truncate|persist>",
+			"config [--busyTimeout | busyTimeout] <duration>",
+			"c",
+		},
+		Help: `View or update configuration settings like editor, settle duration,
+whether numeric IDs are renumbered after a release, and how long
+released thoughts stay in the trash. A setting given without a value
+is asked for on a terminal.
+
+journalMode and busyTimeout tune how the database is shared. The
+default wal mode lets Bloom and the WebUI read while another peony
+writes, and a writer waits up to busyTimeout (5s by default) for
+another one to finish before giving up. Both apply the next time the
+garden is opened.`,
+		Examples: []string{
+			"peony config",
+			"peony config --editor",
+			"peony config settleDuration 3d",
+			"peony config reindexOnRelease false",
+			"peony config releaseGracePeriod 14d",
+			"peony config busyTimeout 10s",
+		},
+		Bloom: "config [settleDuration <duration>|reindexOnRelease <bool>|releaseGracePeriod <duration>|journalMode <mode>|busyTimeout <duration>|editor]",
+	},
+	{
+		Name:    "export",
+		Summary: "Write thoughts and history as JSON, NDJSON, or Markdown",
+		Usage: []string{
+			"export [--format json|ndjson] [--state <state[,state]>] [--since <date|timestamp>]",
+			"export --format markdown --dir <dir> [--state ...] [--since ...]",
+		},
+		Help: `Writes thoughts with their tags and full event history to stdout.
+The output carries a format name and version ("peony.export", 1) so
+other tools can rely on it; the README documents every field.
+json writes one document; ndjson writes a header line followed by
+one thought per line. markdown writes one .md file per thought into
+--dir, with the fields as YAML front matter and the event history
+under a "## History" heading. Thoughts in the trash are left out
+unless --state names released.`,
+		Flags: []Flag{
+			{Name: "--format", Short: "-f", Value: "format", Help: "json (default), ndjson, or markdown"},
+			{Name: "--dir", Short: "-d", Value: "dir", Help: "directory for markdown files; created if missing"},
+			{Name: "--state", Value: "states", Help: "only thoughts in these states; repeat or comma-separate"},
+			{Name: "--since", Value: "date", Help: "only thoughts updated at or after 2006-01-02 or an RFC 3339 time"},
+		},
+		Examples: []string{
+			"peony export > garden.json",
+			"peony export --format ndjson --state resting,captured",
+			"peony export --since 2026-01-01",
+			"peony export --format markdown --dir ~/notes/peony",
+		},
+	},
+	{
+		Name:    "import",
+		Summary: "Bring thoughts in from an export",
+		Usage: []string{
+			"import <file|-> [--skip-duplicates] [--dry-run]",
+			"import <dir> [--format markdown] [--skip-duplicates] [--dry-run]",
+		},
+		Help: `Reads a JSON or NDJSON file written by peony export and recreates every
+thought with its tags and full event history, all in one transaction.
+Thoughts get fresh local IDs; refs are kept unless already taken here.
+Use - to read from stdin. A directory is read as Markdown: every .md
+file in it becomes a thought. Files without front matter are
+captured with their modification time.`,
+		Flags: []Flag{
+			{Name: "--format", Short: "-f", Value: "format", Help: "json, ndjson, or markdown; a directory implies markdown"},
+			{Name: "--skip-duplicates", Help: "skip thoughts whose content and created time match one already here"},
+			{Name: "--dry-run", Short: "-n", Help: "report what would change without changing anything"},
+		},
+		Examples: []string{
+			"peony import garden.json --dry-run",
+			"peony import garden.ndjson --skip-duplicates",
+			"peony import --format markdown ~/notes/peony",
+		},
+	},
+	{
+		Name:    "backup",
+		Summary: "Save a snapshot of the garden",
+		Usage:   []string{"backup [path]"},
+		Help: `Writes a consistent copy of the whole database, safe to take while
+Bloom or the WebUI are open. Without a path, or with a directory, the
+copy is named for the time and goes in the backups folder beside the
+database. An existing file is never overwritten.
+
+Peony also keeps its own rotating backups there, taken before a schema
+migration and before IDs are renumbered; the newest five are kept.
+peony db restore <backup> brings any of them back.`,
+		Examples: []string{
+			"peony backup",
+			"peony backup ~/Dropbox/peony.db",
+		},
+	},
+	{
+		Name:    "db",
+		Summary: "Show database schema status, or restore a backup",
+		Usage:   []string{"db status", "db restore <backup>"},
+		Help: `status shows the database path, its schema version, and which numbered
+migrations are applied or pending. The database is only read; pending
+migrations apply automatically the next time Peony opens it. Peony
+refuses to open a database written by a newer version.
+
+restore checks that a backup is an intact Peony database this version
+can read and swaps it in for the garden, which is moved into the
+backups folder rather than deleted. The garden is not opened, migrated,
+or purged first, and the swap is refused while Bloom, the WebUI, or
+another peony command has it open.`,
+		Examples: []string{
+			"peony db status",
+			"peony db restore ~/.local/share/peony/backups/peony-20261018-090000.db",
+		},
+	},
+	{
+		Name:    "tui",
+		Summary: "Open the Peony terminal garden",
+		Usage:   []string{"tui"},
+		Help: `Opens Bloom, Peony's full-screen Bubble Tea interface for browsing,
+tending, searching, filtering, and resolving thoughts. install.sh
+--alias adds an optional bloom shell function that runs peony tui.`,
+		Examples: []string{
+			"peony tui",
+			"bloom  (if installed with install.sh --alias)",
+		},
+		Bloom: "tui",
+	},
+	{
+		Name:    "web",
+		Summary: "Open a read-only window in the browser",
+		Usage:   []string{"web [--port n]"},
+		Help: `Serves a quiet, read-only WebUI on this machine only (127.0.0.1).
+Pages show Ready, Resting, and All thoughts, and each thought's history.
+The database is opened read-only; nothing can be changed from the browser.`,
+		Flags: []Flag{
+			{Name: "--port", Short: "-p", Value: "n", Help: "port to listen on"},
+		},
+		Examples: []string{
+			"peony web",
+			"peony web --port 8080",
+		},
+	},
+	{
+		Name:    "completion",
+		Summary: "Print a shell completion script",
+		Usage:   []string{"completion bash|zsh|fish"},
+		Help: `Prints a completion script for bash, zsh, or fish. It completes
+commands and their aliases, state filters, config keys, tags, and
+thought refs, which stay valid when IDs are renumbered, described by
+each thought's ID and a short preview. Refs and tags are read from the
+garden without changing it.`,
+		Examples: []string{
+			"source <(peony completion bash)",
+			"source <(peony completion zsh)",
+			"peony completion fish > ~/.config/fish/completions/peony.fish",
+		},
+	},
+	{
+		Name:   "__complete",
+		Usage:  []string{"__complete [word ...] <current>"},
+		Help:   "Prints completion candidates for the completion scripts.",
+		Hidden: true,
+	},
+}
+
+// Lookup finds a command by name or alias.
+func Lookup(name string) (Spec, bool) {
+	for _, spec := range Specs {
+		if spec.Name == name {
+			return spec, true
+		}
+		for _, alias := range spec.Aliases {
+			if alias == name {
+				return spec, true
+			}
+		}
+	}
+	return Spec{}, false
+}
+
+// Canonical maps an alias to its command name and returns anything else unchanged.
+func Canonical(name string) string {
+	if spec, ok := Lookup(name); ok {
+		return spec.Name
+	}
+	return name
+}
+
+// Suggest returns the visible command a mistyped name most likely meant, or "".
+func Suggest(name string) string {
+	if name == "" {
+		return ""
+	}
+	for _, spec := range Specs {
+		if spec.Hidden {
+			continue
+		}
+		for _, candidate := range append([]string{spec.Name}, spec.Aliases...) {
+			if strings.HasPrefix(candidate, name) || strings.HasPrefix(name, candidate) {
+				return spec.Name
+			}
+		}
+	}
+	return ""
+}
+
+// InBloom reports whether Bloom's command bar runs the command.
+func (s Spec) InBloom() bool {
+	return s.Bloom != ""
+}
+
+// Flag finds one of the command's flags by its long or short form.
+func (s Spec) Flag(name string) (Flag, bool) {
+	for _, flag := range s.Flags {
+		if flag.Name == name || (flag.Short != "" && flag.Short == name) {
+			return flag, true
+		}
+	}
+	return Flag{}, false
+}
+
+// Page renders the full help page for peony help <command>.
+func (s Spec) Page() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "peony %s — %s\n\n", s.Name, strings.ToLower(s.Summary[:1])+s.Summary[1:])
+
+	b.WriteString("Description:\n")
+	for _, line := range strings.Split(s.Help, "\n") {
+		if line == "" {
+			b.WriteString("\n")
+			continue
+		}
+		b.WriteString("  " + line + "\n")
+	}
+
+	b.WriteString("\nSyntax:\n")
+	for _, usage := range s.Usage {
+		b.WriteString("  peony " + usage + "\n")
+	}
+
+	if len(s.Flags) > 0 {
+		b.WriteString("\nOptions:\n")
+		b.WriteString(FlagTable(s.Flags, "  "))
+	}
+
+	if len(s.Examples) > 0 {
+		b.WriteString("\nExamples:\n")
+		for _, example := range s.Examples {
+			b.WriteString("  " + example + "\n")
+		}
+	}
+	b.WriteString("\n")
+	return b.String()
+}
+
+// FlagTable lays flags out in two aligned columns, each line starting with indent.
+func FlagTable(flags []Flag, indent string) string {
+	names := make([]string, len(flags))
+	width := 0
+	for i, flag := range flags {
+		names[i] = flag.Name
+		if flag.Short != "" {
+			names[i] += ", " + flag.Short
+		}
+		if flag.Value != "" {
+			names[i] += " " + flag.Value
+		}
+		width = max(width, len(names[i]))
+	}
+
+	var b strings.Builder
+	for i, flag := range flags {
+		fmt.Fprintf(&b, "%s%-*s   %s\n", indent, width, names[i], flag.Help)
+	}
+	return b.String()
+}
@@ -0,0 +1,176 @@
+package command
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/divijg19/peony/internal/core"
+	"github.com/divijg19/peony/internal/storage"
+)
+
+func TestParseReadsDeclaredFlagsAndKeepsTheRestPositional(t *testing.T) {
+	spec, ok := Lookup("release")
+	if !ok {
+		t.Fatal("release is not registered")
+	}
+
+	in, err := spec.Parse([]string{"3", "--note", "no longer true", "-y", "--now", "--archived", "-later"})
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if !reflect.DeepEqual(in.Positional, []string{"3", "--archived", "-later"}) {
+		t.Fatalf("positional = %q", in.Positional)
+	}
+	if note, ok := in.Value("--note"); !ok || note != "no longer true" {
+		t.Fatalf("--note = %q, %v", note, ok)
+	}
+	if !in.Has("--yes") || !in.Has("--now") {
+		t.Fatalf("switches not read: yes=%v now=%v", in.Has("--yes"), in.Has("--now"))
+	}
+
+	in, err = spec.Parse([]string{"--note=a=b", "4", "--note", "later"})
+	if err != nil {
+		t.Fatalf("parse with =: %v", err)
+	}
+	if note, _ := in.Value("--note"); note != "later" {
+		t.Fatalf("last --note = %q, want later", note)
+	}
+	if got := in.Values("--note"); !reflect.DeepEqual(got, []string{"a=b", "later"}) {
+		t.Fatalf("--note values = %q", got)
+	}
+
+	feel, _ := Lookup("feel")
+	in, err = feel.Parse([]string{"2", "--valence", "-2"})
+	if err != nil {
+		t.Fatalf("parse negative value: %v", err)
+	}
+	if valence, _ := in.Value("--valence"); valence != "-2" || len(in.Positional) != 1 {
+		t.Fatalf("--valence = %q positional = %q", valence, in.Positional)
+	}
+
+	if got := in.Prepend("--archived").Positional; !reflect.DeepEqual(got, []string{"--archived", "2"}) {
+		t.Fatalf("prepend = %q", got)
+	}
+	if !reflect.DeepEqual(in.Positional, []string{"2"}) {
+		t.Fatalf("prepend changed the original: %q", in.Positional)
+	}
+
+	for _, tc := range []struct {
+		args []string
+		want string
+	}{
+		{[]string{"3", "--note"}, "--note needs a value"},
+		{[]string{"3", "--now=yes"}, "--now does not take a value"},
+	} {
+		if _, err := spec.Parse(tc.args); err == nil || err.Error() != tc.want {
+			t.Fatalf("parse %q error = %v, want %q", tc.args, err, tc.want)
+		}
+	}
+}
+
+func TestCheckBloomRejectsCLIOnlyFlags(t *testing.T) {
+	spec, _ := Lookup("release")
+	in, err := spec.Parse([]string{"3", "--note", "done"})
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if err := spec.CheckBloom(in); err != nil {
+		t.Fatalf("--note should be allowed in Bloom: %v", err)
+	}
+
+	in, _ = spec.Parse([]string{"3", "--now"})
+	if err := spec.CheckBloom(in); err == nil || !strings.Contains(err.Error(), "--now") {
+		t.Fatalf("--now in Bloom error = %v", err)
+	}
+}
+
+func TestLookupCanonicalAndSuggest(t *testing.T) {
+	for alias, want := range map[string]string{"v": "view", "configure": "config", "-v": "version", "r": "release"} {
+		if got := Canonical(alias); got != want {
+			t.Fatalf("Canonical(%q) = %q, want %q", alias, got, want)
+		}
+	}
+	if got := Canonical("later"); got != "later" {
+		t.Fatalf("Canonical(later) = %q, want it unchanged", got)
+	}
+	if _, ok := Lookup("later"); ok {
+		t.Fatal("Lookup(later) found a command")
+	}
+
+	for name, want := range map[string]string{"rel": "release", "views": "view", "later": "", "": "", "__comp": ""} {
+		if got := Suggest(name); got != want {
+			t.Fatalf("Suggest(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestSpecsAreUniqueAndDocumented(t *testing.T) {
+	seen := map[string]string{}
+	for _, spec := range Specs {
+		for _, name := range append([]string{spec.Name}, spec.Aliases...) {
+			if owner, ok := seen[name]; ok {
+				t.Fatalf("%q is used by both %s and %s", name, owner, spec.Name)
+			}
+			seen[name] = spec.Name
+		}
+
+		flags := map[string]bool{}
+		for _, flag := range spec.Flags {
+			if !strings.HasPrefix(flag.Name, "--") || flags[flag.Name] || (flag.Short != "" && flags[flag.Short]) {
+				t.Fatalf("%s has a bad or repeated flag %+v", spec.Name, flag)
+			}
+			flags[flag.Name] = true
+			if flag.Short != "" {
+				flags[flag.Short] = true
+			}
+		}
+
+		if spec.Hidden {
+			continue
+		}
+		if spec.Summary == "" || spec.Help == "" || len(spec.Usage) == 0 {
+			t.Fatalf("%s is missing its summary, help, or usage", spec.Name)
+		}
+		if page := spec.Page(); !strings.HasPrefix(page, "peony "+spec.Name+" — ") {
+			t.Fatalf("%s help page starts %q", spec.Name, strings.SplitN(page, "\n", 2)[0])
+		}
+	}
+}
+
+func TestViewFilterReadsTheSharedFilterFlags(t *testing.T) {
+	spec, _ := Lookup("view")
+	in, err := spec.Parse([]string{"--state", "resting,Captured", "--created-after", "2026-01-02", "--tended-more-than", "3", "--sort", "Updated"})
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	filter, err := ViewFilter(in, []core.State{core.StateTended}, "work")
+	if err != nil {
+		t.Fatalf("view filter: %v", err)
+	}
+	if !reflect.DeepEqual(filter.States, []core.State{core.StateTended, core.StateResting, core.StateCaptured}) {
+		t.Fatalf("states = %v", filter.States)
+	}
+	if filter.Tag != "work" || filter.MinTends != 4 || filter.Sort != storage.OrderByUpdated {
+		t.Fatalf("filter = %+v", filter)
+	}
+	if want := time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC); !filter.CreatedAfter.Equal(want) {
+		t.Fatalf("created after = %v, want %v", filter.CreatedAfter, want)
+	}
+
+	for args, want := range map[string]string{
+		"--state wilted":            "--state: invalid state",
+		"--updated-before someday":  "--updated-before: invalid time",
+		"--tended-more-than -1":     "--tended-more-than: invalid count",
+		"--tended-more-than plenty": "--tended-more-than: invalid count",
+	} {
+		in, err := spec.Parse(strings.Fields(args))
+		if err != nil {
+			t.Fatalf("parse %q: %v", args, err)
+		}
+		if _, err := ViewFilter(in, nil, ""); err == nil || !strings.Contains(err.Error(), want) {
+			t.Fatalf("ViewFilter(%q) error = %v, want %q", args, err, want)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package command
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Args is a parsed command line: the flags that were given, by long name, and every other
+// argument in order.
+type Args struct {
+	Positional []string
+	values     map[string][]string
+}
+
+// Parse reads the spec's flags out of args in "--flag v", "--flag=v", or short form. A flag's value
+// is always the next argument, even when it starts with "-". Anything that is not one of the spec's
+// flags is positional, so filters such as --archived and tags such as -later reach the command.
+func (s Spec) Parse(args []string) (Args, error) {
+	parsed := Args{values: map[string][]string{}}
+	for i := 0; i < len(args); i++ {
+		name, value, hasValue := strings.Cut(args[i], "=")
+		flag, ok := s.Flag(name)
+		if !ok || !strings.HasPrefix(name, "-") {
+			parsed.Positional = append(parsed.Positional, args[i])
+			continue
+		}
+
+		if flag.Value == "" {
+			if hasValue {
+				return Args{}, fmt.Errorf("%s does not take a value", flag.Name)
+			}
+			parsed.values[flag.Name] = append(parsed.values[flag.Name], "")
+			continue
+		}
+		if !hasValue {
+			if i+1 >= len(args) {
+				return Args{}, fmt.Errorf("%s needs a value", flag.Name)
+			}
+			value = args[i+1]
+			i++
+		}
+		parsed.values[flag.Name] = append(parsed.values[flag.Name], value)
+	}
+	return parsed, nil
+}
+
+// Has reports whether the flag was given.
+func (a Args) Has(name string) bool {
+	_, ok := a.values[name]
+	return ok
+}
+
+// Value returns the last value given for the flag.
+func (a Args) Value(name string) (string, bool) {
+	values := a.values[name]
+	if len(values) == 0 {
+		return "", false
+	}
+	return values[len(values)-1], true
+}
+
+// Values returns every value given for a repeatable flag, in order.
+func (a Args) Values(name string) []string {
+	return a.values[name]
+}
+
+// Prepend returns a copy of a with positional arguments put in front of its own.
+func (a Args) Prepend(positional ...string) Args {
+	a.Positional = append(append([]string(nil), positional...), a.Positional...)
+	return a
+}
+
+// CheckBloom rejects flags the command declares as CLI-only when it runs from Bloom's command bar.
+func (s Spec) CheckBloom(args Args) error {
+	for _, flag := range s.Flags {
+		if flag.CLIOnly && args.Has(flag.Name) {
+			return fmt.Errorf("%s is only available from the peony command line", flag.Name)
+		}
+	}
+	return nil
+}
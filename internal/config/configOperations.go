@@ -7,21 +7,39 @@ import (
 	"path/filepath"
 	"strings"
 	"time"
+
+	"github.com/divijg19/peony/internal/core"
+	"github.com/divijg19/peony/internal/storage"
 )
 
 // DefaultSettleDuration is the default rest duration before a thought becomes eligible.
 const DefaultSettleDuration = 18 * time.Hour
 
+// DefaultReleaseGracePeriod is how long released thoughts stay in the trash before they are purged.
+const DefaultReleaseGracePeriod = 30 * 24 * time.Hour
+
 // Config holds user-configurable settings for Peony.
 type Config struct {
-	Editor         string `json:"editor,omitempty"`
-	SettleDuration string `json:"settleDuration,omitempty"`
+	Editor             string `json:"editor,omitempty"`
+	SettleDuration     string `json:"settleDuration,omitempty"`
+	ReindexOnRelease   *bool  `json:"reindexOnRelease,omitempty"`
+	ReleaseGracePeriod string `json:"releaseGracePeriod,omitempty"`
+	JournalMode        string `json:"journalMode,omitempty"`
+	BusyTimeout        string `json:"busyTimeout,omitempty"`
 }
 
+// DefaultReindexOnRelease keeps numeric IDs contiguous after a permanent release.
+const DefaultReindexOnRelease = true
+
 // Default returns the default configuration.
 func Default() Config {
+	reindex := DefaultReindexOnRelease
 	return Config{
-		SettleDuration: DefaultSettleDuration.String(),
+		SettleDuration:     core.FormatSettleDuration(DefaultSettleDuration),
+		ReindexOnRelease:   &reindex,
+		ReleaseGracePeriod: core.FormatSettleDuration(DefaultReleaseGracePeriod),
+		JournalMode:        storage.DefaultJournalMode,
+		BusyTimeout:        storage.DefaultBusyTimeout.String(),
 	}
 }
 
@@ -88,14 +106,30 @@ func Save(cfg Config) error {
 
 // Normalize ensures defaults are set and invalid values are sanitized.
 func Normalize(cfg Config) Config {
+	if cfg.ReindexOnRelease == nil {
+		reindex := DefaultReindexOnRelease
+		cfg.ReindexOnRelease = &reindex
+	}
 	cfg.Editor = strings.TrimSpace(cfg.Editor)
+	cfg.ReleaseGracePeriod = strings.TrimSpace(cfg.ReleaseGracePeriod)
+	if _, err := core.ParseSettleDuration(cfg.ReleaseGracePeriod); err != nil {
+		cfg.ReleaseGracePeriod = core.FormatSettleDuration(DefaultReleaseGracePeriod)
+	}
+	cfg.JournalMode = strings.ToLower(strings.TrimSpace(cfg.JournalMode))
+	if _, err := ParseJournalMode(cfg.JournalMode); err != nil {
+		cfg.JournalMode = storage.DefaultJournalMode
+	}
+	cfg.BusyTimeout = strings.TrimSpace(cfg.BusyTimeout)
+	if _, err := ParseBusyTimeout(cfg.BusyTimeout); err != nil {
+		cfg.BusyTimeout = storage.DefaultBusyTimeout.String()
+	}
 	cfg.SettleDuration = strings.TrimSpace(cfg.SettleDuration)
 	if cfg.SettleDuration == "" {
-		cfg.SettleDuration = DefaultSettleDuration.String()
+		cfg.SettleDuration = core.FormatSettleDuration(DefaultSettleDuration)
 		return cfg
 	}
-	if _, err := time.ParseDuration(cfg.SettleDuration); err != nil {
-		cfg.SettleDuration = DefaultSettleDuration.String()
+	if _, err := core.ParseSettleDuration(cfg.SettleDuration); err != nil {
+		cfg.SettleDuration = core.FormatSettleDuration(DefaultSettleDuration)
 	}
 	return cfg
 }
@@ -103,9 +137,60 @@ func Normalize(cfg Config) Config {
 // SettleDuration returns a parsed duration, falling back to DefaultSettleDuration.
 func SettleDuration(cfg Config) time.Duration {
 	cfg = Normalize(cfg)
-	d, err := time.ParseDuration(cfg.SettleDuration)
+	d, err := core.ParseSettleDuration(cfg.SettleDuration)
 	if err != nil {
 		return DefaultSettleDuration
 	}
 	return d
 }
+
+// ReleaseGracePeriod returns how long released thoughts are kept, falling back to DefaultReleaseGracePeriod.
+// It accepts day and week units such as "30d" or "2w".
+func ReleaseGracePeriod(cfg Config) time.Duration {
+	cfg = Normalize(cfg)
+	d, err := core.ParseSettleDuration(cfg.ReleaseGracePeriod)
+	if err != nil {
+		return DefaultReleaseGracePeriod
+	}
+	return d
+}
+
+// ReindexOnRelease reports whether numeric IDs should be renumbered after a permanent release.
+func ReindexOnRelease(cfg Config) bool {
+	cfg = Normalize(cfg)
+	return *cfg.ReindexOnRelease
+}
+
+// ParseJournalMode checks a SQLite journal mode such as "wal" or "delete".
+func ParseJournalMode(value string) (string, error) {
+	mode := strings.ToLower(strings.TrimSpace(value))
+	for _, known := range storage.JournalModes {
+		if mode == known {
+			return mode, nil
+		}
+	}
+	return "", fmt.Errorf("journal mode must be one of %s", strings.Join(storage.JournalModes, ", "))
+}
+
+// ParseBusyTimeout parses how long to wait on a locked database, such as "5s" or "0s".
+func ParseBusyTimeout(value string) (time.Duration, error) {
+	d, err := time.ParseDuration(strings.TrimSpace(value))
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("busy timeout must be a duration such as 5s")
+	}
+	return d, nil
+}
+
+// Connection returns the SQLite connection options cfg asks for, falling back to storage's defaults.
+func Connection(cfg Config) storage.Options {
+	cfg = Normalize(cfg)
+	mode, err := ParseJournalMode(cfg.JournalMode)
+	if err != nil {
+		mode = storage.DefaultJournalMode
+	}
+	timeout, err := ParseBusyTimeout(cfg.BusyTimeout)
+	if err != nil {
+		timeout = storage.DefaultBusyTimeout
+	}
+	return storage.Options{JournalMode: mode, BusyTimeout: timeout}
+}
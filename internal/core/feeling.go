@@ -0,0 +1,64 @@
+package core
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Valence runs from -2 (heavy) to +2 (light); energy runs from 1 (low) to 5 (high).
+// Both are optional: nil means the feeling was never named.
+const (
+	MinValence = -2
+	MaxValence = 2
+	MinEnergy  = 1
+	MaxEnergy  = 5
+)
+
+// ValidateFeeling checks that any valence or energy given is within range.
+func ValidateFeeling(valence, energy *int) error {
+	if valence != nil && (*valence < MinValence || *valence > MaxValence) {
+		return fmt.Errorf("feel: valence %d is outside %d..%d", *valence, MinValence, MaxValence)
+	}
+	if energy != nil && (*energy < MinEnergy || *energy > MaxEnergy) {
+		return fmt.Errorf("feel: energy %d is outside %d..%d", *energy, MinEnergy, MaxEnergy)
+	}
+	return nil
+}
+
+// FormatValence renders a valence with its sign, e.g. "+1", "0", "-2", or "none".
+func FormatValence(valence *int) string {
+	if valence == nil {
+		return "none"
+	}
+	if *valence > 0 {
+		return "+" + strconv.Itoa(*valence)
+	}
+	return strconv.Itoa(*valence)
+}
+
+// FormatEnergy renders an energy level, or "none".
+func FormatEnergy(energy *int) string {
+	if energy == nil {
+		return "none"
+	}
+	return strconv.Itoa(*energy)
+}
+
+// FormatFeeling renders both fields, e.g. "valence +1, energy 4". Feel events use it as their note.
+func FormatFeeling(valence, energy *int) string {
+	return "valence " + FormatValence(valence) + ", energy " + FormatEnergy(energy)
+}
+
+// ParseFeelingValue parses one valence or energy value. "none" (or "-") clears it and yields nil.
+func ParseFeelingValue(value string) (*int, error) {
+	value = strings.TrimSpace(value)
+	if strings.EqualFold(value, "none") || value == "-" {
+		return nil, nil
+	}
+	n, err := strconv.Atoi(strings.TrimPrefix(value, "+"))
+	if err != nil {
+		return nil, fmt.Errorf("feel: %q is not a number", value)
+	}
+	return &n, nil
+}
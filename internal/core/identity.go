@@ -0,0 +1,41 @@
+package core
+
+import (
+	"strconv"
+	"strings"
+)
+
+// PublicIDLength is the length of a thought's stable public identifier.
+const PublicIDLength = 11
+
+// ReindexOnRelease controls whether numeric thought IDs are renumbered after a permanent release.
+// Public IDs never change either way. It can be overridden via configuration.
+var ReindexOnRelease = true
+
+// IsPublicID reports whether value has the shape of a public ID: a lowercase letter followed by ten hex digits.
+// The leading letter keeps public IDs from ever being mistaken for numeric IDs.
+func IsPublicID(value string) bool {
+	if len(value) != PublicIDLength {
+		return false
+	}
+	if value[0] < 'a' || value[0] > 'z' {
+		return false
+	}
+	for i := 1; i < len(value); i++ {
+		c := value[i]
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// LooksLikeThoughtRef reports whether value is shaped like a numeric ID or a public ID.
+// A leading "#" and surrounding space are ignored, and public IDs match in any case.
+func LooksLikeThoughtRef(value string) bool {
+	ref := strings.ToLower(strings.TrimPrefix(strings.TrimSpace(value), "#"))
+	if id, err := strconv.ParseInt(ref, 10, 64); err == nil {
+		return id > 0
+	}
+	return IsPublicID(ref)
+}
@@ -0,0 +1,23 @@
+package core
+
+import "testing"
+
+func TestLooksLikeThoughtRef(t *testing.T) {
+	tests := map[string]bool{
+		"4":            true,
+		"#12":          true,
+		" k3f09a1c2d4": true,
+		"#K3F09A1C2D4": true,
+		"0":            false,
+		"-3":           false,
+		"k3f09a1c2d":   false,
+		"3f09a1c2d4e":  false,
+		"learn":        false,
+		"":             false,
+	}
+	for value, want := range tests {
+		if got := LooksLikeThoughtRef(value); got != want {
+			t.Fatalf("LooksLikeThoughtRef(%q) = %v, want %v", value, got, want)
+		}
+	}
+}
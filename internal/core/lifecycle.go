@@ -0,0 +1,186 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Action names a lifecycle operation. Rules are looked up by action as well as by state,
+// because two actions can share a from→to pair with different meaning: resolving a tended
+// thought and evolving it directly both end in evolved.
+type Action string
+
+const (
+	ActionTend    Action = "tend"
+	ActionResolve Action = "resolve"
+	ActionEvolve  Action = "evolve"
+	ActionArchive Action = "archive"
+	ActionRelease Action = "release"
+	ActionRevive  Action = "revive"
+	ActionRestore Action = "restore"
+)
+
+// Effect is something a transition does to a thought besides changing its state.
+// Every transition also bumps updated_at and appends one event.
+type Effect uint8
+
+const (
+	// EffectCountTend increments tend_counter and stamps last_tended_at.
+	EffectCountTend Effect = 1 << iota
+	// EffectResetEligibility moves eligibility_at a settle period past now and records that period on the event.
+	EffectResetEligibility
+	// EffectStampReleased sets released_at, starting the trash grace period.
+	EffectStampReleased
+	// EffectClearReleased clears released_at.
+	EffectClearReleased
+)
+
+// Has reports whether e includes every effect in f.
+func (e Effect) Has(f Effect) bool {
+	return e&f == f
+}
+
+// Transition is one allowed move between states.
+type Transition struct {
+	Action  Action
+	From    State
+	To      State
+	Event   string // kind of the event appended
+	Effects Effect
+}
+
+// Transitions is the whole lifecycle. Storage consults it for every state change, so a new
+// state or move is added here and nowhere else.
+var Transitions = []Transition{
+	{ActionTend, StateCaptured, StateTended, "state_change", EffectCountTend},
+	{ActionTend, StateResting, StateTended, "state_change", EffectCountTend},
+	{ActionTend, StateTended, StateTended, "state_change", EffectCountTend},
+
+	{ActionResolve, StateTended, StateResting, "state_change", EffectResetEligibility},
+	{ActionResolve, StateTended, StateEvolved, "state_change", 0},
+	{ActionResolve, StateTended, StateArchived, "state_change", 0},
+	{ActionResolve, StateTended, StateReleased, "state_change", EffectStampReleased},
+
+	{ActionEvolve, StateCaptured, StateEvolved, "state_change", 0},
+	{ActionEvolve, StateResting, StateEvolved, "state_change", 0},
+	{ActionEvolve, StateTended, StateEvolved, "state_change", 0},
+
+	{ActionArchive, StateCaptured, StateArchived, "state_change", 0},
+	{ActionArchive, StateResting, StateArchived, "state_change", 0},
+	{ActionArchive, StateTended, StateArchived, "state_change", 0},
+
+	{ActionRelease, StateCaptured, StateReleased, "state_change", EffectStampReleased},
+	{ActionRelease, StateResting, StateReleased, "state_change", EffectStampReleased},
+	{ActionRelease, StateTended, StateReleased, "state_change", EffectStampReleased},
+	{ActionRelease, StateEvolved, StateReleased, "state_change", EffectStampReleased},
+	{ActionRelease, StateArchived, StateReleased, "state_change", EffectStampReleased},
+
+	{ActionRevive, StateArchived, StateResting, "revived", EffectResetEligibility},
+	{ActionRevive, StateEvolved, StateResting, "revived", EffectResetEligibility},
+
+	{ActionRestore, StateReleased, StateCaptured, "restored", EffectClearReleased},
+	{ActionRestore, StateReleased, StateResting, "restored", EffectClearReleased},
+	{ActionRestore, StateReleased, StateTended, "restored", EffectClearReleased},
+	{ActionRestore, StateReleased, StateEvolved, "restored", EffectClearReleased},
+	{ActionRestore, StateReleased, StateArchived, "restored", EffectClearReleased},
+}
+
+// States lists every lifecycle state in the order a thought usually meets them.
+var States = []State{StateCaptured, StateResting, StateTended, StateEvolved, StateReleased, StateArchived}
+
+// ParseState reads a lifecycle state by name, ignoring case and surrounding space.
+func ParseState(value string) (State, error) {
+	name := State(strings.ToLower(strings.TrimSpace(value)))
+	for _, state := range States {
+		if name == state {
+			return state, nil
+		}
+	}
+	return "", fmt.Errorf("invalid state %q", strings.TrimSpace(value))
+}
+
+// ParseStates reads a comma-separated list of lifecycle states, such as "resting,captured".
+func ParseStates(value string) ([]State, error) {
+	var states []State
+	for _, part := range strings.Split(value, ",") {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+		state, err := ParseState(part)
+		if err != nil {
+			return nil, err
+		}
+		states = append(states, state)
+	}
+	if len(states) == 0 {
+		return nil, fmt.Errorf("no states in %q", value)
+	}
+	return states, nil
+}
+
+// IsTerminal reports whether a state has left the tend cycle. Only revive or restore bring a thought back.
+func IsTerminal(state State) bool {
+	switch state {
+	case StateEvolved, StateReleased, StateArchived:
+		return true
+	}
+	return false
+}
+
+// CanSurface reports whether thoughts in a state ever become ready for tending.
+func CanSurface(state State) bool {
+	return state == StateCaptured || state == StateResting
+}
+
+// LookupTransition returns the rule for moving from one state to another by action,
+// or a *TransitionError saying why that move is not allowed.
+func LookupTransition(action Action, from, to State) (Transition, error) {
+	for _, t := range Transitions {
+		if t.Action == action && t.From == from && t.To == to {
+			return t, nil
+		}
+	}
+	return Transition{}, &TransitionError{Action: action, From: from, To: to}
+}
+
+// TransitionError is returned for a move the lifecycle does not allow.
+type TransitionError struct {
+	Action Action
+	From   State
+	To     State
+}
+
+func (e *TransitionError) Error() string {
+	var froms []State
+	fromTerminal := false
+	for _, t := range Transitions {
+		if t.Action == e.Action && t.To == e.To {
+			froms = append(froms, t.From)
+			fromTerminal = fromTerminal || IsTerminal(t.From)
+		}
+	}
+	switch {
+	case len(froms) == 0:
+		return fmt.Sprintf("invalid next state %q", e.To)
+	case IsTerminal(e.From) && !fromTerminal:
+		return fmt.Sprintf("thought is in terminal state (%s)", e.From)
+	case e.From == e.To && IsTerminal(e.From):
+		return fmt.Sprintf("thought is already %s", e.From)
+	}
+	names := make([]string, 0, len(froms))
+	for _, from := range froms {
+		names = append(names, string(from))
+	}
+	return fmt.Sprintf("only %s thoughts can be %s (currently %s)", strings.Join(names, " or "), actionDone[e.Action], e.From)
+}
+
+// actionDone is how an action reads in "only tended thoughts can be resolved".
+var actionDone = map[Action]string{
+	ActionTend:    "tended",
+	ActionResolve: "resolved",
+	ActionEvolve:  "evolved",
+	ActionArchive: "archived",
+	ActionRelease: "released",
+	ActionRevive:  "revived",
+	ActionRestore: "restored",
+}
@@ -0,0 +1,204 @@
+package core
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+var allActions = []Action{ActionTend, ActionResolve, ActionEvolve, ActionArchive, ActionRelease, ActionRevive, ActionRestore}
+
+func TestLookupTransitionAllowsExactlyTheLifecycle(t *testing.T) {
+	type move struct {
+		action   Action
+		from, to State
+	}
+	allowed := map[move]bool{
+		{ActionTend, StateCaptured, StateTended}: true,
+		{ActionTend, StateResting, StateTended}:  true,
+		{ActionTend, StateTended, StateTended}:   true,
+
+		{ActionResolve, StateTended, StateResting}:  true,
+		{ActionResolve, StateTended, StateEvolved}:  true,
+		{ActionResolve, StateTended, StateArchived}: true,
+		{ActionResolve, StateTended, StateReleased}: true,
+
+		{ActionEvolve, StateCaptured, StateEvolved}: true,
+		{ActionEvolve, StateResting, StateEvolved}:  true,
+		{ActionEvolve, StateTended, StateEvolved}:   true,
+
+		{ActionArchive, StateCaptured, StateArchived}: true,
+		{ActionArchive, StateResting, StateArchived}:  true,
+		{ActionArchive, StateTended, StateArchived}:   true,
+
+		{ActionRelease, StateCaptured, StateReleased}: true,
+		{ActionRelease, StateResting, StateReleased}:  true,
+		{ActionRelease, StateTended, StateReleased}:   true,
+		{ActionRelease, StateEvolved, StateReleased}:  true,
+		{ActionRelease, StateArchived, StateReleased}: true,
+
+		{ActionRevive, StateArchived, StateResting}: true,
+		{ActionRevive, StateEvolved, StateResting}:  true,
+
+		{ActionRestore, StateReleased, StateCaptured}: true,
+		{ActionRestore, StateReleased, StateResting}:  true,
+		{ActionRestore, StateReleased, StateTended}:   true,
+		{ActionRestore, StateReleased, StateEvolved}:  true,
+		{ActionRestore, StateReleased, StateArchived}: true,
+	}
+
+	for _, action := range allActions {
+		for _, from := range States {
+			for _, to := range States {
+				rule, err := LookupTransition(action, from, to)
+				want := allowed[move{action, from, to}]
+				if want && err != nil {
+					t.Fatalf("%s %s → %s: %v, want allowed", action, from, to, err)
+				}
+				if !want {
+					var transitionErr *TransitionError
+					if !errors.As(err, &transitionErr) {
+						t.Fatalf("%s %s → %s: err = %v, want *TransitionError", action, from, to, err)
+					}
+					continue
+				}
+				if rule.Action != action || rule.From != from || rule.To != to || rule.Event == "" {
+					t.Fatalf("%s %s → %s: rule = %+v", action, from, to, rule)
+				}
+			}
+		}
+	}
+
+	if len(Transitions) != len(allowed) {
+		t.Fatalf("len(Transitions) = %d, want %d with no duplicates", len(Transitions), len(allowed))
+	}
+	if _, err := LookupTransition(ActionTend, State("wilted"), StateTended); err == nil {
+		t.Fatal("unknown state was allowed to move")
+	}
+}
+
+func TestTransitionsCarryTheirEventsAndEffects(t *testing.T) {
+	cases := []struct {
+		action  Action
+		from    State
+		to      State
+		event   string
+		effects Effect
+	}{
+		{ActionTend, StateCaptured, StateTended, "state_change", EffectCountTend},
+		{ActionTend, StateResting, StateTended, "state_change", EffectCountTend},
+		{ActionTend, StateTended, StateTended, "state_change", EffectCountTend},
+		{ActionResolve, StateTended, StateResting, "state_change", EffectResetEligibility},
+		{ActionResolve, StateTended, StateEvolved, "state_change", 0},
+		{ActionResolve, StateTended, StateArchived, "state_change", 0},
+		{ActionResolve, StateTended, StateReleased, "state_change", EffectStampReleased},
+		{ActionEvolve, StateCaptured, StateEvolved, "state_change", 0},
+		{ActionArchive, StateResting, StateArchived, "state_change", 0},
+		{ActionRelease, StateArchived, StateReleased, "state_change", EffectStampReleased},
+		{ActionRevive, StateArchived, StateResting, "revived", EffectResetEligibility},
+		{ActionRevive, StateEvolved, StateResting, "revived", EffectResetEligibility},
+		{ActionRestore, StateReleased, StateCaptured, "restored", EffectClearReleased},
+		{ActionRestore, StateReleased, StateResting, "restored", EffectClearReleased},
+	}
+	for _, tc := range cases {
+		rule, err := LookupTransition(tc.action, tc.from, tc.to)
+		if err != nil {
+			t.Fatalf("%s %s → %s: %v", tc.action, tc.from, tc.to, err)
+		}
+		if rule.Event != tc.event || rule.Effects != tc.effects {
+			t.Fatalf("%s %s → %s: event %q effects %04b, want %q %04b", tc.action, tc.from, tc.to, rule.Event, rule.Effects, tc.event, tc.effects)
+		}
+	}
+
+	both := EffectCountTend | EffectResetEligibility
+	if !both.Has(EffectCountTend) || !both.Has(both) || both.Has(EffectStampReleased) || EffectCountTend.Has(both) {
+		t.Fatalf("Effect.Has does not match set membership")
+	}
+}
+
+func TestTransitionErrorsExplainTheGuard(t *testing.T) {
+	cases := []struct {
+		action   Action
+		from, to State
+		want     string
+	}{
+		{ActionTend, StateReleased, StateTended, "thought is in terminal state (released)"},
+		{ActionEvolve, StateEvolved, StateEvolved, "thought is in terminal state (evolved)"},
+		{ActionArchive, StateArchived, StateArchived, "thought is in terminal state (archived)"},
+		{ActionResolve, StateCaptured, StateResting, "only tended thoughts can be resolved (currently captured)"},
+		{ActionResolve, StateTended, StateCaptured, `invalid next state "captured"`},
+		{ActionRelease, StateReleased, StateReleased, "thought is already released"},
+		{ActionRevive, StateResting, StateResting, "only archived or evolved thoughts can be revived (currently resting)"},
+		{ActionRestore, StateCaptured, StateResting, "only released thoughts can be restored (currently captured)"},
+	}
+	for _, tc := range cases {
+		_, err := LookupTransition(tc.action, tc.from, tc.to)
+		if err == nil || err.Error() != tc.want {
+			t.Fatalf("%s %s → %s: err = %v, want %q", tc.action, tc.from, tc.to, err, tc.want)
+		}
+	}
+}
+
+func TestStateClassesFollowTheLifecycle(t *testing.T) {
+	cases := []struct {
+		state    State
+		terminal bool
+		surfaces bool
+	}{
+		{StateCaptured, false, true},
+		{StateResting, false, true},
+		{StateTended, false, false},
+		{StateEvolved, true, false},
+		{StateReleased, true, false},
+		{StateArchived, true, false},
+		{State("wilted"), false, false},
+	}
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	for _, tc := range cases {
+		if got := IsTerminal(tc.state); got != tc.terminal {
+			t.Fatalf("IsTerminal(%s) = %t, want %t", tc.state, got, tc.terminal)
+		}
+		if got := CanSurface(tc.state); got != tc.surfaces {
+			t.Fatalf("CanSurface(%s) = %t, want %t", tc.state, got, tc.surfaces)
+		}
+		due := Thought{CurrentState: tc.state, EligibilityAt: now}
+		if got := EligibleToSurface(due, now); got != tc.surfaces {
+			t.Fatalf("EligibleToSurface(%s, due) = %t, want %t", tc.state, got, tc.surfaces)
+		}
+		early := Thought{CurrentState: tc.state, EligibilityAt: now.Add(time.Minute)}
+		if EligibleToSurface(early, now) {
+			t.Fatalf("EligibleToSurface(%s) before eligibility_at = true", tc.state)
+		}
+		if EligibleToSurface(Thought{CurrentState: tc.state}, now) {
+			t.Fatalf("EligibleToSurface(%s) with zero eligibility_at = true", tc.state)
+		}
+	}
+
+	// Every terminal state is left only by revive, restore, or release.
+	for _, rule := range Transitions {
+		if !IsTerminal(rule.From) {
+			continue
+		}
+		switch rule.Action {
+		case ActionRevive, ActionRestore, ActionRelease:
+		default:
+			t.Fatalf("terminal %s is left by %s", rule.From, rule.Action)
+		}
+	}
+}
+
+func TestParseStatesReadsCommaSeparatedNames(t *testing.T) {
+	got, err := ParseStates(" Tended, resting,,")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0] != StateTended || got[1] != StateResting {
+		t.Fatalf("ParseStates = %v, want [tended resting]", got)
+	}
+	if _, err := ParseStates(" , "); err == nil {
+		t.Fatal("ParseStates accepted an empty list")
+	}
+	if _, err := ParseState("wilted"); err == nil {
+		t.Fatal("ParseState accepted an unknown state")
+	}
+}
@@ -0,0 +1,34 @@
+package core
+
+import "fmt"
+
+// MaxTagLength bounds how long a single tag name may be.
+const MaxTagLength = 32
+
+// NormalizeTag lowercases a tag and strips a leading # so "#Work" and "work" name the same tag.
+// A tag starts with a letter and may contain letters, digits, '-', '_', and '/'.
+func NormalizeTag(value string) (string, error) {
+	tag := []byte(value)
+	if len(tag) > 0 && tag[0] == '#' {
+		tag = tag[1:]
+	}
+	if len(tag) == 0 {
+		return "", fmt.Errorf("tag: name is empty")
+	}
+	if len(tag) > MaxTagLength {
+		return "", fmt.Errorf("tag: %q is longer than %d characters", value, MaxTagLength)
+	}
+	for i, c := range tag {
+		if c >= 'A' && c <= 'Z' {
+			c += 'a' - 'A'
+			tag[i] = c
+		}
+		switch {
+		case c >= 'a' && c <= 'z':
+		case i > 0 && (c >= '0' && c <= '9' || c == '-' || c == '_' || c == '/'):
+		default:
+			return "", fmt.Errorf("tag: %q must start with a letter and use only letters, digits, '-', '_', or '/'", value)
+		}
+	}
+	return string(tag), nil
+}
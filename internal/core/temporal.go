@@ -1,6 +1,9 @@
 package core
 
 import (
+	"fmt"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -8,17 +11,23 @@ import (
 // It can be overridden via configuration.
 var SettleDuration = 18 * time.Hour
 
+// ReleaseGracePeriod is how long a released thought waits in the trash before it is purged for good.
+// It can be overridden via configuration.
+var ReleaseGracePeriod = 30 * 24 * time.Hour
+
+// PurgeAt returns when a released thought leaves the trash for good, or the zero time
+// for a thought that is not released.
+func PurgeAt(thought Thought) time.Time {
+	if thought.CurrentState != StateReleased || thought.ReleasedAt == nil {
+		return time.Time{}
+	}
+	return thought.ReleasedAt.Add(ReleaseGracePeriod)
+}
+
 // EligibleToSurface reports whether a thought is eligible to be tended at the given time.
 func EligibleToSurface(thought Thought, now time.Time) bool {
-	// Only captured and resting thoughts can surface for tending.
-	switch thought.CurrentState {
-	case StateCaptured, StateResting:
-		// eligible states
-	case StateEvolved, StateReleased, StateArchived:
-		// terminal states are never eligible
-		return false
-	default:
-		// unknown states are treated as ineligible
+	// Only captured and resting thoughts can surface for tending; terminal and unknown states never do.
+	if !CanSurface(thought.CurrentState) {
 		return false
 	}
 
@@ -30,3 +39,92 @@ func EligibleToSurface(thought Thought, now time.Time) bool {
 	// Eligibility is reached once now is at or after eligibility_at.
 	return !now.Before(thought.EligibilityAt)
 }
+
+// ParseSettleDuration parses how long a thought should rest. It accepts Go durations
+// ("90m", "2h30m") plus whole days and weeks ("3d", "2w", "1w2d"), and rejects anything not positive.
+func ParseSettleDuration(value string) (time.Duration, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if value == "" {
+		return 0, fmt.Errorf("settle duration is empty")
+	}
+
+	var total time.Duration
+	rest := value
+	for rest != "" {
+		i := 0
+		for i < len(rest) && rest[i] >= '0' && rest[i] <= '9' {
+			i++
+		}
+		if i == 0 || i == len(rest) || (rest[i] != 'd' && rest[i] != 'w') {
+			break
+		}
+		n, err := strconv.Atoi(rest[:i])
+		if err != nil {
+			return 0, fmt.Errorf("settle duration %q is invalid", value)
+		}
+		unit := 24 * time.Hour
+		if rest[i] == 'w' {
+			unit = 7 * 24 * time.Hour
+		}
+		total += time.Duration(n) * unit
+		rest = rest[i+1:]
+	}
+	if rest != "" {
+		d, err := time.ParseDuration(rest)
+		if err != nil {
+			return 0, fmt.Errorf("settle duration %q is invalid (try 18h, 3d, or 1w)", value)
+		}
+		total += d
+	}
+	if total <= 0 {
+		return 0, fmt.Errorf("settle duration %q must be longer than zero", value)
+	}
+	return total, nil
+}
+
+// FormatSettleDuration renders a rest length the way people choose one: "3d", "1w", "1d12h", or "90m".
+func FormatSettleDuration(d time.Duration) string {
+	if d <= 0 {
+		return "0s"
+	}
+	const day = 24 * time.Hour
+	if d%(7*day) == 0 {
+		return fmt.Sprintf("%dw", d/(7*day))
+	}
+	out := ""
+	if days := d / day; days > 0 {
+		out = fmt.Sprintf("%dd", days)
+		d -= days * day
+	}
+	if d > 0 {
+		clock := d.String()
+		if strings.HasSuffix(clock, "m0s") {
+			clock = strings.TrimSuffix(clock, "0s")
+		}
+		if strings.HasSuffix(clock, "h0m") {
+			clock = strings.TrimSuffix(clock, "0m")
+		}
+		out += clock
+	}
+	return out
+}
+
+// SettleFor returns d when it is positive and SettleDuration otherwise.
+func SettleFor(d time.Duration) time.Duration {
+	if d > 0 {
+		return d
+	}
+	return SettleDuration
+}
+
+// ParseTime reads a date (2006-01-02, taken as midnight UTC) or an RFC 3339 timestamp.
+func ParseTime(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if t, err := time.Parse(time.RFC3339Nano, value); err == nil {
+		return t.UTC(), nil
+	}
+	if t, err := time.Parse("2006-01-02", value); err == nil {
+		return t.UTC(), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q (use 2006-01-02 or an RFC 3339 timestamp)", value)
+}
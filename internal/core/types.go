@@ -19,6 +19,7 @@ const (
 // Thought represents the current snapshot of a cognitive unit.
 type Thought struct {
 	ID            int64      `db:"id"`
+	PublicID      string     `db:"public_id"`
 	Content       string     `db:"content"`
 	CurrentState  State      `db:"current_state"`
 	TendCounter   int        `db:"tend_counter"`
@@ -28,6 +29,8 @@ type Thought struct {
 	EligibilityAt time.Time  `db:"eligibility_at"`
 	Valence       *int       `db:"valence"`
 	Energy        *int       `db:"energy"`
+	ReleasedAt    *time.Time `db:"released_at"`
+	Tags          []string   `db:"-"`
 }
 
 // Event represents a single append-only history record for a thought.
@@ -39,4 +42,6 @@ type Event struct {
 	PreviousState *State    `db:"previous_state"`
 	NextState     *State    `db:"next_state"`
 	Note          *string   `db:"note"`
+	// SettleFor is the rest length chosen when this event put the thought to rest.
+	SettleFor *time.Duration `db:"settle_seconds"`
 }
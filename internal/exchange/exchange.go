@@ -0,0 +1,239 @@
+// Package exchange defines Peony's versioned export format.
+//
+// A JSON export is one Document. An NDJSON export is a Header line followed by
+// one Thought per line. Field names are stable within a FormatVersion; any
+// breaking change bumps FormatVersion. The README documents the schema.
+// Read accepts either shape.
+package exchange
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/divijg19/peony/internal/core"
+)
+
+// FormatName identifies a Peony export regardless of version.
+const FormatName = "peony.export"
+
+// FormatVersion is the version of the export schema written by this build.
+const FormatVersion = 1
+
+// Header describes an export. It is embedded in a Document and is the first line of NDJSON.
+type Header struct {
+	Format        string    `json:"format"`
+	Version       int       `json:"version"`
+	ExportedAt    time.Time `json:"exported_at"`
+	SchemaVersion int       `json:"schema_version"`
+}
+
+// Document is a complete JSON export.
+type Document struct {
+	Header
+	Thoughts []Thought `json:"thoughts"`
+}
+
+// Thought is the exported form of core.Thought with its full event history.
+type Thought struct {
+	ID            int64      `json:"id"`
+	PublicID      string     `json:"public_id"`
+	Content       string     `json:"content"`
+	State         core.State `json:"state"`
+	TendCounter   int        `json:"tend_counter"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+	LastTendedAt  *time.Time `json:"last_tended_at"`
+	EligibilityAt time.Time  `json:"eligibility_at"`
+	Valence       *int       `json:"valence"`
+	Energy        *int       `json:"energy"`
+	ReleasedAt    *time.Time `json:"released_at,omitempty"`
+	Tags          []string   `json:"tags"`
+	Events        []Event    `json:"events"`
+}
+
+// Event is the exported form of core.Event. The owning thought is implied by nesting.
+type Event struct {
+	ID            int64       `json:"id"`
+	Kind          string      `json:"kind"`
+	At            time.Time   `json:"at"`
+	PreviousState *core.State `json:"previous_state"`
+	NextState     *core.State `json:"next_state"`
+	Note          *string     `json:"note"`
+	// SettleSeconds is how long a rest was chosen to last. Only events that put a thought to rest carry it.
+	SettleSeconds *int64 `json:"settle_seconds,omitempty"`
+}
+
+// NewHeader returns a header for an export taken now from a database at schemaVersion.
+func NewHeader(schemaVersion int) Header {
+	return Header{
+		Format:        FormatName,
+		Version:       FormatVersion,
+		ExportedAt:    time.Now().UTC(),
+		SchemaVersion: schemaVersion,
+	}
+}
+
+// FromCore converts a thought and its events into the export form.
+func FromCore(thought core.Thought, events []core.Event) Thought {
+	tags := thought.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	out := Thought{
+		ID:            thought.ID,
+		PublicID:      thought.PublicID,
+		Content:       thought.Content,
+		State:         thought.CurrentState,
+		TendCounter:   thought.TendCounter,
+		CreatedAt:     thought.CreatedAt.UTC(),
+		UpdatedAt:     thought.UpdatedAt.UTC(),
+		LastTendedAt:  utcPointer(thought.LastTendedAt),
+		EligibilityAt: thought.EligibilityAt.UTC(),
+		Valence:       thought.Valence,
+		Energy:        thought.Energy,
+		ReleasedAt:    utcPointer(thought.ReleasedAt),
+		Tags:          tags,
+		Events:        make([]Event, 0, len(events)),
+	}
+	for _, event := range events {
+		out.Events = append(out.Events, Event{
+			ID:            event.ID,
+			Kind:          event.Kind,
+			At:            event.At.UTC(),
+			PreviousState: event.PreviousState,
+			NextState:     event.NextState,
+			Note:          event.Note,
+			SettleSeconds: durationSeconds(event.SettleFor),
+		})
+	}
+	return out
+}
+
+// WriteJSON writes doc as one indented JSON document.
+func WriteJSON(w io.Writer, doc Document) error {
+	if doc.Thoughts == nil {
+		doc.Thoughts = []Thought{}
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(doc); err != nil {
+		return fmt.Errorf("export json: %w", err)
+	}
+	return nil
+}
+
+// WriteNDJSON writes the header on the first line and then one thought per line.
+func WriteNDJSON(w io.Writer, doc Document) error {
+	encoder := json.NewEncoder(w)
+	if err := encoder.Encode(doc.Header); err != nil {
+		return fmt.Errorf("export ndjson: header: %w", err)
+	}
+	for _, thought := range doc.Thoughts {
+		if err := encoder.Encode(thought); err != nil {
+			return fmt.Errorf("export ndjson: thought %d: %w", thought.ID, err)
+		}
+	}
+	return nil
+}
+
+// Read decodes a JSON or NDJSON export. It rejects input that is not a Peony export
+// or was written with a newer FormatVersion than this build understands.
+func Read(r io.Reader) (Document, error) {
+	decoder := json.NewDecoder(r)
+
+	var first struct {
+		Header
+		Thoughts *[]Thought `json:"thoughts"`
+	}
+	if err := decoder.Decode(&first); err != nil {
+		if errors.Is(err, io.EOF) {
+			return Document{}, fmt.Errorf("read export: input is empty")
+		}
+		return Document{}, fmt.Errorf("read export: decode header: %w", err)
+	}
+	if first.Format != FormatName {
+		return Document{}, fmt.Errorf("read export: not a Peony export (format %q)", first.Format)
+	}
+	if first.Version < 1 || first.Version > FormatVersion {
+		return Document{}, fmt.Errorf("read export: export format version %d is not supported (this peony reads up to %d)", first.Version, FormatVersion)
+	}
+
+	doc := Document{Header: first.Header}
+	if first.Thoughts != nil {
+		doc.Thoughts = *first.Thoughts
+		return doc, nil
+	}
+
+	for line := 2; ; line++ {
+		var thought Thought
+		err := decoder.Decode(&thought)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return Document{}, fmt.Errorf("read export: decode ndjson line %d: %w", line, err)
+		}
+		doc.Thoughts = append(doc.Thoughts, thought)
+	}
+	return doc, nil
+}
+
+// ToCore converts an exported thought back into core types.
+func ToCore(thought Thought) (core.Thought, []core.Event) {
+	out := core.Thought{
+		ID:            thought.ID,
+		PublicID:      thought.PublicID,
+		Content:       thought.Content,
+		CurrentState:  thought.State,
+		TendCounter:   thought.TendCounter,
+		CreatedAt:     thought.CreatedAt.UTC(),
+		UpdatedAt:     thought.UpdatedAt.UTC(),
+		LastTendedAt:  utcPointer(thought.LastTendedAt),
+		EligibilityAt: thought.EligibilityAt.UTC(),
+		Valence:       thought.Valence,
+		Energy:        thought.Energy,
+		ReleasedAt:    utcPointer(thought.ReleasedAt),
+		Tags:          thought.Tags,
+	}
+	events := make([]core.Event, 0, len(thought.Events))
+	for _, event := range thought.Events {
+		events = append(events, core.Event{
+			ID:            event.ID,
+			ThoughtID:     thought.ID,
+			Kind:          event.Kind,
+			At:            event.At.UTC(),
+			PreviousState: event.PreviousState,
+			NextState:     event.NextState,
+			Note:          event.Note,
+			SettleFor:     secondsDuration(event.SettleSeconds),
+		})
+	}
+	return out, events
+}
+
+func durationSeconds(d *time.Duration) *int64 {
+	if d == nil {
+		return nil
+	}
+	seconds := int64(*d / time.Second)
+	return &seconds
+}
+
+func secondsDuration(seconds *int64) *time.Duration {
+	if seconds == nil {
+		return nil
+	}
+	d := time.Duration(*seconds) * time.Second
+	return &d
+}
+
+func utcPointer(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	utc := t.UTC()
+	return &utc
+}
@@ -0,0 +1,224 @@
+package exchange
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/divijg19/peony/internal/core"
+)
+
+func sampleDocument() Document {
+	created := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	tended := created.Add(2 * time.Hour)
+	released := created.Add(3 * time.Hour)
+	captured := core.StateCaptured
+	tendedState := core.StateTended
+	resting := core.StateResting
+	releasedState := core.StateReleased
+	note := "first line\nsecond line"
+	settle := int64((3*24*time.Hour + 4*time.Hour) / time.Second)
+	valence, energy := -1, 2
+
+	return Document{
+		Header: Header{Format: FormatName, Version: FormatVersion, ExportedAt: created.Add(24 * time.Hour), SchemaVersion: 7},
+		Thoughts: []Thought{
+			{
+				ID:            4,
+				PublicID:      "k3f09a1c2d4",
+				Content:       "learn rust",
+				State:         core.StateResting,
+				TendCounter:   1,
+				CreatedAt:     created,
+				UpdatedAt:     tended,
+				LastTendedAt:  &tended,
+				EligibilityAt: tended.Add(76 * time.Hour),
+				Valence:       &valence,
+				Energy:        &energy,
+				Tags:          []string{"code", "someday"},
+				Events: []Event{
+					{ID: 10, Kind: "captured", At: created, NextState: &captured},
+					{ID: 11, Kind: "tended", At: tended, PreviousState: &captured, NextState: &tendedState, Note: &note},
+					{ID: 12, Kind: "state_change", At: tended, PreviousState: &tendedState, NextState: &resting, SettleSeconds: &settle},
+				},
+			},
+			{
+				ID:            9,
+				PublicID:      "bcafe000001",
+				Content:       "let it go",
+				State:         core.StateReleased,
+				CreatedAt:     created,
+				UpdatedAt:     released,
+				EligibilityAt: created,
+				ReleasedAt:    &released,
+				Tags:          []string{},
+				Events: []Event{
+					{ID: 13, Kind: "captured", At: created, NextState: &captured},
+					{ID: 14, Kind: "state_change", At: released, PreviousState: &captured, NextState: &releasedState},
+				},
+			},
+		},
+	}
+}
+
+func TestJSONAndNDJSONRoundTrip(t *testing.T) {
+	want := sampleDocument()
+	for name, write := range map[string]func(*bytes.Buffer, Document) error{
+		"json":   func(b *bytes.Buffer, doc Document) error { return WriteJSON(b, doc) },
+		"ndjson": func(b *bytes.Buffer, doc Document) error { return WriteNDJSON(b, doc) },
+	} {
+		var buf bytes.Buffer
+		if err := write(&buf, want); err != nil {
+			t.Fatalf("%s: write: %v", name, err)
+		}
+		got, err := Read(&buf)
+		if err != nil {
+			t.Fatalf("%s: read: %v", name, err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("%s: round trip changed the document:\n got %+v\nwant %+v", name, got, want)
+		}
+	}
+}
+
+func TestNDJSONWritesTheHeaderFirst(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteNDJSON(&buf, sampleDocument()); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("ndjson lines = %d, want header and two thoughts", len(lines))
+	}
+	if !strings.Contains(lines[0], `"format":"peony.export"`) || strings.Contains(lines[0], `"thoughts"`) {
+		t.Fatalf("header line = %s", lines[0])
+	}
+}
+
+func TestCoreConversionKeepsIDsForImport(t *testing.T) {
+	// Import remaps ids and skips duplicates by public id, so both must survive the trip.
+	for _, want := range sampleDocument().Thoughts {
+		thought, events := ToCore(want)
+		if thought.ID != want.ID || thought.PublicID != want.PublicID {
+			t.Fatalf("ToCore ids = %d/%s, want %d/%s", thought.ID, thought.PublicID, want.ID, want.PublicID)
+		}
+		for _, event := range events {
+			if event.ThoughtID != want.ID {
+				t.Fatalf("event %d belongs to %d, want %d", event.ID, event.ThoughtID, want.ID)
+			}
+		}
+		if got := FromCore(thought, events); !reflect.DeepEqual(got, want) {
+			t.Fatalf("FromCore(ToCore(x)) changed the thought:\n got %+v\nwant %+v", got, want)
+		}
+	}
+}
+
+func TestReadRejectsForeignAndNewerExports(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"", "input is empty"},
+		{`{"format":"something.else","version":1}`, "not a Peony export"},
+		{`{"format":"peony.export","version":2,"thoughts":[]}`, "export format version 2 is not supported"},
+		{`{"format":"peony.export","version":0,"thoughts":[]}`, "export format version 0 is not supported"},
+	}
+	for _, tt := range tests {
+		_, err := Read(strings.NewReader(tt.input))
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Fatalf("Read(%q) error = %v, want %q", tt.input, err, tt.want)
+		}
+	}
+}
+
+func TestReadRejectsTruncatedNDJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteNDJSON(&buf, sampleDocument()); err != nil {
+		t.Fatal(err)
+	}
+	truncated := buf.String()[:buf.Len()-20]
+	_, err := Read(strings.NewReader(truncated))
+	if err == nil || !strings.Contains(err.Error(), "decode ndjson line 3") {
+		t.Fatalf("Read(truncated) error = %v, want a line 3 decode error", err)
+	}
+}
+
+func TestMarkdownRoundTripKeepsHistory(t *testing.T) {
+	want := sampleDocument()
+	dir := t.TempDir()
+	paths, err := WriteMarkdown(dir, want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) != 2 || filepath.Base(paths[0]) != "k3f09a1c2d4-learn-rust.md" {
+		t.Fatalf("paths = %v", paths)
+	}
+
+	got, err := ReadMarkdown(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Markdown carries no event ids; the importer assigns new ones.
+	for i := range want.Thoughts {
+		for j := range want.Thoughts[i].Events {
+			want.Thoughts[i].Events[j].ID = 0
+		}
+	}
+	if !reflect.DeepEqual(got.Thoughts, want.Thoughts) {
+		t.Fatalf("markdown round trip changed the thoughts:\n got %+v\nwant %+v", got.Thoughts, want.Thoughts)
+	}
+}
+
+func TestParseMarkdownTreatsPlainNotesAsCaptured(t *testing.T) {
+	at := time.Date(2026, 5, 6, 7, 8, 9, 0, time.UTC)
+	thought, err := ParseMarkdown("just a note\n", at)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if thought.Content != "just a note" || thought.State != core.StateCaptured || !thought.CreatedAt.Equal(at) {
+		t.Fatalf("plain note = %+v", thought)
+	}
+	if len(thought.Events) != 1 || thought.Events[0].Kind != "captured" {
+		t.Fatalf("plain note events = %+v", thought.Events)
+	}
+}
+
+func TestParseMarkdownRejectsMalformedFiles(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"unclosed front matter", "---\nid: 1\ncontent\n", "front matter is not closed"},
+		{"line without a key", "---\nid: 1\njust words\n---\n\ncontent\n", "front matter line 3"},
+		{"bad id", "---\nid: one\n---\n\ncontent\n", "front matter id"},
+		{"bad time", "---\ncreated_at: yesterday\n---\n\ncontent\n", "front matter created_at"},
+		{"empty content", "---\nid: 1\n---\n\n## History\n", "content is empty"},
+		{"bad history line", "content\n\n## History\n\n- sometime captured\n", "history line 2"},
+		{"note before event", "content\n\n## History\n\n  > orphan\n", "note before any event"},
+	}
+	for _, tt := range tests {
+		_, err := ParseMarkdown(tt.input, time.Now())
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Fatalf("%s: error = %v, want %q", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestReadMarkdownNamesTheBadFile(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "broken.md"), []byte("---\nid: 1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "ignored.txt"), []byte("not markdown"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	_, err := ReadMarkdown(dir)
+	if err == nil || !strings.Contains(err.Error(), "broken.md") {
+		t.Fatalf("ReadMarkdown error = %v, want it to name broken.md", err)
+	}
+}
@@ -0,0 +1,381 @@
+package exchange
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/divijg19/peony/internal/core"
+)
+
+// historyHeading starts the event history section, which is always the last part of a file.
+const historyHeading = "## History"
+
+// WriteMarkdown writes one Markdown file per thought into dir and returns the file paths.
+//
+// Each file starts with YAML front matter holding the thought's fields, then its content,
+// then a "## History" section with one list item per event, such as
+// "- 2026-01-02T03:04:05Z state_change (captured → resting)", followed by any note
+// as "  > " quoted lines.
+func WriteMarkdown(dir string, doc Document) ([]string, error) {
+	if strings.TrimSpace(dir) == "" {
+		return nil, fmt.Errorf("export markdown: empty directory")
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("export markdown: create directory: %w", err)
+	}
+
+	paths := make([]string, 0, len(doc.Thoughts))
+	for _, thought := range doc.Thoughts {
+		path := filepath.Join(dir, markdownFileName(thought))
+		if err := os.WriteFile(path, []byte(FormatMarkdown(thought)), 0o644); err != nil {
+			return nil, fmt.Errorf("export markdown: write #%d: %w", thought.ID, err)
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+// FormatMarkdown renders one thought as front matter, content, and history.
+func FormatMarkdown(thought Thought) string {
+	var b strings.Builder
+	b.WriteString("---\n")
+	fmt.Fprintf(&b, "id: %d\n", thought.ID)
+	fmt.Fprintf(&b, "public_id: %s\n", thought.PublicID)
+	fmt.Fprintf(&b, "state: %s\n", thought.State)
+	fmt.Fprintf(&b, "created_at: %s\n", formatMarkdownTime(thought.CreatedAt))
+	fmt.Fprintf(&b, "updated_at: %s\n", formatMarkdownTime(thought.UpdatedAt))
+	fmt.Fprintf(&b, "eligibility_at: %s\n", formatMarkdownTime(thought.EligibilityAt))
+	if thought.LastTendedAt != nil {
+		fmt.Fprintf(&b, "last_tended_at: %s\n", formatMarkdownTime(*thought.LastTendedAt))
+	}
+	if thought.ReleasedAt != nil {
+		fmt.Fprintf(&b, "released_at: %s\n", formatMarkdownTime(*thought.ReleasedAt))
+	}
+	fmt.Fprintf(&b, "valence: %s\n", formatMarkdownInt(thought.Valence))
+	fmt.Fprintf(&b, "energy: %s\n", formatMarkdownInt(thought.Energy))
+	fmt.Fprintf(&b, "tend_counter: %d\n", thought.TendCounter)
+	fmt.Fprintf(&b, "tags: [%s]\n", strings.Join(thought.Tags, ", "))
+	b.WriteString("---\n\n")
+
+	b.WriteString(strings.TrimSpace(thought.Content))
+	b.WriteString("\n\n")
+
+	b.WriteString(historyHeading)
+	b.WriteString("\n\n")
+	for _, event := range thought.Events {
+		fmt.Fprintf(&b, "- %s %s", formatMarkdownTime(event.At), event.Kind)
+		if event.PreviousState != nil || event.NextState != nil {
+			fmt.Fprintf(&b, " (%s → %s)", markdownState(event.PreviousState), markdownState(event.NextState))
+		}
+		if event.SettleSeconds != nil {
+			fmt.Fprintf(&b, " for %s", core.FormatSettleDuration(time.Duration(*event.SettleSeconds)*time.Second))
+		}
+		b.WriteString("\n")
+		if event.Note != nil {
+			for _, line := range strings.Split(*event.Note, "\n") {
+				b.WriteString(strings.TrimRight("  > "+line, " "))
+				b.WriteString("\n")
+			}
+		}
+	}
+	return b.String()
+}
+
+// ReadMarkdown reads every .md file in dir (not recursively) as a thought.
+// Files written by WriteMarkdown round-trip exactly. Plain notes without front matter
+// become captured thoughts created at the file's modification time.
+func ReadMarkdown(dir string) (Document, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return Document{}, fmt.Errorf("read markdown: %w", err)
+	}
+
+	doc := Document{Header: NewHeader(0)}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.EqualFold(filepath.Ext(entry.Name()), ".md") {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return Document{}, fmt.Errorf("read markdown: %w", err)
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return Document{}, fmt.Errorf("read markdown: %w", err)
+		}
+		thought, err := ParseMarkdown(string(data), info.ModTime())
+		if err != nil {
+			return Document{}, fmt.Errorf("read markdown: %s: %w", entry.Name(), err)
+		}
+		doc.Thoughts = append(doc.Thoughts, thought)
+	}
+
+	// Exported thoughts keep their order; plain notes, which have no id, follow by age.
+	sort.SliceStable(doc.Thoughts, func(i, j int) bool {
+		left, right := doc.Thoughts[i], doc.Thoughts[j]
+		if (left.ID == 0) != (right.ID == 0) {
+			return right.ID == 0
+		}
+		if left.ID != right.ID {
+			return left.ID < right.ID
+		}
+		return left.CreatedAt.Before(right.CreatedAt)
+	})
+	return doc, nil
+}
+
+var markdownEventLine = regexp.MustCompile(`^- (\S+) (\S+)(?: \(([a-z]*) → ([a-z]*)\))?(?: for (\S+))?$`)
+
+// ParseMarkdown parses one Markdown thought. fallback stands in for missing timestamps.
+func ParseMarkdown(text string, fallback time.Time) (Thought, error) {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	fallback = fallback.UTC()
+	thought := Thought{
+		State:         core.StateCaptured,
+		CreatedAt:     fallback,
+		UpdatedAt:     fallback,
+		EligibilityAt: fallback,
+		Tags:          []string{},
+	}
+
+	body := text
+	fields := map[string]string{}
+	if rest, ok := strings.CutPrefix(text, "---\n"); ok {
+		front, after, found := strings.Cut(rest, "\n---\n")
+		if !found {
+			front, found = strings.CutSuffix(rest, "\n---")
+			after = ""
+		}
+		if !found {
+			return Thought{}, fmt.Errorf("front matter is not closed")
+		}
+		for n, line := range strings.Split(front, "\n") {
+			if strings.TrimSpace(line) == "" || strings.HasPrefix(strings.TrimSpace(line), "#") {
+				continue
+			}
+			key, value, ok := strings.Cut(line, ":")
+			if !ok {
+				return Thought{}, fmt.Errorf("front matter line %d: expected key: value", n+2)
+			}
+			fields[strings.TrimSpace(key)] = unquote(strings.TrimSpace(value))
+		}
+		body = after
+	}
+
+	var err error
+	for key, value := range fields {
+		switch key {
+		case "id":
+			thought.ID, err = strconv.ParseInt(value, 10, 64)
+		case "public_id":
+			thought.PublicID = value
+		case "state":
+			thought.State = core.State(strings.ToLower(value))
+		case "created_at":
+			thought.CreatedAt, err = parseMarkdownTime(value)
+		case "updated_at":
+			thought.UpdatedAt, err = parseMarkdownTime(value)
+		case "eligibility_at":
+			thought.EligibilityAt, err = parseMarkdownTime(value)
+		case "last_tended_at":
+			if value != "" && value != "null" {
+				var t time.Time
+				t, err = parseMarkdownTime(value)
+				thought.LastTendedAt = &t
+			}
+		case "released_at":
+			if value != "" && value != "null" {
+				var t time.Time
+				t, err = parseMarkdownTime(value)
+				thought.ReleasedAt = &t
+			}
+		case "valence":
+			thought.Valence, err = parseMarkdownInt(value)
+		case "energy":
+			thought.Energy, err = parseMarkdownInt(value)
+		case "tend_counter":
+			thought.TendCounter, err = strconv.Atoi(value)
+		case "tags":
+			thought.Tags = parseMarkdownList(value)
+		}
+		if err != nil {
+			return Thought{}, fmt.Errorf("front matter %s: %w", key, err)
+		}
+	}
+	if _, ok := fields["updated_at"]; !ok {
+		thought.UpdatedAt = thought.CreatedAt
+	}
+	if _, ok := fields["eligibility_at"]; !ok {
+		thought.EligibilityAt = thought.CreatedAt
+	}
+
+	content := body
+	history := ""
+	if i := strings.LastIndex(body, "\n"+historyHeading+"\n"); i >= 0 {
+		content, history = body[:i], body[i+len(historyHeading)+2:]
+	} else if rest, ok := strings.CutPrefix(body, historyHeading+"\n"); ok {
+		content, history = "", rest
+	}
+	thought.Content = strings.TrimSpace(content)
+	if thought.Content == "" {
+		return Thought{}, fmt.Errorf("content is empty")
+	}
+
+	thought.Events, err = parseMarkdownHistory(history)
+	if err != nil {
+		return Thought{}, err
+	}
+	if len(thought.Events) == 0 {
+		next := core.StateCaptured
+		thought.Events = []Event{{Kind: "captured", At: thought.CreatedAt, NextState: &next}}
+	}
+	return thought, nil
+}
+
+func parseMarkdownHistory(history string) ([]Event, error) {
+	events := []Event{}
+	for n, line := range strings.Split(history, "\n") {
+		trimmed := strings.TrimRight(line, " ")
+		switch {
+		case strings.TrimSpace(trimmed) == "":
+			continue
+		case strings.HasPrefix(trimmed, "  >"):
+			if len(events) == 0 {
+				return nil, fmt.Errorf("history line %d: note before any event", n+1)
+			}
+			text := strings.TrimPrefix(strings.TrimPrefix(trimmed, "  >"), " ")
+			last := &events[len(events)-1]
+			if last.Note == nil {
+				last.Note = &text
+			} else {
+				joined := *last.Note + "\n" + text
+				last.Note = &joined
+			}
+		default:
+			match := markdownEventLine.FindStringSubmatch(trimmed)
+			if match == nil {
+				return nil, fmt.Errorf("history line %d: expected \"- <time> <kind> (<from> → <to>) [for <duration>]\"", n+1)
+			}
+			at, err := parseMarkdownTime(match[1])
+			if err != nil {
+				return nil, fmt.Errorf("history line %d: %w", n+1, err)
+			}
+			event := Event{
+				Kind:          match[2],
+				At:            at,
+				PreviousState: parseMarkdownState(match[3]),
+				NextState:     parseMarkdownState(match[4]),
+			}
+			if match[5] != "" {
+				settle, err := core.ParseSettleDuration(match[5])
+				if err != nil {
+					return nil, fmt.Errorf("history line %d: %w", n+1, err)
+				}
+				event.SettleSeconds = durationSeconds(&settle)
+			}
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
+
+// markdownFileName is the ref followed by a short slug of the content, e.g. k3f09a1c2d4-learn-rust.md.
+func markdownFileName(thought Thought) string {
+	name := thought.PublicID
+	if name == "" {
+		name = strconv.FormatInt(thought.ID, 10)
+	}
+	var slug strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(thought.Content) {
+		if slug.Len() >= 40 {
+			break
+		}
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			slug.WriteRune(r)
+			dash = false
+			continue
+		}
+		if !dash && slug.Len() > 0 {
+			slug.WriteByte('-')
+			dash = true
+		}
+	}
+	if s := strings.Trim(slug.String(), "-"); s != "" {
+		name += "-" + s
+	}
+	return name + ".md"
+}
+
+func formatMarkdownTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+func parseMarkdownTime(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339Nano, value); err == nil {
+		return t.UTC(), nil
+	}
+	if t, err := time.Parse("2006-01-02", value); err == nil {
+		return t.UTC(), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q", value)
+}
+
+func formatMarkdownInt(v *int) string {
+	if v == nil {
+		return "null"
+	}
+	return strconv.Itoa(*v)
+}
+
+func parseMarkdownInt(value string) (*int, error) {
+	if value == "" || value == "null" || value == "~" {
+		return nil, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return nil, err
+	}
+	return &n, nil
+}
+
+func parseMarkdownList(value string) []string {
+	value = strings.TrimSuffix(strings.TrimPrefix(value, "["), "]")
+	items := []string{}
+	for _, item := range strings.Split(value, ",") {
+		item = unquote(strings.TrimSpace(item))
+		if item != "" {
+			items = append(items, strings.TrimPrefix(item, "#"))
+		}
+	}
+	return items
+}
+
+func markdownState(state *core.State) string {
+	if state == nil {
+		return ""
+	}
+	return string(*state)
+}
+
+func parseMarkdownState(value string) *core.State {
+	if value == "" {
+		return nil
+	}
+	state := core.State(value)
+	return &state
+}
+
+func unquote(value string) string {
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+		return value[1 : len(value)-1]
+	}
+	return value
+}
@@ -0,0 +1,328 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ErrGardenInUse reports a database another connection has open, so it cannot be swapped out.
+var ErrGardenInUse = errors.New("the garden is open elsewhere; close Bloom, the WebUI, and other peony commands first")
+
+// keepAutomaticBackups is how many automatic backups are kept beside a database. Older ones are
+// removed as new ones are written; backups made with peony backup or before a restore are never removed.
+const keepAutomaticBackups = 5
+
+// backupStamp names backups so that sorting by name sorts by time.
+const backupStamp = "20060102T150405.000000000Z"
+
+// BackupDir returns the directory that holds backups of the database at dbPath.
+func BackupDir(dbPath string) string {
+	return filepath.Join(filepath.Dir(dbPath), "backups")
+}
+
+// DefaultBackupPath returns where peony backup writes when no path is given.
+func DefaultBackupPath(dbPath string, now time.Time) string {
+	return filepath.Join(BackupDir(dbPath), "peony-"+now.UTC().Format("20060102-150405")+".db")
+}
+
+// Backup writes a consistent snapshot of the database to path with VACUUM INTO. Other connections
+// may keep reading and writing while it runs. path must not exist yet.
+func (s *Store) Backup(path string) error {
+	if s == nil {
+		return fmt.Errorf("backup: store is nil")
+	}
+	if s.db == nil {
+		return fmt.Errorf("backup: db is nil")
+	}
+	if err := backupTo(s.db, path); err != nil {
+		return fmt.Errorf("backup: %w", err)
+	}
+	return nil
+}
+
+func backupTo(db *sql.DB, path string) error {
+	if path == "" {
+		return fmt.Errorf("empty backup path")
+	}
+	if _, err := os.Lstat(path); err == nil {
+		return fmt.Errorf("%s: %w", path, fs.ErrExist)
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("create backup dir: %w", err)
+	}
+
+	if _, err := db.Exec(`VACUUM INTO ?;`, path); err != nil {
+		_ = os.Remove(path)
+		return fmt.Errorf("vacuum into %s: %w", path, err)
+	}
+	return nil
+}
+
+// automaticBackup snapshots the database before a change that rewrites it, such as a migration or
+// a reindex, and prunes older automatic backups. Databases without a file, such as in-memory ones,
+// are skipped and "" is returned.
+func automaticBackup(db *sql.DB, reason string) (string, error) {
+	dbPath, err := databaseFile(db)
+	if err != nil || dbPath == "" {
+		return "", err
+	}
+
+	dir := BackupDir(dbPath)
+	path := filepath.Join(dir, "auto-"+time.Now().UTC().Format(backupStamp)+"-"+reason+".db")
+	if err := backupTo(db, path); err != nil {
+		return "", err
+	}
+	if err := pruneAutomaticBackups(dir); err != nil {
+		return path, err
+	}
+	return path, nil
+}
+
+// pruneAutomaticBackups keeps the newest keepAutomaticBackups automatic backups in dir.
+func pruneAutomaticBackups(dir string) error {
+	paths, err := filepath.Glob(filepath.Join(dir, "auto-*.db"))
+	if err != nil {
+		return fmt.Errorf("list automatic backups: %w", err)
+	}
+	sort.Strings(paths)
+	for len(paths) > keepAutomaticBackups {
+		if err := os.Remove(paths[0]); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("remove old backup: %w", err)
+		}
+		paths = paths[1:]
+	}
+	return nil
+}
+
+// databaseFile returns the file behind db's main database, or "" when it has none.
+func databaseFile(db *sql.DB) (string, error) {
+	var file string
+	if err := db.QueryRow(`SELECT file FROM pragma_database_list WHERE name = 'main';`).Scan(&file); err != nil {
+		return "", fmt.Errorf("read database file: %w", err)
+	}
+	return file, nil
+}
+
+// RestoreResult describes a backup put back in place by RestoreBackup.
+type RestoreResult struct {
+	Schema   int    // schema version of the restored backup
+	Previous string // where the replaced database was moved, or "" when there was none
+}
+
+// RestoreBackup checks the backup at backupPath and swaps it in for the database at dbPath.
+// The backup must be an intact Peony database no newer than SchemaVersion; an older one is
+// migrated the next time it is opened. The database it replaces is moved into BackupDir, never
+// deleted. It fails with ErrGardenInUse while another connection has dbPath open.
+func RestoreBackup(backupPath, dbPath string) (RestoreResult, error) {
+	var result RestoreResult
+	if backupPath == "" || dbPath == "" {
+		return result, fmt.Errorf("restore backup: empty path")
+	}
+
+	schema, err := checkBackup(backupPath)
+	if err != nil {
+		return result, fmt.Errorf("restore backup: %w", err)
+	}
+	result.Schema = schema
+
+	if backupInfo, err := os.Stat(backupPath); err == nil {
+		if dbInfo, err := os.Stat(dbPath); err == nil && os.SameFile(backupInfo, dbInfo) {
+			return result, fmt.Errorf("restore backup: %s is the database itself", backupPath)
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dbPath), 0o755); err != nil {
+		return result, fmt.Errorf("restore backup: create db dir: %w", err)
+	}
+	staged, err := stageCopy(backupPath, filepath.Dir(dbPath))
+	if err != nil {
+		return result, fmt.Errorf("restore backup: %w", err)
+	}
+	defer func() {
+		_ = os.Remove(staged)
+	}()
+
+	unlock, err := lockGarden(dbPath)
+	if err != nil {
+		return result, fmt.Errorf("restore backup: %w", err)
+	}
+	defer unlock()
+
+	previous, err := moveAside(dbPath)
+	if err != nil {
+		return result, fmt.Errorf("restore backup: %w", err)
+	}
+	if err := os.Rename(staged, dbPath); err != nil {
+		if previous != "" {
+			_ = os.Rename(previous, dbPath)
+		}
+		return result, fmt.Errorf("restore backup: swap in backup: %w", err)
+	}
+	result.Previous = previous
+	return result, nil
+}
+
+// lockGarden takes the database at dbPath for itself until the returned function is called, so
+// the file can be moved without pulling it out from under another connection. In WAL mode even
+// an idle connection keeps the file open; leaving WAL only succeeds without one, so the database
+// is switched to rollback mode first, and the next Open switches it back. A missing database
+// needs no lock.
+func lockGarden(dbPath string) (func(), error) {
+	if _, err := os.Stat(dbPath); errors.Is(err, fs.ErrNotExist) {
+		return func() {}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	db, err := sql.Open("sqlite", "file:"+dbPath+"?mode=rw"+busyTimeoutPragma(0))
+	if err != nil {
+		return nil, fmt.Errorf("sql open: %w", err)
+	}
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		_ = db.Close()
+		return nil, inUse(err)
+	}
+	release := func() {
+		_, _ = conn.ExecContext(ctx, `ROLLBACK;`)
+		_ = conn.Close()
+		_ = db.Close()
+	}
+
+	var mode string
+	if err := conn.QueryRowContext(ctx, `PRAGMA journal_mode;`).Scan(&mode); err != nil {
+		release()
+		return nil, inUse(err)
+	}
+	if strings.EqualFold(mode, "wal") {
+		if err := conn.QueryRowContext(ctx, `PRAGMA journal_mode = DELETE;`).Scan(&mode); err != nil || !strings.EqualFold(mode, "delete") {
+			release()
+			if err == nil {
+				return nil, ErrGardenInUse
+			}
+			return nil, inUse(err)
+		}
+	}
+	if _, err := conn.ExecContext(ctx, `BEGIN EXCLUSIVE;`); err != nil {
+		release()
+		return nil, inUse(err)
+	}
+	return release, nil
+}
+
+// inUse reports a busy database as ErrGardenInUse and passes any other error through.
+func inUse(err error) error {
+	if isBusy(err) {
+		return ErrGardenInUse
+	}
+	return fmt.Errorf("lock database: %w", err)
+}
+
+// checkBackup opens the backup read-only, checks its integrity, and returns its schema version.
+func checkBackup(path string) (int, error) {
+	if _, err := os.Stat(path); err != nil {
+		return 0, err
+	}
+
+	db, err := sql.Open("sqlite", "file:"+path+"?mode=ro&_pragma=query_only(1)")
+	if err != nil {
+		return 0, fmt.Errorf("sql open: %w", err)
+	}
+	defer db.Close()
+
+	var check string
+	if err := db.QueryRow(`PRAGMA quick_check;`).Scan(&check); err != nil {
+		return 0, fmt.Errorf("%s is not a readable database: %w", path, err)
+	}
+	if check != "ok" {
+		return 0, fmt.Errorf("%s is damaged: %s", path, check)
+	}
+
+	var tables int
+	err = db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'schema_migrations';`).Scan(&tables)
+	if err != nil {
+		return 0, fmt.Errorf("read schema: %w", err)
+	}
+	if tables == 0 {
+		return 0, fmt.Errorf("%s is not a Peony database", path)
+	}
+	applied, err := appliedVersions(db)
+	if err != nil {
+		return 0, err
+	}
+	schema := maxVersion(applied)
+	if schema == 0 {
+		return 0, fmt.Errorf("%s is not a Peony database", path)
+	}
+	if schema > SchemaVersion {
+		return schema, fmt.Errorf("%w (backup is at version %d, this peony supports %d)", ErrSchemaTooNew, schema, SchemaVersion)
+	}
+	return schema, nil
+}
+
+// stageCopy copies src into dir under a temporary name and syncs it, so the final swap is one rename.
+func stageCopy(src, dir string) (string, error) {
+	in, err := os.Open(src)
+	if err != nil {
+		return "", err
+	}
+	defer in.Close()
+
+	out, err := os.CreateTemp(dir, ".peony-restore-*.db")
+	if err != nil {
+		return "", fmt.Errorf("stage copy: %w", err)
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		_ = out.Close()
+		_ = os.Remove(out.Name())
+		return "", fmt.Errorf("stage copy: %w", err)
+	}
+	if err := out.Sync(); err != nil {
+		_ = out.Close()
+		_ = os.Remove(out.Name())
+		return "", fmt.Errorf("stage copy: %w", err)
+	}
+	if err := out.Close(); err != nil {
+		_ = os.Remove(out.Name())
+		return "", fmt.Errorf("stage copy: %w", err)
+	}
+	return out.Name(), nil
+}
+
+// moveAside moves the database at dbPath, with its -wal and -shm files, into BackupDir and returns
+// its new path. A missing database returns "".
+func moveAside(dbPath string) (string, error) {
+	if _, err := os.Stat(dbPath); errors.Is(err, fs.ErrNotExist) {
+		return "", nil
+	} else if err != nil {
+		return "", err
+	}
+
+	dir := BackupDir(dbPath)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("create backup dir: %w", err)
+	}
+	previous := filepath.Join(dir, "pre-restore-"+time.Now().UTC().Format(backupStamp)+".db")
+	if err := os.Rename(dbPath, previous); err != nil {
+		return "", fmt.Errorf("move current database aside: %w", err)
+	}
+	for _, suffix := range []string{"-wal", "-shm"} {
+		if err := os.Rename(dbPath+suffix, previous+suffix); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			_ = os.Rename(previous, dbPath)
+			return "", fmt.Errorf("move current database aside: %w", err)
+		}
+	}
+	return previous, nil
+}
@@ -0,0 +1,87 @@
+package storage
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/divijg19/peony/internal/core"
+)
+
+// ErrInvalidCursor reports a cursor that is malformed or belongs to a list in another order.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// EncodeCursor returns the opaque cursor that continues a list in order after thought.
+// It carries the thought's sort key and ID, so a page that follows it starts in the same place
+// even when rows before it have changed state or been removed since.
+func EncodeCursor(order ThoughtOrder, thought core.Thought) string {
+	if order == "" {
+		order = OrderByID
+	}
+	raw := string(order) + "\n" + OrderKey(thought, order) + "\n" + strconv.FormatInt(thought.ID, 10)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// DecodeCursor reads a cursor made by EncodeCursor for a list in order.
+func DecodeCursor(cursor string, order ThoughtOrder) (Position, error) {
+	if order == "" {
+		order = OrderByID
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return Position{}, fmt.Errorf("%w %q", ErrInvalidCursor, cursor)
+	}
+	parts := strings.Split(string(raw), "\n")
+	if len(parts) != 3 {
+		return Position{}, fmt.Errorf("%w %q", ErrInvalidCursor, cursor)
+	}
+	if ThoughtOrder(parts[0]) != order {
+		return Position{}, fmt.Errorf("%w: it continues a list ordered by %s, not %s", ErrInvalidCursor, parts[0], order)
+	}
+	id, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil || id <= 0 {
+		return Position{}, fmt.Errorf("%w %q", ErrInvalidCursor, cursor)
+	}
+	if order != OrderByID {
+		if _, err := time.Parse(time.RFC3339Nano, parts[1]); err != nil {
+			return Position{}, fmt.Errorf("%w %q", ErrInvalidCursor, cursor)
+		}
+	}
+	return Position{Key: parts[1], ID: id}, nil
+}
+
+// PageThoughts returns up to limit thoughts of q that follow cursor, from the start of q when
+// cursor is empty, and the cursor that continues after them. The returned cursor is empty once
+// the list is exhausted. A limit of 0 returns every remaining thought. q's own Limit and After
+// are replaced; its Offset applies only to a page without a cursor.
+func (s *Store) PageThoughts(q ThoughtQuery, limit int, cursor string) ([]core.Thought, string, error) {
+	if limit < 0 {
+		return nil, "", fmt.Errorf("page thoughts: limit must be >= 0")
+	}
+	q.After = nil
+	if cursor != "" {
+		position, err := DecodeCursor(cursor, q.Order)
+		if err != nil {
+			return nil, "", fmt.Errorf("page thoughts: %w", err)
+		}
+		q.After = &position
+		q.Offset = 0
+	}
+	q.Limit = 0
+	if limit > 0 {
+		q.Limit = limit + 1 // one more tells whether another page follows
+	}
+
+	thoughts, err := s.QueryThoughts(q)
+	if err != nil {
+		return nil, "", fmt.Errorf("page thoughts: %w", err)
+	}
+	if limit == 0 || len(thoughts) <= limit {
+		return thoughts, "", nil
+	}
+	thoughts = thoughts[:limit]
+	return thoughts, EncodeCursor(q.Order, thoughts[len(thoughts)-1]), nil
+}
@@ -1,14 +1,101 @@
 package storage
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 
-	_ "modernc.org/sqlite"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
 )
 
+// DefaultJournalMode lets readers such as Bloom and the WebUI keep reading while another
+// process writes.
+const DefaultJournalMode = "wal"
+
+// DefaultBusyTimeout is how long a connection waits on another connection's lock before it
+// reports the database as busy.
+const DefaultBusyTimeout = 5 * time.Second
+
+// JournalModes are the SQLite journal modes Open accepts.
+var JournalModes = []string{"wal", "delete", "truncate", "persist"}
+
+// Options configures the SQLite connections Open and OpenReadOnly make.
+type Options struct {
+	JournalMode string        // one of JournalModes; empty means DefaultJournalMode
+	BusyTimeout time.Duration // 0 reports a lock held elsewhere as busy right away
+}
+
+// Connection holds the options Open and OpenReadOnly apply. The CLI sets it from the config
+// file at startup.
+var Connection = Options{JournalMode: DefaultJournalMode, BusyTimeout: DefaultBusyTimeout}
+
+// check validates o and returns its journal mode in lower case.
+func (o Options) check() (string, error) {
+	mode := strings.ToLower(strings.TrimSpace(o.JournalMode))
+	if mode == "" {
+		mode = DefaultJournalMode
+	}
+	known := false
+	for _, candidate := range JournalModes {
+		known = known || mode == candidate
+	}
+	if !known {
+		return "", fmt.Errorf("unknown journal mode %q (use %s)", o.JournalMode, strings.Join(JournalModes, ", "))
+	}
+	if o.BusyTimeout < 0 {
+		return "", fmt.Errorf("busy timeout must be >= 0")
+	}
+	return mode, nil
+}
+
+// busyTimeoutPragma is the DSN parameter that makes every connection of a pool wait d on locks.
+func busyTimeoutPragma(d time.Duration) string {
+	return "&_pragma=busy_timeout(" + strconv.FormatInt(d.Milliseconds(), 10) + ")"
+}
+
+// setJournalMode switches db's file to mode. The mode is stored in the file, so it is set once
+// here rather than on every connection. Leaving WAL needs the file to itself; while another
+// process has it open the current mode is kept, without waiting, and the change is made by a
+// later open.
+func setJournalMode(db *sql.DB, mode string) error {
+	conn, err := db.Conn(context.Background())
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	var current string
+	if err := conn.QueryRowContext(context.Background(), `PRAGMA journal_mode;`).Scan(&current); err != nil {
+		return fmt.Errorf("read journal mode: %w", err)
+	}
+	if strings.EqualFold(current, mode) {
+		return nil
+	}
+
+	var timeout int64
+	if err := conn.QueryRowContext(context.Background(), `PRAGMA busy_timeout;`).Scan(&timeout); err != nil {
+		return fmt.Errorf("read busy timeout: %w", err)
+	}
+	if _, err := conn.ExecContext(context.Background(), `PRAGMA busy_timeout = 0;`); err != nil {
+		return fmt.Errorf("set busy timeout: %w", err)
+	}
+	defer func() {
+		_, _ = conn.ExecContext(context.Background(), `PRAGMA busy_timeout = `+strconv.FormatInt(timeout, 10)+`;`)
+	}()
+
+	if err := conn.QueryRowContext(context.Background(), `PRAGMA journal_mode = `+mode+`;`).Scan(&current); err != nil && !isBusy(err) {
+		return fmt.Errorf("set journal mode %s: %w", mode, err)
+	}
+	return nil
+}
+
 // DefaultDBPath returns the default filesystem location for Peony's SQLite database.
 func DefaultDBPath() (string, error) {
 	home, err := os.UserHomeDir()
@@ -27,18 +114,29 @@ func ResolveDBPath() (string, error) {
 	return DefaultDBPath()
 }
 
-// Open opens (or creates) a SQLite database at dbPath and applies migrations.
+// Open opens (or creates) a SQLite database at dbPath with Connection and applies migrations.
 func Open(dbPath string) (*sql.DB, error) {
+	return OpenWith(dbPath, Connection)
+}
+
+// OpenWith opens (or creates) a SQLite database at dbPath with opts and applies migrations.
+// Write transactions take the write lock when they begin, so two writers queue on the busy
+// timeout instead of failing halfway through.
+func OpenWith(dbPath string, opts Options) (*sql.DB, error) {
 	if dbPath == "" {
 		return nil, fmt.Errorf("open: empty db path")
 	}
+	mode, err := opts.check()
+	if err != nil {
+		return nil, fmt.Errorf("open: %w", err)
+	}
 
-	err := os.MkdirAll(filepath.Dir(dbPath), 0o755)
+	err = os.MkdirAll(filepath.Dir(dbPath), 0o755)
 	if err != nil {
 		return nil, fmt.Errorf("open: create db dir: %w", err)
 	}
 
-	dsn := "file:" + dbPath + "?mode=rwc&_pragma=foreign_keys(1)"
+	dsn := "file:" + dbPath + "?mode=rwc&_txlock=immediate&_pragma=foreign_keys(1)" + busyTimeoutPragma(opts.BusyTimeout)
 
 	db, err := sql.Open("sqlite", dsn)
 	if err != nil {
@@ -51,6 +149,12 @@ func Open(dbPath string) (*sql.DB, error) {
 		return nil, fmt.Errorf("open: ping: %w", err)
 	}
 
+	err = setJournalMode(db, mode)
+	if err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("open: %w", err)
+	}
+
 	err = Migrate(db)
 	if err != nil {
 		_ = db.Close()
@@ -59,3 +163,93 @@ func Open(dbPath string) (*sql.DB, error) {
 
 	return db, nil
 }
+
+// OpenReadOnly opens an existing SQLite database at dbPath without creating, migrating, or writing to it.
+func OpenReadOnly(dbPath string) (*sql.DB, error) {
+	if dbPath == "" {
+		return nil, fmt.Errorf("open read-only: empty db path")
+	}
+
+	_, err := os.Stat(dbPath)
+	if err != nil {
+		return nil, fmt.Errorf("open read-only: %w", err)
+	}
+
+	dsn := "file:" + dbPath + "?mode=ro&_pragma=foreign_keys(1)&_pragma=query_only(1)" + busyTimeoutPragma(Connection.BusyTimeout)
+
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("open read-only: sql open: %w", err)
+	}
+
+	err = db.Ping()
+	if err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("open read-only: ping: %w", err)
+	}
+
+	var current int
+	err = db.QueryRow(`SELECT COALESCE(MAX(version), 0) FROM schema_migrations;`).Scan(&current)
+	if err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("open read-only: read schema version: %w", err)
+	}
+	if current > SchemaVersion {
+		_ = db.Close()
+		return nil, fmt.Errorf("open read-only: %w (database is at version %d, this peony supports %d)", ErrSchemaTooNew, current, SchemaVersion)
+	}
+	if current < SchemaVersion {
+		_ = db.Close()
+		return nil, fmt.Errorf("open read-only: schema version %d is older than %d; run any peony command to migrate", current, SchemaVersion)
+	}
+
+	return db, nil
+}
+
+// busyRetries is how many more times a write that still finds the database busy after the busy
+// timeout tries again before its error is returned.
+const busyRetries = 4
+
+// busyBackoff is the pause before the first retry of a busy write; it doubles with each retry.
+var busyBackoff = 20 * time.Millisecond
+
+// retryBusy runs write, and runs it again after a growing pause while it fails because another
+// connection holds the database. write must be safe to repeat: a whole statement or the start
+// of a transaction, never part of one.
+func retryBusy[T any](write func() (T, error)) (T, error) {
+	delay := busyBackoff
+	for attempt := 0; ; attempt++ {
+		result, err := write()
+		if err == nil || !isBusy(err) || attempt == busyRetries {
+			return result, err
+		}
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
+
+// isBusy reports whether err is SQLite refusing a lock another connection holds.
+func isBusy(err error) bool {
+	var sqliteErr *sqlite.Error
+	if !errors.As(err, &sqliteErr) {
+		return false
+	}
+	switch sqliteErr.Code() & 0xff {
+	case sqlite3.SQLITE_BUSY, sqlite3.SQLITE_LOCKED:
+		return true
+	}
+	return false
+}
+
+// begin starts a write transaction. The transaction holds the write lock from the start, so
+// once begin returns no other writer can make it fail as busy.
+func (s *Store) begin() (*sql.Tx, error) {
+	return retryBusy(s.db.Begin)
+}
+
+// exec runs one write statement outside a transaction, retrying it while the database is busy.
+func (s *Store) exec(query string, args ...any) (sql.Result, error) {
+	return retryBusy(func() (sql.Result, error) {
+		return s.db.Exec(query, args...)
+	})
+}
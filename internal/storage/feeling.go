@@ -0,0 +1,83 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/divijg19/peony/internal/core"
+)
+
+// SetThoughtFeeling sets a thought's valence and energy to exactly the given values (nil clears one)
+// and appends a "feel" event noting the new feeling. It reports whether anything changed;
+// setting the feeling a thought already has records nothing.
+func (s *Store) SetThoughtFeeling(id int64, valence, energy *int) (bool, error) {
+	if s == nil {
+		return false, fmt.Errorf("set feeling: store is nil")
+	}
+	if s.db == nil {
+		return false, fmt.Errorf("set feeling: db is nil")
+	}
+	if id <= 0 {
+		return false, fmt.Errorf("set feeling: invalid thought ID")
+	}
+	if err := core.ValidateFeeling(valence, energy); err != nil {
+		return false, fmt.Errorf("set feeling: %w", err)
+	}
+
+	tx, err := s.begin()
+	if err != nil {
+		return false, fmt.Errorf("set feeling: begin tx: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	var currentValence, currentEnergy sql.NullInt64
+	err = tx.QueryRow(`SELECT valence, energy FROM thoughts WHERE id = ?`, id).Scan(&currentValence, &currentEnergy)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, fmt.Errorf("set feeling: %w", ErrNotFound)
+		}
+		return false, fmt.Errorf("set feeling: read feeling: %w", err)
+	}
+	if sameNullableInt(currentValence, valence) && sameNullableInt(currentEnergy, energy) {
+		return false, nil
+	}
+
+	now := time.Now().UTC().Format(time.RFC3339Nano)
+	_, err = tx.Exec(
+		`UPDATE thoughts SET valence = ?, energy = ?, updated_at = ? WHERE id = ?`,
+		nullableInt(valence),
+		nullableInt(energy),
+		now,
+		id,
+	)
+	if err != nil {
+		return false, fmt.Errorf("set feeling: update thoughts: %w", err)
+	}
+
+	_, err = tx.Exec(
+		`INSERT INTO events (thought_id, kind, at, previous_state, next_state, note) VALUES (?, ?, ?, NULL, NULL, ?)`,
+		id,
+		"feel",
+		now,
+		core.FormatFeeling(valence, energy),
+	)
+	if err != nil {
+		return false, fmt.Errorf("set feeling: insert event: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("set feeling: commit: %w", err)
+	}
+	return true, nil
+}
+
+func sameNullableInt(current sql.NullInt64, next *int) bool {
+	if !current.Valid || next == nil {
+		return !current.Valid && next == nil
+	}
+	return int(current.Int64) == *next
+}
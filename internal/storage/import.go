@@ -0,0 +1,290 @@
+package storage
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/divijg19/peony/internal/core"
+)
+
+// ImportRecord is one thought and its history to recreate. IDs inside it are
+// the source garden's and are remapped on insert.
+type ImportRecord struct {
+	Thought core.Thought
+	Events  []core.Event
+}
+
+// ImportOptions controls how ImportThoughts treats existing data.
+type ImportOptions struct {
+	// SkipDuplicates skips records whose content hash and created_at match a thought already present.
+	SkipDuplicates bool
+	// DryRun performs the whole import and then rolls it back, so the result reports exactly what would change.
+	DryRun bool
+}
+
+// ImportedThought maps a source thought ID to the ID it was given here.
+type ImportedThought struct {
+	SourceID int64
+	ID       int64
+	PublicID string
+}
+
+// ImportResult summarizes an import.
+type ImportResult struct {
+	Imported []ImportedThought
+	Skipped  []int64
+	Events   int
+	DryRun   bool
+}
+
+// ImportThoughts recreates thoughts, their events, and their tags in one transaction.
+// Each thought gets a fresh local ID; a public ID that is missing, malformed, or already
+// taken is replaced with a new one.
+func (s *Store) ImportThoughts(records []ImportRecord, opts ImportOptions) (ImportResult, error) {
+	result := ImportResult{DryRun: opts.DryRun}
+	if s == nil {
+		return result, fmt.Errorf("import: store is nil")
+	}
+	if s.db == nil {
+		return result, fmt.Errorf("import: db is nil")
+	}
+	for i, record := range records {
+		if err := validateImportRecord(record); err != nil {
+			return result, fmt.Errorf("import: thought %d (source #%d): %w", i+1, record.Thought.ID, err)
+		}
+	}
+
+	tx, err := s.begin()
+	if err != nil {
+		return result, fmt.Errorf("import: begin tx: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	var seen map[string]bool
+	if opts.SkipDuplicates {
+		seen, err = existingThoughtKeys(tx)
+		if err != nil {
+			return result, fmt.Errorf("import: %w", err)
+		}
+	}
+
+	now := time.Now().UTC().Format(time.RFC3339Nano)
+	for _, record := range records {
+		thought := record.Thought
+		if opts.SkipDuplicates {
+			key := duplicateKey(thought.Content, thought.CreatedAt)
+			if seen[key] {
+				result.Skipped = append(result.Skipped, thought.ID)
+				continue
+			}
+			seen[key] = true
+		}
+
+		publicID, err := importPublicID(tx, thought.PublicID)
+		if err != nil {
+			return result, fmt.Errorf("import: source #%d: %w", thought.ID, err)
+		}
+
+		res, err := tx.Exec(
+			`INSERT INTO thoughts (public_id, content, current_state, tend_counter, created_at, updated_at, last_tended_at, eligibility_at, valence, energy, released_at)
+			 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			publicID,
+			thought.Content,
+			string(thought.CurrentState),
+			thought.TendCounter,
+			formatTime(thought.CreatedAt),
+			formatTime(thought.UpdatedAt),
+			nullableTime(thought.LastTendedAt),
+			formatTime(thought.EligibilityAt),
+			nullableInt(thought.Valence),
+			nullableInt(thought.Energy),
+			nullableTime(thought.ReleasedAt),
+		)
+		if err != nil {
+			return result, fmt.Errorf("import: source #%d: insert thought: %w", thought.ID, err)
+		}
+		id, err := res.LastInsertId()
+		if err != nil {
+			return result, fmt.Errorf("import: source #%d: last insert id: %w", thought.ID, err)
+		}
+
+		for _, event := range record.Events {
+			_, err = tx.Exec(
+				`INSERT INTO events (thought_id, kind, at, previous_state, next_state, note, settle_seconds) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+				id,
+				event.Kind,
+				formatTime(event.At),
+				nullableState(event.PreviousState),
+				nullableState(event.NextState),
+				nullableString(event.Note),
+				nullableSeconds(event.SettleFor),
+			)
+			if err != nil {
+				return result, fmt.Errorf("import: source #%d: insert event: %w", thought.ID, err)
+			}
+			result.Events++
+		}
+
+		tags, err := normalizeTags(thought.Tags)
+		if err != nil {
+			return result, fmt.Errorf("import: source #%d: %w", thought.ID, err)
+		}
+		for _, name := range tags {
+			if _, err := attachTag(tx, id, name, now); err != nil {
+				return result, fmt.Errorf("import: source #%d: %w", thought.ID, err)
+			}
+		}
+
+		result.Imported = append(result.Imported, ImportedThought{SourceID: thought.ID, ID: id, PublicID: publicID})
+	}
+
+	if opts.DryRun {
+		return result, nil
+	}
+	if err := tx.Commit(); err != nil {
+		return result, fmt.Errorf("import: commit: %w", err)
+	}
+	return result, nil
+}
+
+func validateImportRecord(record ImportRecord) error {
+	thought := record.Thought
+	if strings.TrimSpace(thought.Content) == "" {
+		return fmt.Errorf("content is empty")
+	}
+	if !isKnownState(thought.CurrentState) {
+		return fmt.Errorf("unknown state %q", thought.CurrentState)
+	}
+	if thought.TendCounter < 0 {
+		return fmt.Errorf("tend counter is negative")
+	}
+	if thought.CreatedAt.IsZero() || thought.UpdatedAt.IsZero() || thought.EligibilityAt.IsZero() {
+		return fmt.Errorf("created, updated, and eligibility times are required")
+	}
+	if err := core.ValidateFeeling(thought.Valence, thought.Energy); err != nil {
+		return err
+	}
+	for _, event := range record.Events {
+		if strings.TrimSpace(event.Kind) == "" {
+			return fmt.Errorf("event kind is empty")
+		}
+		if event.At.IsZero() {
+			return fmt.Errorf("event time is missing")
+		}
+		if event.PreviousState != nil && !isKnownState(*event.PreviousState) {
+			return fmt.Errorf("event has unknown state %q", *event.PreviousState)
+		}
+		if event.NextState != nil && !isKnownState(*event.NextState) {
+			return fmt.Errorf("event has unknown state %q", *event.NextState)
+		}
+	}
+	return nil
+}
+
+func isKnownState(state core.State) bool {
+	parsed, err := core.ParseState(string(state))
+	return err == nil && parsed == state
+}
+
+// existingThoughtKeys returns the duplicate keys of every thought already stored.
+func existingThoughtKeys(tx *sql.Tx) (map[string]bool, error) {
+	rows, err := tx.Query(`SELECT content, created_at FROM thoughts`)
+	if err != nil {
+		return nil, fmt.Errorf("read existing thoughts: %w", err)
+	}
+	defer rows.Close()
+
+	keys := map[string]bool{}
+	for rows.Next() {
+		var content, createdAtStr string
+		if err := rows.Scan(&content, &createdAtStr); err != nil {
+			return nil, fmt.Errorf("scan existing thought: %w", err)
+		}
+		createdAt, err := time.Parse(time.RFC3339Nano, createdAtStr)
+		if err != nil {
+			return nil, fmt.Errorf("parse existing created_at: %w", err)
+		}
+		keys[duplicateKey(content, createdAt)] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("existing thoughts rows: %w", err)
+	}
+	return keys, nil
+}
+
+// duplicateKey identifies a thought by the SHA-256 of its content and its creation instant.
+func duplicateKey(content string, createdAt time.Time) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:]) + "|" + formatTime(createdAt)
+}
+
+// importPublicID keeps a source public ID when it is well formed and free, otherwise mints a new one.
+func importPublicID(tx *sql.Tx, publicID string) (string, error) {
+	publicID = strings.ToLower(strings.TrimSpace(publicID))
+	if core.IsPublicID(publicID) {
+		var existing int64
+		err := tx.QueryRow(`SELECT id FROM thoughts WHERE public_id = ?`, publicID).Scan(&existing)
+		if errors.Is(err, sql.ErrNoRows) {
+			return publicID, nil
+		}
+		if err != nil {
+			return "", fmt.Errorf("check public id: %w", err)
+		}
+	}
+	return newPublicID()
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+func nullableTime(t *time.Time) any {
+	if t == nil {
+		return nil
+	}
+	return formatTime(*t)
+}
+
+func nullableInt(v *int) any {
+	if v == nil {
+		return nil
+	}
+	return *v
+}
+
+func nullableState(state *core.State) any {
+	if state == nil {
+		return nil
+	}
+	return string(*state)
+}
+
+func nullableString(v *string) any {
+	if v == nil {
+		return nil
+	}
+	return *v
+}
+
+func nullableSeconds(d *time.Duration) any {
+	if d == nil {
+		return nil
+	}
+	return int64(*d / time.Second)
+}
+
+// durationFromSeconds reads a settle_seconds column back into a duration.
+func durationFromSeconds(seconds sql.NullInt64) *time.Duration {
+	if !seconds.Valid {
+		return nil
+	}
+	d := time.Duration(seconds.Int64) * time.Second
+	return &d
+}
@@ -0,0 +1,81 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/divijg19/peony/internal/core"
+)
+
+// transitionTx moves a thought into next by action inside tx. The move must be in core.Transitions;
+// its effects decide which columns change beyond current_state and updated_at, and its event kind is
+// appended with the note (blank notes are dropped). settle only matters for moves that reset
+// eligibility, where zero means core.SettleDuration. It returns the state the thought left.
+// op prefixes every error.
+func transitionTx(tx *sql.Tx, op string, id int64, action core.Action, next core.State, note *string, settle time.Duration) (core.State, error) {
+	var prevStateStr string
+	if err := tx.QueryRow(`SELECT current_state FROM thoughts WHERE id = ?`, id).Scan(&prevStateStr); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", fmt.Errorf("%s: %w", op, ErrNotFound)
+		}
+		return "", fmt.Errorf("%s: read current_state: %w", op, err)
+	}
+	prev := core.State(prevStateStr)
+
+	rule, err := core.LookupTransition(action, prev, next)
+	if err != nil {
+		return prev, fmt.Errorf("%s: %w", op, err)
+	}
+
+	nowTime := time.Now().UTC()
+	now := nowTime.Format(time.RFC3339Nano)
+
+	sets := []string{"current_state = ?", "updated_at = ?"}
+	args := []any{string(next), now}
+	var settleValue any
+	if rule.Effects.Has(core.EffectCountTend) {
+		sets = append(sets, "tend_counter = tend_counter + 1", "last_tended_at = ?")
+		args = append(args, now)
+	}
+	if rule.Effects.Has(core.EffectResetEligibility) {
+		settle = core.SettleFor(settle)
+		settleValue = int64(settle / time.Second)
+		sets = append(sets, "eligibility_at = ?")
+		args = append(args, nowTime.Add(settle).Format(time.RFC3339Nano))
+	}
+	if rule.Effects.Has(core.EffectStampReleased) {
+		sets = append(sets, "released_at = ?")
+		args = append(args, now)
+	}
+	if rule.Effects.Has(core.EffectClearReleased) {
+		sets = append(sets, "released_at = NULL")
+	}
+	args = append(args, id)
+
+	if _, err := tx.Exec(`UPDATE thoughts SET `+strings.Join(sets, ", ")+` WHERE id = ?`, args...); err != nil {
+		return prev, fmt.Errorf("%s: update thoughts: %w", op, err)
+	}
+
+	var noteValue any
+	if note != nil && strings.TrimSpace(*note) != "" {
+		noteValue = *note
+	}
+	_, err = tx.Exec(
+		`INSERT INTO events (thought_id, kind, at, previous_state, next_state, note, settle_seconds)
+		 VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		id,
+		rule.Event,
+		now,
+		string(prev),
+		string(next),
+		noteValue,
+		settleValue,
+	)
+	if err != nil {
+		return prev, fmt.Errorf("%s: insert event: %w", op, err)
+	}
+	return prev, nil
+}
@@ -2,45 +2,247 @@ package storage
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
+	"os"
 )
 
+// migration is one numbered schema step. Steps run in order, each in its own transaction,
+// and are recorded in schema_migrations once applied.
+type migration struct {
+	Version int
+	Name    string
+	Up      func(tx *sql.Tx) error
+}
+
+// migrations lists every schema step in version order. Append new steps; never edit or reorder applied ones.
+// Versions start at 2. Every step checks for what it adds before adding it, so applying one again is harmless.
+var migrations = []migration{
+	{Version: 2, Name: "thoughts, events, and app state", Up: migrateBaseSchema},
+	{Version: 3, Name: "stable public ids", Up: migratePublicIDs},
+	{Version: 4, Name: "tags", Up: migrateTags},
+	{Version: 5, Name: "full-text search", Up: migrateSearch},
+	{Version: 6, Name: "per-thought settle durations", Up: migrateSettleDurations},
+	{Version: 7, Name: "soft release", Up: migrateSoftRelease},
+}
+
 // SchemaVersion is the latest schema version supported by the migrator.
-const SchemaVersion = 2
+const SchemaVersion = 7
 
-// Migrate ensures the SQLite schema exists and is upgraded to SchemaVersion.
+// ErrSchemaTooNew reports a database written by a newer Peony than this binary.
+var ErrSchemaTooNew = errors.New("database schema is newer than this peony supports")
+
+// MigrationStatus describes one known migration step and whether a database has applied it.
+type MigrationStatus struct {
+	Version int
+	Name    string
+	Applied bool
+}
+
+// SchemaStatus summarizes a database's schema against the steps this binary knows.
+type SchemaStatus struct {
+	Exists  bool
+	Current int
+	Latest  int
+	Steps   []MigrationStatus
+}
+
+// Pending returns the number of known steps not yet applied.
+func (s SchemaStatus) Pending() int {
+	pending := 0
+	for _, step := range s.Steps {
+		if !step.Applied {
+			pending++
+		}
+	}
+	return pending
+}
+
+// TooNew reports whether the database was written by a newer Peony.
+func (s SchemaStatus) TooNew() bool {
+	return s.Current > s.Latest
+}
+
+// Migrate applies every pending migration step in order.
+// It refuses to touch a database whose schema is newer than SchemaVersion, and takes an
+// automatic backup first when an existing garden is about to change.
 func Migrate(db *sql.DB) error {
 	if db == nil {
 		return fmt.Errorf("migrate: db is nil")
 	}
 
-	// err holds the error returned by each DDL/DML operation during migration.
 	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (version INTEGER PRIMARY KEY);`)
 	if err != nil {
 		return fmt.Errorf("migrate: create schema_migrations: %w", err)
 	}
 
-	// current is the highest schema version recorded in schema_migrations.
-	var current int
-	err = db.QueryRow(`SELECT COALESCE(MAX(version), 0) FROM schema_migrations;`).Scan(&current)
+	applied, err := appliedVersions(db)
 	if err != nil {
-		return fmt.Errorf("migrate: read current version: %w", err)
+		return fmt.Errorf("migrate: %w", err)
+	}
+	if current := maxVersion(applied); current > SchemaVersion {
+		return fmt.Errorf("migrate: %w (database is at version %d, this peony supports %d)", ErrSchemaTooNew, current, SchemaVersion)
 	}
 
-	if current >= SchemaVersion {
-		return nil
+	pending := false
+	for _, step := range migrations {
+		pending = pending || !applied[step.Version]
+	}
+	if pending {
+		existing, err := hasGarden(db)
+		if err != nil {
+			return fmt.Errorf("migrate: %w", err)
+		}
+		if existing {
+			if _, err := automaticBackup(db, "migrate"); err != nil {
+				return fmt.Errorf("migrate: backup before migrating: %w", err)
+			}
+		}
+	}
+
+	for _, step := range migrations {
+		if applied[step.Version] {
+			continue
+		}
+		if err := applyMigration(db, step); err != nil {
+			return err
+		}
 	}
 
-	// transaction groups schema changes so the migration is applied atomically.
+	return nil
+}
+
+// applyMigration runs one step and records its version in the same transaction.
+func applyMigration(db *sql.DB, step migration) error {
 	transaction, err := db.Begin()
 	if err != nil {
-		return fmt.Errorf("migrate: begin transaction: %w", err)
+		return fmt.Errorf("migrate %d: begin transaction: %w", step.Version, err)
 	}
 	defer func() {
 		_ = transaction.Rollback()
 	}()
 
-	_, err = transaction.Exec(`
+	if err := step.Up(transaction); err != nil {
+		return fmt.Errorf("migrate %d (%s): %w", step.Version, step.Name, err)
+	}
+
+	_, err = transaction.Exec(`INSERT INTO schema_migrations(version) VALUES (?);`, step.Version)
+	if err != nil {
+		return fmt.Errorf("migrate %d: record schema version: %w", step.Version, err)
+	}
+
+	err = transaction.Commit()
+	if err != nil {
+		return fmt.Errorf("migrate %d: commit transaction: %w", step.Version, err)
+	}
+	return nil
+}
+
+// ReadSchemaStatus inspects the database at dbPath without creating or migrating it.
+// A missing file reports every step as pending.
+func ReadSchemaStatus(dbPath string) (SchemaStatus, error) {
+	status := SchemaStatus{Latest: SchemaVersion}
+	if dbPath == "" {
+		return status, fmt.Errorf("schema status: empty db path")
+	}
+
+	applied := map[int]bool{}
+	_, err := os.Stat(dbPath)
+	switch {
+	case err == nil:
+		status.Exists = true
+		db, err := sql.Open("sqlite", "file:"+dbPath+"?mode=ro&_pragma=query_only(1)")
+		if err != nil {
+			return status, fmt.Errorf("schema status: sql open: %w", err)
+		}
+		defer db.Close()
+
+		var tables int
+		err = db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'schema_migrations'`).Scan(&tables)
+		if err != nil {
+			return status, fmt.Errorf("schema status: %w", err)
+		}
+		if tables > 0 {
+			applied, err = appliedVersions(db)
+			if err != nil {
+				return status, fmt.Errorf("schema status: %w", err)
+			}
+		}
+	case errors.Is(err, os.ErrNotExist):
+	default:
+		return status, fmt.Errorf("schema status: %w", err)
+	}
+
+	status.Current = maxVersion(applied)
+	for _, step := range migrations {
+		status.Steps = append(status.Steps, MigrationStatus{
+			Version: step.Version,
+			Name:    step.Name,
+			Applied: applied[step.Version],
+		})
+	}
+	return status, nil
+}
+
+// appliedVersions returns the set of versions recorded in schema_migrations.
+func appliedVersions(db *sql.DB) (map[int]bool, error) {
+	rows, err := db.Query(`SELECT version FROM schema_migrations;`)
+	if err != nil {
+		return nil, fmt.Errorf("read applied versions: %w", err)
+	}
+	defer rows.Close()
+
+	applied := map[int]bool{}
+	for rows.Next() {
+		var version int
+		if err := rows.Scan(&version); err != nil {
+			return nil, fmt.Errorf("scan applied version: %w", err)
+		}
+		applied[version] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("applied versions rows: %w", err)
+	}
+	return applied, nil
+}
+
+// hasGarden reports whether the database holds any tables of its own, so a migration would change
+// existing data rather than set up a new file.
+func hasGarden(db *sql.DB) (bool, error) {
+	var tables int
+	err := db.QueryRow(`
+		SELECT COUNT(*) FROM sqlite_master
+		WHERE type = 'table' AND name <> 'schema_migrations' AND name NOT LIKE 'sqlite_%';
+	`).Scan(&tables)
+	if err != nil {
+		return false, fmt.Errorf("read tables: %w", err)
+	}
+	return tables > 0, nil
+}
+
+// hasColumn reports whether table already has column, so a step can skip an ALTER TABLE it has run before.
+func hasColumn(transaction *sql.Tx, table, column string) (bool, error) {
+	var count int
+	err := transaction.QueryRow(`SELECT COUNT(*) FROM pragma_table_info(?) WHERE name = ?`, table, column).Scan(&count)
+	if err != nil {
+		return false, fmt.Errorf("inspect %s columns: %w", table, err)
+	}
+	return count > 0, nil
+}
+
+func maxVersion(applied map[int]bool) int {
+	current := 0
+	for version := range applied {
+		if version > current {
+			current = version
+		}
+	}
+	return current
+}
+
+// migrateBaseSchema creates the thoughts, events, and app_state tables (version 2).
+func migrateBaseSchema(transaction *sql.Tx) error {
+	_, err := transaction.Exec(`
 		CREATE TABLE IF NOT EXISTS thoughts (
 			id INTEGER PRIMARY KEY AUTOINCREMENT,
 			content TEXT NOT NULL,
@@ -55,7 +257,7 @@ func Migrate(db *sql.DB) error {
 		);
 	`)
 	if err != nil {
-		return fmt.Errorf("migrate: create thoughts table: %w", err)
+		return fmt.Errorf("create thoughts table: %w", err)
 	}
 
 	_, err = transaction.Exec(`
@@ -71,7 +273,7 @@ func Migrate(db *sql.DB) error {
 		);
 	`)
 	if err != nil {
-		return fmt.Errorf("migrate: create events table: %w", err)
+		return fmt.Errorf("create events table: %w", err)
 	}
 
 	_, err = transaction.Exec(`
@@ -82,28 +284,167 @@ func Migrate(db *sql.DB) error {
 		);
 	`)
 	if err != nil {
-		return fmt.Errorf("migrate: create app_state table: %w", err)
+		return fmt.Errorf("create app_state table: %w", err)
 	}
 
 	_, err = transaction.Exec(`CREATE INDEX IF NOT EXISTS idx_thoughts_state_eligibility ON thoughts(current_state, eligibility_at);`)
 	if err != nil {
-		return fmt.Errorf("migrate: create idx_thoughts_state_eligibility: %w", err)
+		return fmt.Errorf("create idx_thoughts_state_eligibility: %w", err)
 	}
 
 	_, err = transaction.Exec(`CREATE INDEX IF NOT EXISTS idx_events_thought_id_at ON events(thought_id, at);`)
 	if err != nil {
-		return fmt.Errorf("migrate: create idx_events_thought_id_at: %w", err)
+		return fmt.Errorf("create idx_events_thought_id_at: %w", err)
 	}
 
-	_, err = transaction.Exec(`INSERT INTO schema_migrations(version) VALUES (?);`, SchemaVersion)
+	return nil
+}
+
+// migratePublicIDs adds a stable public_id to every thought (version 3).
+// Existing rows are backfilled with the same shape newPublicID produces: a letter followed by ten hex digits.
+func migratePublicIDs(transaction *sql.Tx) error {
+	existing, err := hasColumn(transaction, "thoughts", "public_id")
+	if err != nil {
+		return err
+	}
+	if !existing {
+		_, err = transaction.Exec(`ALTER TABLE thoughts ADD COLUMN public_id TEXT NULL;`)
+		if err != nil {
+			return fmt.Errorf("add thoughts.public_id: %w", err)
+		}
+	}
+
+	_, err = transaction.Exec(`UPDATE thoughts SET public_id = char(97 + abs(random() % 26)) || lower(hex(randomblob(5))) WHERE public_id IS NULL;`)
 	if err != nil {
-		return fmt.Errorf("migrate: record schema version: %w", err)
+		return fmt.Errorf("backfill thoughts.public_id: %w", err)
 	}
 
-	err = transaction.Commit()
+	_, err = transaction.Exec(`CREATE UNIQUE INDEX IF NOT EXISTS idx_thoughts_public_id ON thoughts(public_id);`)
+	if err != nil {
+		return fmt.Errorf("create idx_thoughts_public_id: %w", err)
+	}
+
+	return nil
+}
+
+// migrateTags adds the tags table and the thought_tags join table (version 4).
+func migrateTags(transaction *sql.Tx) error {
+	_, err := transaction.Exec(`
+		CREATE TABLE IF NOT EXISTS tags (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			name TEXT NOT NULL UNIQUE,
+			created_at TEXT NOT NULL
+		);
+	`)
+	if err != nil {
+		return fmt.Errorf("create tags table: %w", err)
+	}
+
+	_, err = transaction.Exec(`
+		CREATE TABLE IF NOT EXISTS thought_tags (
+			thought_id INTEGER NOT NULL,
+			tag_id INTEGER NOT NULL,
+			created_at TEXT NOT NULL,
+			PRIMARY KEY(thought_id, tag_id),
+			FOREIGN KEY(thought_id) REFERENCES thoughts(id),
+			FOREIGN KEY(tag_id) REFERENCES tags(id)
+		);
+	`)
+	if err != nil {
+		return fmt.Errorf("create thought_tags table: %w", err)
+	}
+
+	_, err = transaction.Exec(`CREATE INDEX IF NOT EXISTS idx_thought_tags_tag_id ON thought_tags(tag_id, thought_id);`)
+	if err != nil {
+		return fmt.Errorf("create idx_thought_tags_tag_id: %w", err)
+	}
+
+	return nil
+}
+
+// migrateSearch adds the thought_search full-text table, its triggers, and fills it from
+// existing thoughts and event notes (version 5). The notes column holds every note left on
+// a thought, oldest first. The SQL is spelled out here rather than shared with
+// rebuildSearchIndex so that this step stays as it shipped.
+func migrateSearch(transaction *sql.Tx) error {
+	_, err := transaction.Exec(`CREATE VIRTUAL TABLE IF NOT EXISTS thought_search USING fts5(content, notes, tokenize = 'unicode61 remove_diacritics 2', prefix = '2 3');`)
+	if err != nil {
+		return fmt.Errorf("create thought_search: %w", err)
+	}
+
+	triggers := []string{
+		`CREATE TRIGGER IF NOT EXISTS thought_search_thought_insert AFTER INSERT ON thoughts BEGIN
+			INSERT INTO thought_search(rowid, content, notes) VALUES (new.id, new.content, '');
+		END;`,
+		`CREATE TRIGGER IF NOT EXISTS thought_search_thought_update AFTER UPDATE OF content ON thoughts BEGIN
+			UPDATE thought_search SET content = new.content WHERE rowid = new.id;
+		END;`,
+		`CREATE TRIGGER IF NOT EXISTS thought_search_thought_delete AFTER DELETE ON thoughts BEGIN
+			DELETE FROM thought_search WHERE rowid = old.id;
+		END;`,
+		`CREATE TRIGGER IF NOT EXISTS thought_search_event_insert AFTER INSERT ON events WHEN new.note IS NOT NULL BEGIN
+			UPDATE thought_search SET notes = (SELECT COALESCE(group_concat(note, char(10)), '') FROM (SELECT note FROM events WHERE thought_id = new.thought_id AND note IS NOT NULL ORDER BY at, id)) WHERE rowid = new.thought_id;
+		END;`,
+		`CREATE TRIGGER IF NOT EXISTS thought_search_event_update AFTER UPDATE OF note ON events BEGIN
+			UPDATE thought_search SET notes = (SELECT COALESCE(group_concat(note, char(10)), '') FROM (SELECT note FROM events WHERE thought_id = new.thought_id AND note IS NOT NULL ORDER BY at, id)) WHERE rowid = new.thought_id;
+		END;`,
+		`CREATE TRIGGER IF NOT EXISTS thought_search_event_delete AFTER DELETE ON events WHEN old.note IS NOT NULL BEGIN
+			UPDATE thought_search SET notes = (SELECT COALESCE(group_concat(note, char(10)), '') FROM (SELECT note FROM events WHERE thought_id = old.thought_id AND note IS NOT NULL ORDER BY at, id)) WHERE rowid = old.thought_id;
+		END;`,
+	}
+	for _, trigger := range triggers {
+		if _, err := transaction.Exec(trigger); err != nil {
+			return fmt.Errorf("create search trigger: %w", err)
+		}
+	}
+
+	_, err = transaction.Exec(`DELETE FROM thought_search;`)
+	if err != nil {
+		return fmt.Errorf("clear thought_search: %w", err)
+	}
+	_, err = transaction.Exec(`INSERT INTO thought_search(rowid, content, notes)
+		SELECT t.id, t.content, (SELECT COALESCE(group_concat(note, char(10)), '') FROM (SELECT note FROM events WHERE thought_id = t.id AND note IS NOT NULL ORDER BY at, id)) FROM thoughts t;`)
+	if err != nil {
+		return fmt.Errorf("fill thought_search: %w", err)
+	}
+	return nil
+}
+
+// migrateSettleDurations records how long each rest was chosen to last (version 6).
+// Existing events keep NULL, which reads as the configured settle duration.
+func migrateSettleDurations(transaction *sql.Tx) error {
+	existing, err := hasColumn(transaction, "events", "settle_seconds")
 	if err != nil {
-		return fmt.Errorf("migrate: commit transaction: %w", err)
+		return err
+	}
+	if existing {
+		return nil
 	}
 
+	_, err = transaction.Exec(`ALTER TABLE events ADD COLUMN settle_seconds INTEGER NULL;`)
+	if err != nil {
+		return fmt.Errorf("add events.settle_seconds: %w", err)
+	}
+	return nil
+}
+
+// migrateSoftRelease adds released_at so a released thought can wait in the trash before it is purged (version 7).
+// Existing rows keep NULL; nothing is backfilled.
+func migrateSoftRelease(transaction *sql.Tx) error {
+	existing, err := hasColumn(transaction, "thoughts", "released_at")
+	if err != nil {
+		return err
+	}
+	if !existing {
+		_, err = transaction.Exec(`ALTER TABLE thoughts ADD COLUMN released_at TEXT NULL;`)
+		if err != nil {
+			return fmt.Errorf("add thoughts.released_at: %w", err)
+		}
+	}
+
+	_, err = transaction.Exec(`CREATE INDEX IF NOT EXISTS idx_thoughts_released_at ON thoughts(released_at);`)
+	if err != nil {
+		return fmt.Errorf("create idx_thoughts_released_at: %w", err)
+	}
 	return nil
 }
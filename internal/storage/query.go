@@ -0,0 +1,502 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/divijg19/peony/internal/core"
+)
+
+// ThoughtOrder is the order QueryThoughts returns thoughts in. Every order breaks ties by ID.
+type ThoughtOrder string
+
+const (
+	OrderByID          ThoughtOrder = "id"
+	OrderByCreated     ThoughtOrder = "created"
+	OrderByUpdated     ThoughtOrder = "updated"
+	OrderByEligibility ThoughtOrder = "eligibility"
+	// OrderByReleased sorts by release time; thoughts released without a stamp use their last update.
+	OrderByReleased ThoughtOrder = "released"
+)
+
+// orderKeys is the column each order sorts by.
+var orderKeys = map[ThoughtOrder]string{
+	OrderByID:          "id",
+	OrderByCreated:     "created_at",
+	OrderByUpdated:     "updated_at",
+	OrderByEligibility: "eligibility_at",
+	OrderByReleased:    "COALESCE(released_at, updated_at)",
+}
+
+// TimeRange bounds a timestamp. Both ends are inclusive, and a zero end leaves that side open.
+type TimeRange struct {
+	From time.Time
+	To   time.Time
+}
+
+// Position is a place in a QueryThoughts order: the sort key and ID of the last thought already seen.
+// Key is the stored text of the order's column and is ignored when ordering by ID.
+type Position struct {
+	Key string
+	ID  int64
+}
+
+// ThoughtQuery selects thoughts for QueryThoughts. Every field that is set must match; the zero
+// query returns every thought, the trash included, ordered by ID.
+type ThoughtQuery struct {
+	IDs      []int64      // only these thoughts
+	States   []core.State // only thoughts in one of these states
+	Exclude  []core.State // no thoughts in any of these states
+	Tags     []string     // only thoughts carrying every one of these tags
+	Text     string       // only thoughts whose content or notes match, in peony search's syntax
+	Created  TimeRange
+	Updated  TimeRange
+	Eligible TimeRange
+	MinTends int          // only thoughts tended at least this many times
+	Order    ThoughtOrder // OrderByID when empty
+	After    *Position    // start after this position in Order, instead of at Offset
+	Limit    int          // 0 returns every match
+	Offset   int
+}
+
+// GardenQuery selects every thought outside the trash, by updated time: peony view's list.
+func GardenQuery() ThoughtQuery {
+	return ThoughtQuery{Exclude: []core.State{core.StateReleased}, Order: OrderByUpdated}
+}
+
+// BloomQuery selects the thoughts Bloom shows, leaving out archived and released ones, by updated time.
+func BloomQuery() ThoughtQuery {
+	return ThoughtQuery{Exclude: []core.State{core.StateArchived, core.StateReleased}, Order: OrderByUpdated}
+}
+
+// TendQuery selects the thoughts ready to tend at now, the longest eligible first.
+func TendQuery(now time.Time) ThoughtQuery {
+	return ThoughtQuery{
+		States:   []core.State{core.StateCaptured, core.StateResting},
+		Eligible: TimeRange{To: now},
+		Order:    OrderByEligibility,
+	}
+}
+
+// StateQuery selects the thoughts in state, by ID.
+func StateQuery(state core.State) ThoughtQuery {
+	return ThoughtQuery{States: []core.State{state}, Order: OrderByID}
+}
+
+// TagQuery selects the thoughts carrying tag, by ID.
+func TagQuery(tag string) ThoughtQuery {
+	return ThoughtQuery{Tags: []string{tag}, Order: OrderByID}
+}
+
+// ThoughtWithEvents is a thought with its tags and its full history, oldest event first.
+type ThoughtWithEvents struct {
+	Thought core.Thought
+	Events  []core.Event
+}
+
+// rowScanner is satisfied by *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// thoughtColumns is every thoughts column scanThought reads, in order.
+const thoughtColumns = `id, public_id, content, current_state, tend_counter, created_at, updated_at, last_tended_at, eligibility_at, valence, energy, released_at`
+
+// eventColumns is every events column scanEvent reads, in order.
+const eventColumns = `id, thought_id, kind, at, previous_state, next_state, note, settle_seconds`
+
+// QueryThoughts returns the thoughts q selects, with their tags.
+func (s *Store) QueryThoughts(q ThoughtQuery) ([]core.Thought, error) {
+	items, err := s.queryThoughts("query thoughts", q, false)
+	if err != nil {
+		return nil, err
+	}
+	thoughts := make([]core.Thought, 0, len(items))
+	for _, item := range items {
+		thoughts = append(thoughts, item.Thought)
+	}
+	return thoughts, nil
+}
+
+// QueryThoughtsWithEvents returns the thoughts q selects with their tags and events.
+func (s *Store) QueryThoughtsWithEvents(q ThoughtQuery) ([]ThoughtWithEvents, error) {
+	return s.queryThoughts("query thoughts", q, true)
+}
+
+// ListThoughtsWithEvents returns every thought outside the excluded states with its tags and
+// events, ordered by updated time and ID.
+func (s *Store) ListThoughtsWithEvents(exclude ...core.State) ([]ThoughtWithEvents, error) {
+	q := GardenQuery()
+	q.Exclude = exclude
+	return s.queryThoughts("list thoughts with events", q, true)
+}
+
+// queryThoughts reads thoughts, tags, and, when asked, events with one set-based query each inside
+// a single read transaction, so the cost does not grow by a query per thought and every read sees
+// the same garden. op prefixes errors.
+func (s *Store) queryThoughts(op string, q ThoughtQuery, withEvents bool) ([]ThoughtWithEvents, error) {
+	if s == nil {
+		return nil, fmt.Errorf("%s: store is nil", op)
+	}
+	if s.db == nil {
+		return nil, fmt.Errorf("%s: db is nil", op)
+	}
+
+	built, err := q.build()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	tx, err := s.db.BeginTx(context.Background(), &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return nil, fmt.Errorf("%s: begin tx: %w", op, err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	items, index, err := selectThoughts(tx, q, built)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if len(items) == 0 {
+		return items, nil
+	}
+
+	if err := attachTags(tx, items, index, built); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if withEvents {
+		if err := attachEvents(tx, items, index, built); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+	return items, nil
+}
+
+// thoughtSelect is a built ThoughtQuery: it selects any columns of the matching thoughts, in order
+// and paged. The thoughts, tags, and events reads all filter through it.
+type thoughtSelect struct {
+	where  string
+	suffix string
+	args   []any
+}
+
+// columns returns the statement selecting columns of the matching thoughts.
+func (t thoughtSelect) columns(columns string) string {
+	return "SELECT " + columns + " FROM thoughts" + t.where + t.suffix
+}
+
+// build validates q and turns it into a thoughtSelect.
+func (q ThoughtQuery) build() (thoughtSelect, error) {
+	if q.Limit < 0 {
+		return thoughtSelect{}, fmt.Errorf("limit must be >= 0")
+	}
+	if q.Offset < 0 {
+		return thoughtSelect{}, fmt.Errorf("offset must be >= 0")
+	}
+	if q.After != nil && q.Offset > 0 {
+		return thoughtSelect{}, fmt.Errorf("use a position or an offset, not both")
+	}
+	order := q.Order
+	if order == "" {
+		order = OrderByID
+	}
+	key, ok := orderKeys[order]
+	if !ok {
+		return thoughtSelect{}, fmt.Errorf("unknown order %q", order)
+	}
+
+	var where []string
+	var args []any
+	in := func(column string, values []any) {
+		where = append(where, column+" IN ("+placeholders(len(values))+")")
+		args = append(args, values...)
+	}
+
+	if len(q.IDs) > 0 {
+		ids := make([]any, 0, len(q.IDs))
+		for _, id := range q.IDs {
+			ids = append(ids, id)
+		}
+		in("id", ids)
+	}
+	if len(q.States) > 0 {
+		in("current_state", stateArgs(q.States))
+	}
+	if len(q.Exclude) > 0 {
+		where = append(where, "current_state NOT IN ("+placeholders(len(q.Exclude))+")")
+		args = append(args, stateArgs(q.Exclude)...)
+	}
+	for _, tag := range q.Tags {
+		name, err := core.NormalizeTag(tag)
+		if err != nil {
+			return thoughtSelect{}, err
+		}
+		where = append(where, `id IN (SELECT tt.thought_id FROM thought_tags tt JOIN tags t ON t.id = tt.tag_id WHERE t.name = ?)`)
+		args = append(args, name)
+	}
+	if strings.TrimSpace(q.Text) != "" {
+		match := MatchQuery(q.Text, false)
+		if match == "" {
+			return thoughtSelect{}, fmt.Errorf("%w: nothing to search for", ErrInvalidSearch)
+		}
+		where = append(where, `id IN (SELECT rowid FROM thought_search WHERE thought_search MATCH ?)`)
+		args = append(args, match)
+	}
+	for _, bound := range []struct {
+		column string
+		span   TimeRange
+	}{{"created_at", q.Created}, {"updated_at", q.Updated}, {"eligibility_at", q.Eligible}} {
+		if !bound.span.From.IsZero() {
+			where = append(where, bound.column+" >= ?")
+			args = append(args, formatTime(bound.span.From))
+		}
+		if !bound.span.To.IsZero() {
+			where = append(where, bound.column+" <= ?")
+			args = append(args, formatTime(bound.span.To))
+		}
+	}
+	if q.MinTends > 0 {
+		where = append(where, "tend_counter >= ?")
+		args = append(args, q.MinTends)
+	}
+	if q.After != nil {
+		if order == OrderByID {
+			where = append(where, "id > ?")
+			args = append(args, q.After.ID)
+		} else {
+			where = append(where, "("+key+" > ? OR ("+key+" = ? AND id > ?))")
+			args = append(args, q.After.Key, q.After.Key, q.After.ID)
+		}
+	}
+
+	var built thoughtSelect
+	if len(where) > 0 {
+		built.where = " WHERE " + strings.Join(where, " AND ")
+	}
+	built.suffix = " ORDER BY " + key + " ASC"
+	if order != OrderByID {
+		built.suffix += ", id ASC"
+	}
+	if q.Limit > 0 || q.Offset > 0 {
+		limit := q.Limit
+		if limit == 0 {
+			limit = -1
+		}
+		built.suffix += " LIMIT ? OFFSET ?"
+		args = append(args, limit, q.Offset)
+	}
+	built.args = args
+	return built, nil
+}
+
+// OrderKey returns the stored text thought sorts by in order, for building a Position.
+func OrderKey(thought core.Thought, order ThoughtOrder) string {
+	switch order {
+	case OrderByCreated:
+		return formatTime(thought.CreatedAt)
+	case OrderByUpdated:
+		return formatTime(thought.UpdatedAt)
+	case OrderByEligibility:
+		return formatTime(thought.EligibilityAt)
+	case OrderByReleased:
+		if thought.ReleasedAt != nil {
+			return formatTime(*thought.ReleasedAt)
+		}
+		return formatTime(thought.UpdatedAt)
+	default:
+		return strconv.FormatInt(thought.ID, 10)
+	}
+}
+
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
+
+func stateArgs(states []core.State) []any {
+	args := make([]any, 0, len(states))
+	for _, state := range states {
+		args = append(args, string(state))
+	}
+	return args
+}
+
+// selectThoughts reads the matching thoughts in order and indexes their positions by ID.
+func selectThoughts(tx *sql.Tx, q ThoughtQuery, built thoughtSelect) ([]ThoughtWithEvents, map[int64]int, error) {
+	rows, err := tx.Query(built.columns(thoughtColumns), built.args...)
+	if err != nil {
+		if q.Text != "" {
+			return nil, nil, fmt.Errorf("%w: %v", ErrInvalidSearch, err)
+		}
+		return nil, nil, fmt.Errorf("query thoughts: %w", err)
+	}
+	defer rows.Close()
+
+	items := make([]ThoughtWithEvents, 0)
+	index := map[int64]int{}
+	for rows.Next() {
+		thought, err := scanThought(rows)
+		if err != nil {
+			return nil, nil, err
+		}
+		thought.Tags = make([]string, 0)
+		index[thought.ID] = len(items)
+		items = append(items, ThoughtWithEvents{Thought: thought, Events: make([]core.Event, 0)})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("thought rows: %w", err)
+	}
+	return items, index, nil
+}
+
+// attachTags fills in the tags of every matching thought, each thought's tags ordered by name.
+func attachTags(tx *sql.Tx, items []ThoughtWithEvents, index map[int64]int, built thoughtSelect) error {
+	rows, err := tx.Query(
+		`SELECT tt.thought_id, t.name
+		 FROM thought_tags tt
+		 JOIN tags t ON t.id = tt.tag_id
+		 WHERE tt.thought_id IN (`+built.columns("id")+`)
+		 ORDER BY tt.thought_id ASC, t.name ASC`,
+		built.args...,
+	)
+	if err != nil {
+		return fmt.Errorf("query tags: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var thoughtID int64
+		var name string
+		if err := rows.Scan(&thoughtID, &name); err != nil {
+			return fmt.Errorf("scan tag: %w", err)
+		}
+		if i, ok := index[thoughtID]; ok {
+			items[i].Thought.Tags = append(items[i].Thought.Tags, name)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("tag rows: %w", err)
+	}
+	return nil
+}
+
+// attachEvents fills in the history of every matching thought, oldest event first.
+func attachEvents(tx *sql.Tx, items []ThoughtWithEvents, index map[int64]int, built thoughtSelect) error {
+	rows, err := tx.Query(
+		`SELECT `+eventColumns+`
+		 FROM events
+		 WHERE thought_id IN (`+built.columns("id")+`)
+		 ORDER BY thought_id ASC, at ASC, id ASC`,
+		built.args...,
+	)
+	if err != nil {
+		return fmt.Errorf("query events: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		event, err := scanEvent(rows)
+		if err != nil {
+			return err
+		}
+		if i, ok := index[event.ThoughtID]; ok {
+			items[i].Events = append(items[i].Events, event)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("event rows: %w", err)
+	}
+	return nil
+}
+
+// scanThought reads one row of thoughtColumns. Tags are left for the caller.
+func scanThought(row rowScanner) (core.Thought, error) {
+	var thought core.Thought
+	var publicID sql.NullString
+	var stateStr string
+	var createdAtStr, updatedAtStr, eligibilityAtStr string
+	var lastTendedAtStr, releasedAtStr sql.NullString
+	var valence, energy sql.NullInt64
+
+	err := row.Scan(&thought.ID, &publicID, &thought.Content, &stateStr, &thought.TendCounter, &createdAtStr, &updatedAtStr, &lastTendedAtStr, &eligibilityAtStr, &valence, &energy, &releasedAtStr)
+	if err != nil {
+		return core.Thought{}, fmt.Errorf("scan thought: %w", err)
+	}
+
+	thought.PublicID = publicID.String
+	thought.CurrentState = core.State(stateStr)
+
+	if thought.CreatedAt, err = time.Parse(time.RFC3339Nano, createdAtStr); err != nil {
+		return core.Thought{}, fmt.Errorf("parse created_at: %w", err)
+	}
+	if thought.UpdatedAt, err = time.Parse(time.RFC3339Nano, updatedAtStr); err != nil {
+		return core.Thought{}, fmt.Errorf("parse updated_at: %w", err)
+	}
+	if thought.EligibilityAt, err = time.Parse(time.RFC3339Nano, eligibilityAtStr); err != nil {
+		return core.Thought{}, fmt.Errorf("parse eligibility_at: %w", err)
+	}
+	if thought.LastTendedAt, err = parseNullableTime(lastTendedAtStr); err != nil {
+		return core.Thought{}, fmt.Errorf("parse last_tended_at: %w", err)
+	}
+	if thought.ReleasedAt, err = parseNullableTime(releasedAtStr); err != nil {
+		return core.Thought{}, fmt.Errorf("parse released_at: %w", err)
+	}
+
+	if valence.Valid {
+		v := int(valence.Int64)
+		thought.Valence = &v
+	}
+	if energy.Valid {
+		e := int(energy.Int64)
+		thought.Energy = &e
+	}
+	return thought, nil
+}
+
+// scanEvent reads one row of eventColumns.
+func scanEvent(row rowScanner) (core.Event, error) {
+	var event core.Event
+	var atStr string
+	var previousStateStr, nextStateStr, noteStr sql.NullString
+	var settleSeconds sql.NullInt64
+
+	err := row.Scan(&event.ID, &event.ThoughtID, &event.Kind, &atStr, &previousStateStr, &nextStateStr, &noteStr, &settleSeconds)
+	if err != nil {
+		return core.Event{}, fmt.Errorf("scan event: %w", err)
+	}
+
+	if event.At, err = time.Parse(time.RFC3339Nano, atStr); err != nil {
+		return core.Event{}, fmt.Errorf("parse event at: %w", err)
+	}
+	if previousStateStr.Valid {
+		ps := core.State(previousStateStr.String)
+		event.PreviousState = &ps
+	}
+	if nextStateStr.Valid {
+		ns := core.State(nextStateStr.String)
+		event.NextState = &ns
+	}
+	if noteStr.Valid {
+		n := noteStr.String
+		event.Note = &n
+	}
+	event.SettleFor = durationFromSeconds(settleSeconds)
+	return event, nil
+}
+
+// parseNullableTime reads an optional RFC 3339 column.
+func parseNullableTime(value sql.NullString) (*time.Time, error) {
+	if !value.Valid {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, value.String)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
@@ -0,0 +1,308 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/divijg19/peony/internal/core"
+)
+
+// ErrInvalidSearch reports a search query that SQLite's full-text engine rejected.
+var ErrInvalidSearch = errors.New("invalid search query")
+
+// SearchHit is one ranked full-text match with a highlighted snippet.
+// Matched terms in Snippet are wrapped in [brackets]. The snippet comes from the content
+// unless only a note matched, in which case FromNote is set.
+type SearchHit struct {
+	ID        int64
+	PublicID  string
+	Content   string
+	State     core.State
+	UpdatedAt time.Time
+	Snippet   string
+	FromNote  bool
+	Rank      float64
+}
+
+// searchIndexTriggers keep thought_search in step with thoughts and event notes.
+// The notes column holds every note left on a thought, oldest first.
+var searchIndexTriggers = []string{
+	`CREATE TRIGGER thought_search_thought_insert AFTER INSERT ON thoughts BEGIN
+		INSERT INTO thought_search(rowid, content, notes) VALUES (new.id, new.content, '');
+	END;`,
+	`CREATE TRIGGER thought_search_thought_update AFTER UPDATE OF content ON thoughts BEGIN
+		UPDATE thought_search SET content = new.content WHERE rowid = new.id;
+	END;`,
+	`CREATE TRIGGER thought_search_thought_delete AFTER DELETE ON thoughts BEGIN
+		DELETE FROM thought_search WHERE rowid = old.id;
+	END;`,
+	`CREATE TRIGGER thought_search_event_insert AFTER INSERT ON events WHEN new.note IS NOT NULL BEGIN
+		UPDATE thought_search SET notes = (` + searchNotesSQL("new.thought_id") + `) WHERE rowid = new.thought_id;
+	END;`,
+	`CREATE TRIGGER thought_search_event_update AFTER UPDATE OF note ON events BEGIN
+		UPDATE thought_search SET notes = (` + searchNotesSQL("new.thought_id") + `) WHERE rowid = new.thought_id;
+	END;`,
+	`CREATE TRIGGER thought_search_event_delete AFTER DELETE ON events WHEN old.note IS NOT NULL BEGIN
+		UPDATE thought_search SET notes = (` + searchNotesSQL("old.thought_id") + `) WHERE rowid = old.thought_id;
+	END;`,
+}
+
+func searchNotesSQL(thoughtID string) string {
+	return `SELECT COALESCE(group_concat(note, char(10)), '') FROM (SELECT note FROM events WHERE thought_id = ` + thoughtID + ` AND note IS NOT NULL ORDER BY at, id)`
+}
+
+// rebuildSearchIndex drops and recreates the full-text table and its triggers, then fills it
+// from the current thoughts and events. Reindexing calls it because rebuilding thoughts and
+// events drops the triggers attached to them. Migrations keep their own copy of the SQL.
+func rebuildSearchIndex(tx *sql.Tx) error {
+	for _, name := range []string{"thought_search_thought_insert", "thought_search_thought_update", "thought_search_thought_delete", "thought_search_event_insert", "thought_search_event_update", "thought_search_event_delete"} {
+		if _, err := tx.Exec(`DROP TRIGGER IF EXISTS ` + name + `;`); err != nil {
+			return fmt.Errorf("drop trigger %s: %w", name, err)
+		}
+	}
+	if _, err := tx.Exec(`DROP TABLE IF EXISTS thought_search;`); err != nil {
+		return fmt.Errorf("drop thought_search: %w", err)
+	}
+
+	_, err := tx.Exec(`CREATE VIRTUAL TABLE thought_search USING fts5(content, notes, tokenize = 'unicode61 remove_diacritics 2', prefix = '2 3');`)
+	if err != nil {
+		return fmt.Errorf("create thought_search: %w", err)
+	}
+	for _, trigger := range searchIndexTriggers {
+		if _, err := tx.Exec(trigger); err != nil {
+			return fmt.Errorf("create search trigger: %w", err)
+		}
+	}
+
+	_, err = tx.Exec(`INSERT INTO thought_search(rowid, content, notes) SELECT t.id, t.content, (` + searchNotesSQL("t.id") + `) FROM thoughts t;`)
+	if err != nil {
+		return fmt.Errorf("fill thought_search: %w", err)
+	}
+	return nil
+}
+
+// SearchThoughts returns thoughts whose content or notes match query, best match first.
+// query uses the syntax described at MatchQuery; bare words match whole words. Released
+// thoughts in the trash are left out, as they are from every other list.
+func (s *Store) SearchThoughts(query string, limit int) ([]SearchHit, error) {
+	if s == nil {
+		return nil, fmt.Errorf("search: store is nil")
+	}
+	if s.db == nil {
+		return nil, fmt.Errorf("search: db is nil")
+	}
+	if limit <= 0 {
+		return nil, fmt.Errorf("search: limit must be > 0")
+	}
+
+	match := MatchQuery(query, false)
+	if match == "" {
+		return nil, fmt.Errorf("search: %w: nothing to search for", ErrInvalidSearch)
+	}
+
+	// Snippets are marked with control characters first so that brackets already in the
+	// text cannot be mistaken for a match.
+	sqlSearch := `SELECT t.id, t.public_id, t.content, t.current_state, t.updated_at,
+	                     snippet(thought_search, 0, char(2), char(3), '…', 12),
+	                     snippet(thought_search, 1, char(2), char(3), '…', 12),
+	                     bm25(thought_search, 1.0, 0.5) AS score
+	              FROM thought_search
+	              JOIN thoughts t ON t.id = thought_search.rowid
+	              WHERE thought_search MATCH ?
+	                AND t.current_state <> ?
+	              ORDER BY score ASC, t.id ASC
+	              LIMIT ?`
+
+	rows, err := s.db.Query(sqlSearch, match, string(core.StateReleased), limit)
+	if err != nil {
+		return nil, fmt.Errorf("search: %w: %v", ErrInvalidSearch, err)
+	}
+	defer rows.Close()
+
+	hits := make([]SearchHit, 0, limit)
+	for rows.Next() {
+		var hit SearchHit
+		var publicID sql.NullString
+		var stateStr string
+		var updatedAtStr string
+		var contentSnippet, noteSnippet string
+
+		if err := rows.Scan(&hit.ID, &publicID, &hit.Content, &stateStr, &updatedAtStr, &contentSnippet, &noteSnippet, &hit.Rank); err != nil {
+			return nil, fmt.Errorf("search: scan: %w", err)
+		}
+
+		hit.Snippet = contentSnippet
+		if !strings.ContainsRune(contentSnippet, '\x02') && strings.ContainsRune(noteSnippet, '\x02') {
+			hit.Snippet = noteSnippet
+			hit.FromNote = true
+		}
+		hit.Snippet = strings.NewReplacer("\x02", "[", "\x03", "]").Replace(hit.Snippet)
+
+		hit.PublicID = publicID.String
+		hit.State = core.State(stateStr)
+		hit.UpdatedAt, err = time.Parse(time.RFC3339Nano, updatedAtStr)
+		if err != nil {
+			return nil, fmt.Errorf("search: parse updated_at: %w", err)
+		}
+
+		hits = append(hits, hit)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("search: rows: %w", err)
+	}
+
+	return hits, nil
+}
+
+// SearchThoughtIDs returns the IDs of every thought whose content or notes match query.
+// Bare words match as prefixes, which suits search-as-you-type.
+func (s *Store) SearchThoughtIDs(query string) (map[int64]bool, error) {
+	if s == nil {
+		return nil, fmt.Errorf("search ids: store is nil")
+	}
+	if s.db == nil {
+		return nil, fmt.Errorf("search ids: db is nil")
+	}
+
+	ids := map[int64]bool{}
+	match := MatchQuery(query, true)
+	if match == "" {
+		return ids, nil
+	}
+
+	rows, err := s.db.Query(`SELECT rowid FROM thought_search WHERE thought_search MATCH ?`, match)
+	if err != nil {
+		return nil, fmt.Errorf("search ids: %w: %v", ErrInvalidSearch, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("search ids: scan: %w", err)
+		}
+		ids[id] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("search ids: rows: %w", err)
+	}
+	return ids, nil
+}
+
+// MatchQuery turns a user search into an FTS5 MATCH expression.
+//
+// It understands "quoted phrases", prefix* terms, AND, OR, NOT, and parentheses; adjacent
+// terms must all match. Every term is quoted, so punctuation never reaches the FTS5 parser,
+// and dangling operators or parentheses are dropped rather than reported. With prefixTerms
+// set, bare words also match as prefixes. It returns "" when nothing searchable remains.
+func MatchQuery(query string, prefixTerms bool) string {
+	var out []string
+	lastOperand := false
+	pendingOp := ""
+	depth := 0
+
+	joinOperand := func() {
+		if lastOperand {
+			op := pendingOp
+			if op == "" {
+				op = "AND"
+			}
+			out = append(out, op)
+		}
+		pendingOp = ""
+	}
+	closeGroup := func() {
+		if !lastOperand {
+			// Empty group: drop "(" and the operator that led into it.
+			out = out[:len(out)-1]
+			if n := len(out); n > 0 && isMatchOperator(out[n-1]) {
+				out = out[:n-1]
+			}
+			lastOperand = len(out) > 0 && out[len(out)-1] != "("
+		} else {
+			out = append(out, ")")
+		}
+		pendingOp = ""
+		depth--
+	}
+
+	runes := []rune(query)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			joinOperand()
+			out = append(out, "(")
+			lastOperand = false
+			depth++
+			i++
+		case r == ')':
+			if depth > 0 {
+				closeGroup()
+			}
+			i++
+		case r == '"':
+			end := i + 1
+			for end < len(runes) && runes[end] != '"' {
+				end++
+			}
+			phrase := string(runes[i+1 : min(end, len(runes))])
+			i = end + 1
+			prefix := i < len(runes) && runes[i] == '*'
+			if prefix {
+				i++
+			}
+			if term := matchTerm(phrase, prefix); term != "" {
+				joinOperand()
+				out = append(out, term)
+				lastOperand = true
+			}
+		default:
+			end := i
+			for end < len(runes) && !unicode.IsSpace(runes[end]) && runes[end] != '(' && runes[end] != ')' && runes[end] != '"' {
+				end++
+			}
+			word := string(runes[i:end])
+			i = end
+			if word == "AND" || word == "OR" || word == "NOT" {
+				if lastOperand {
+					pendingOp = word
+				}
+				continue
+			}
+			prefix := prefixTerms || strings.HasSuffix(word, "*")
+			if term := matchTerm(strings.TrimRight(word, "*"), prefix); term != "" {
+				joinOperand()
+				out = append(out, term)
+				lastOperand = true
+			}
+		}
+	}
+	for depth > 0 {
+		closeGroup()
+	}
+	return strings.Join(out, " ")
+}
+
+// matchTerm quotes text as an FTS5 string, or returns "" when it holds no letters or digits.
+func matchTerm(text string, prefix bool) string {
+	if strings.IndexFunc(text, func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) }) < 0 {
+		return ""
+	}
+	term := `"` + strings.ReplaceAll(text, `"`, `""`) + `"`
+	if prefix {
+		term += "*"
+	}
+	return term
+}
+
+func isMatchOperator(token string) bool {
+	return token == "AND" || token == "OR" || token == "NOT"
+}
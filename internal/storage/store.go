@@ -1,7 +1,9 @@
 package storage
 
 import (
+	"crypto/rand"
 	"database/sql"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"strconv"
@@ -11,6 +13,9 @@ import (
 	"github.com/divijg19/peony/internal/core"
 )
 
+// ErrNotFound reports a thought ID or ref that matches nothing.
+var ErrNotFound = errors.New("not found")
+
 // Store provides SQLite-backed persistence for thoughts and events.
 type Store struct {
 	db *sql.DB
@@ -41,11 +46,14 @@ func (s *Store) CreateThought(content string) (int64, error) {
 	now := nowTime.Format(time.RFC3339Nano)
 	eligibilityAt := nowTime.Add(core.SettleDuration).Format(time.RFC3339Nano)
 	state := core.StateCaptured
-	sqlString := `INSERT INTO thoughts (content, current_state, tend_counter, created_at, updated_at, last_tended_at, eligibility_at, valence, energy)
-	             VALUES (?, ?, 0, ?, ?, NULL, ?, NULL, NULL)`
-	var err error
+	publicID, err := newPublicID()
+	if err != nil {
+		return -1, fmt.Errorf("create thought: %w", err)
+	}
+	sqlString := `INSERT INTO thoughts (public_id, content, current_state, tend_counter, created_at, updated_at, last_tended_at, eligibility_at, valence, energy)
+	             VALUES (?, ?, ?, 0, ?, ?, NULL, ?, NULL, NULL)`
 	var result sql.Result
-	result, err = s.db.Exec(sqlString, content, string(state), now, now, eligibilityAt)
+	result, err = s.exec(sqlString, publicID, content, string(state), now, now, eligibilityAt)
 	if err != nil {
 		return -1, fmt.Errorf("create thought: insert: %w", err)
 	}
@@ -57,6 +65,47 @@ func (s *Store) CreateThought(content string) (int64, error) {
 	return id, nil
 }
 
+// newPublicID returns a random stable identifier: a lowercase letter followed by ten hex digits.
+func newPublicID() (string, error) {
+	var buf [6]byte
+	if _, err := rand.Read(buf[:]); err != nil {
+		return "", fmt.Errorf("public id: %w", err)
+	}
+	return string(rune('a'+buf[0]%26)) + hex.EncodeToString(buf[1:]), nil
+}
+
+// ResolveThoughtID maps a thought reference to its numeric ID.
+// A reference is either a numeric ID (optionally prefixed with #) or a stable public ID.
+func (s *Store) ResolveThoughtID(ref string) (int64, error) {
+	if s == nil {
+		return -1, fmt.Errorf("resolve thought: store is nil")
+	}
+	if s.db == nil {
+		return -1, fmt.Errorf("resolve thought: db is nil")
+	}
+
+	ref = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(ref), "#"))
+	if id, err := strconv.ParseInt(ref, 10, 64); err == nil {
+		if id <= 0 {
+			return -1, fmt.Errorf("resolve thought: invalid thought ID")
+		}
+		return id, nil
+	}
+	if !core.IsPublicID(ref) {
+		return -1, fmt.Errorf("resolve thought: invalid thought ID %q", ref)
+	}
+
+	var id int64
+	err := s.db.QueryRow(`SELECT id FROM thoughts WHERE public_id = ?`, ref).Scan(&id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return -1, fmt.Errorf("resolve thought: %w", ErrNotFound)
+		}
+		return -1, fmt.Errorf("resolve thought: query: %w", err)
+	}
+	return id, nil
+}
+
 // AppendEvent appends an immutable event row for a thought.
 func (s *Store) AppendEvent(thoughtID int64, kind string, previousState, nextState *core.State, note *string) error {
 	if s == nil {
@@ -96,7 +145,7 @@ func (s *Store) AppendEvent(thoughtID int64, kind string, previousState, nextSta
 
 	sqlString := `INSERT INTO events (thought_id, kind, at, previous_state, next_state, note) VALUES (?, ?, ?, ?, ?, ?)`
 	var err error
-	_, err = s.db.Exec(sqlString, thoughtID, kind, now, previousStateValue, nextStateValue, noteValue)
+	_, err = s.exec(sqlString, thoughtID, kind, now, previousStateValue, nextStateValue, noteValue)
 	if err != nil {
 		return fmt.Errorf("append event: insert: %w", err)
 	}
@@ -105,539 +154,73 @@ func (s *Store) AppendEvent(thoughtID int64, kind string, previousState, nextSta
 
 // GetThought returns the thought snapshot and its ordered event history.
 func (s *Store) GetThought(id int64) (core.Thought, []core.Event, error) {
-	if s == nil {
-		return core.Thought{}, nil, fmt.Errorf("get thought: store is nil")
-	}
-	if s.db == nil {
-		return core.Thought{}, nil, fmt.Errorf("get thought: db is nil")
-	}
 	if id <= 0 {
 		return core.Thought{}, nil, fmt.Errorf("get thought: invalid thought ID")
 	}
-
-	sqlThought := `SELECT id, content, current_state, tend_counter, created_at, updated_at, last_tended_at, eligibility_at, valence, energy FROM thoughts WHERE id = ?`
-
-	var thought core.Thought
-	var createdAtStr, updatedAtStr string
-	var lastTendedAtStr sql.NullString
-	var valence sql.NullInt64
-	var energy sql.NullInt64
-	var stateStr string
-	var tendCounter int
-	var eligibilityAtStr string
-
-	var err error
-	row := s.db.QueryRow(sqlThought, id)
-	err = row.Scan(&thought.ID, &thought.Content, &stateStr, &tendCounter, &createdAtStr, &updatedAtStr, &lastTendedAtStr, &eligibilityAtStr, &valence, &energy)
-	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return core.Thought{}, nil, fmt.Errorf("get thought: not found")
-		}
-		return core.Thought{}, nil, fmt.Errorf("get thought: scan: %w", err)
-	}
-
-	thought.CurrentState = core.State(stateStr)
-	thought.TendCounter = tendCounter
-
-	thought.CreatedAt, err = time.Parse(time.RFC3339Nano, createdAtStr)
-	if err != nil {
-		return core.Thought{}, nil, fmt.Errorf("get thought: parse created_at: %w", err)
-	}
-	thought.UpdatedAt, err = time.Parse(time.RFC3339Nano, updatedAtStr)
-	if err != nil {
-		return core.Thought{}, nil, fmt.Errorf("get thought: parse updated_at: %w", err)
-	}
-
-	thought.EligibilityAt, err = time.Parse(time.RFC3339Nano, eligibilityAtStr)
-	if err != nil {
-		return core.Thought{}, nil, fmt.Errorf("get thought: parse eligibility_at: %w", err)
-	}
-
-	if lastTendedAtStr.Valid {
-		var t time.Time
-		t, err = time.Parse(time.RFC3339Nano, lastTendedAtStr.String)
-		if err != nil {
-			return core.Thought{}, nil, fmt.Errorf("get thought: parse last_tended_at: %w", err)
-		}
-		thought.LastTendedAt = &t
-	}
-
-	if valence.Valid {
-		v := int(valence.Int64)
-		thought.Valence = &v
-	}
-
-	if energy.Valid {
-		e := int(energy.Int64)
-		thought.Energy = &e
-	}
-
-	sqlEvents := `SELECT id, thought_id, kind, at, previous_state, next_state, note FROM events WHERE thought_id = ? ORDER BY at ASC, id ASC`
-	var rows *sql.Rows
-	rows, err = s.db.Query(sqlEvents, id)
-	if err != nil {
-		return core.Thought{}, nil, fmt.Errorf("get thought: query events: %w", err)
-	}
-	defer rows.Close()
-
-	events := make([]core.Event, 0)
-	for rows.Next() {
-		var event core.Event
-		var atStr string
-		var previousStateStr sql.NullString
-		var nextStateStr sql.NullString
-		var noteStr sql.NullString
-
-		err = rows.Scan(&event.ID, &event.ThoughtID, &event.Kind, &atStr, &previousStateStr, &nextStateStr, &noteStr)
-		if err != nil {
-			return core.Thought{}, nil, fmt.Errorf("get thought: scan event: %w", err)
-		}
-
-		event.At, err = time.Parse(time.RFC3339Nano, atStr)
-		if err != nil {
-			return core.Thought{}, nil, fmt.Errorf("get thought: parse event at: %w", err)
-		}
-		if previousStateStr.Valid {
-			ps := core.State(previousStateStr.String)
-			event.PreviousState = &ps
-		}
-		if nextStateStr.Valid {
-			ns := core.State(nextStateStr.String)
-			event.NextState = &ns
-		}
-
-		if noteStr.Valid {
-			n := noteStr.String
-			event.Note = &n
-		}
-
-		events = append(events, event)
-	}
-
-	err = rows.Err()
-	if err != nil {
-		return core.Thought{}, nil, fmt.Errorf("get thought: events rows: %w", err)
-	}
-
-	return thought, events, nil
+	return s.getThought(ThoughtQuery{IDs: []int64{id}})
 }
 
 // GetTendThought returns a thought and its events only if it is currently eligible for tending.
 func (s *Store) GetTendThought(id int64) (core.Thought, []core.Event, error) {
-	if s == nil {
-		return core.Thought{}, nil, fmt.Errorf("get thought: store is nil")
-	}
-	if s.db == nil {
-		return core.Thought{}, nil, fmt.Errorf("get thought: db is nil")
-	}
 	if id <= 0 {
 		return core.Thought{}, nil, fmt.Errorf("get thought: invalid thought ID")
 	}
+	q := TendQuery(time.Now().UTC())
+	q.IDs = []int64{id}
+	return s.getThought(q)
+}
 
-	nowStr := time.Now().UTC().Format(time.RFC3339Nano)
-
-	sqlThought := `SELECT id, content, current_state, tend_counter, created_at, updated_at, last_tended_at, eligibility_at, valence, energy
-	               FROM thoughts
-				   WHERE id = ? AND current_state IN (?, ?) AND eligibility_at <= ?
-				  `
-
-	var thought core.Thought
-	var createdAtStr, updatedAtStr string
-	var lastTendedAtStr sql.NullString
-	var valence sql.NullInt64
-	var energy sql.NullInt64
-	var stateStr string
-	var tendCounter int
-	var eligibilityAtStr string
-
-	var err error
-	row := s.db.QueryRow(sqlThought, id, string(core.StateCaptured), string(core.StateResting), nowStr)
-	err = row.Scan(&thought.ID, &thought.Content, &stateStr, &tendCounter, &createdAtStr, &updatedAtStr, &lastTendedAtStr, &eligibilityAtStr, &valence, &energy)
-	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return core.Thought{}, nil, fmt.Errorf("get thought: not found")
-		}
-		return core.Thought{}, nil, fmt.Errorf("get thought: scan: %w", err)
-	}
-
-	thought.CurrentState = core.State(stateStr)
-	thought.TendCounter = tendCounter
-
-	thought.CreatedAt, err = time.Parse(time.RFC3339Nano, createdAtStr)
-	if err != nil {
-		return core.Thought{}, nil, fmt.Errorf("get thought: parse created_at: %w", err)
-	}
-
-	thought.UpdatedAt, err = time.Parse(time.RFC3339Nano, updatedAtStr)
-	if err != nil {
-		return core.Thought{}, nil, fmt.Errorf("get thought: parse updated_at: %w", err)
-	}
-
-	thought.EligibilityAt, err = time.Parse(time.RFC3339Nano, eligibilityAtStr)
+// getThought returns the one thought q selects with its events, or ErrNotFound.
+func (s *Store) getThought(q ThoughtQuery) (core.Thought, []core.Event, error) {
+	items, err := s.queryThoughts("get thought", q, true)
 	if err != nil {
-		return core.Thought{}, nil, fmt.Errorf("get thought: parse eligibility_at: %w", err)
-	}
-
-	if lastTendedAtStr.Valid {
-		var t time.Time
-		t, err = time.Parse(time.RFC3339Nano, lastTendedAtStr.String)
-		if err != nil {
-			return core.Thought{}, nil, fmt.Errorf("get thought: parse last_tended_at: %w", err)
-		}
-		thought.LastTendedAt = &t
-	}
-
-	if valence.Valid {
-		v := int(valence.Int64)
-		thought.Valence = &v
-	}
-
-	if energy.Valid {
-		e := int(energy.Int64)
-		thought.Energy = &e
+		return core.Thought{}, nil, err
 	}
-
-	sqlEvents := `SELECT id, thought_id, kind, at, previous_state, next_state, note
-	              FROM events
-	              WHERE thought_id = ?
-	              ORDER BY at ASC, id ASC
-				 `
-
-	rows, err := s.db.Query(sqlEvents, id)
-	if err != nil {
-		return core.Thought{}, nil, fmt.Errorf("get thought: query events: %w", err)
+	if len(items) == 0 {
+		return core.Thought{}, nil, fmt.Errorf("get thought: %w", ErrNotFound)
 	}
-	defer rows.Close()
-
-	events := make([]core.Event, 0)
-	for rows.Next() {
-		var event core.Event
-		var atStr string
-		var previousStateStr sql.NullString
-		var nextStateStr sql.NullString
-		var noteStr sql.NullString
-
-		err = rows.Scan(&event.ID, &event.ThoughtID, &event.Kind, &atStr, &previousStateStr, &nextStateStr, &noteStr)
-		if err != nil {
-			return core.Thought{}, nil, fmt.Errorf("get thought: scan event: %w", err)
-		}
-
-		event.At, err = time.Parse(time.RFC3339Nano, atStr)
-		if err != nil {
-			return core.Thought{}, nil, fmt.Errorf("get thought: parse event at: %w", err)
-		}
-
-		if previousStateStr.Valid {
-			ps := core.State(previousStateStr.String)
-			event.PreviousState = &ps
-		}
-
-		if nextStateStr.Valid {
-			ns := core.State(nextStateStr.String)
-			event.NextState = &ns
-		}
-
-		if noteStr.Valid {
-			n := noteStr.String
-			event.Note = &n
-		}
-
-		events = append(events, event)
-	}
-
-	if err = rows.Err(); err != nil {
-		return core.Thought{}, nil, fmt.Errorf("get thought: events rows: %w", err)
-	}
-
-	return thought, events, nil
+	return items[0].Thought, items[0].Events, nil
 }
 
 // ListThoughtsByPagination returns a page of thoughts ordered by updated time and ID.
+// Released thoughts sit in the trash and are left to ListReleasedThoughts.
 func (s *Store) ListThoughtsByPagination(limit, offset int) ([]core.Thought, error) {
-	if s == nil {
-		return nil, fmt.Errorf("list thoughts: store is nil")
-	}
-	if s.db == nil {
-		return nil, fmt.Errorf("list thoughts: db is nil")
-	}
-	if limit <= 0 {
-		return nil, fmt.Errorf("list thoughts: limit must be > 0")
-	}
-	if offset < 0 {
-		return nil, fmt.Errorf("list thoughts: offset must be >= 0")
-	}
-
-	sqlList := `SELECT id, content, current_state, tend_counter, updated_at
-                FROM thoughts
-                ORDER BY updated_at ASC, id ASC
-                LIMIT ? OFFSET ?`
-
-	rows, err := s.db.Query(sqlList, limit, offset)
-	if err != nil {
-		return nil, fmt.Errorf("list thoughts: query: %w", err)
-	}
-	defer rows.Close()
-
-	thoughts := make([]core.Thought, 0, limit)
-	for rows.Next() {
-		var thought core.Thought
-		var stateStr string
-		var updatedAtStr string
-
-		if err := rows.Scan(&thought.ID, &thought.Content, &stateStr, &thought.TendCounter, &updatedAtStr); err != nil {
-			return nil, fmt.Errorf("list thoughts: scan: %w", err)
-		}
-
-		thought.CurrentState = core.State(stateStr)
-
-		thought.UpdatedAt, err = time.Parse(time.RFC3339Nano, updatedAtStr)
-		if err != nil {
-			return nil, fmt.Errorf("list thoughts: parse updated_at: %w", err)
-		}
-
-		thoughts = append(thoughts, thought)
-	}
-
-	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("list thoughts: rows: %w", err)
-	}
-
-	return thoughts, nil
+	return s.listPage("list thoughts", limit, offset, GardenQuery())
 }
 
 // ListBloomThoughtsByPagination returns thoughts visible to the Bloom TUI.
 func (s *Store) ListBloomThoughtsByPagination(limit, offset int) ([]core.Thought, error) {
-	if s == nil {
-		return nil, fmt.Errorf("list bloom thoughts: store is nil")
-	}
-	if s.db == nil {
-		return nil, fmt.Errorf("list bloom thoughts: db is nil")
-	}
-	if limit <= 0 {
-		return nil, fmt.Errorf("list bloom thoughts: limit must be > 0")
-	}
-	if offset < 0 {
-		return nil, fmt.Errorf("list bloom thoughts: offset must be >= 0")
-	}
-
-	sqlList := `SELECT id, content, current_state, tend_counter, updated_at
-                FROM thoughts
-                WHERE current_state NOT IN (?, ?)
-                ORDER BY updated_at ASC, id ASC
-                LIMIT ? OFFSET ?`
-
-	rows, err := s.db.Query(sqlList, core.StateArchived, core.StateReleased, limit, offset)
-	if err != nil {
-		return nil, fmt.Errorf("list bloom thoughts: query: %w", err)
-	}
-	defer rows.Close()
-
-	thoughts := make([]core.Thought, 0, limit)
-	for rows.Next() {
-		var thought core.Thought
-		var stateStr string
-		var updatedAtStr string
-
-		if err := rows.Scan(&thought.ID, &thought.Content, &stateStr, &thought.TendCounter, &updatedAtStr); err != nil {
-			return nil, fmt.Errorf("list bloom thoughts: scan: %w", err)
-		}
-
-		thought.CurrentState = core.State(stateStr)
-
-		thought.UpdatedAt, err = time.Parse(time.RFC3339Nano, updatedAtStr)
-		if err != nil {
-			return nil, fmt.Errorf("list bloom thoughts: parse updated_at: %w", err)
-		}
-
-		thoughts = append(thoughts, thought)
-	}
-
-	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("list bloom thoughts: rows: %w", err)
-	}
-
-	return thoughts, nil
+	return s.listPage("list bloom thoughts", limit, offset, BloomQuery())
 }
 
 // ListTendThoughtsByPagination returns a page of thoughts eligible for tending ordered by eligibility time and ID.
 func (s *Store) ListTendThoughtsByPagination(limit, offset int) ([]core.Thought, error) {
-	if s == nil {
-		return nil, fmt.Errorf("list tend thoughts: store is nil")
-	}
-	if s.db == nil {
-		return nil, fmt.Errorf("list tend thoughts: db is nil")
-	}
-	if limit <= 0 {
-		return nil, fmt.Errorf("list tend thoughts: limit must be > 0")
-	}
-	if offset < 0 {
-		return nil, fmt.Errorf("list tend thoughts: offset must be >= 0")
-	}
-
-	nowStr := time.Now().UTC().Format(time.RFC3339Nano)
-
-	sqlList := `SELECT id, content, current_state, tend_counter, created_at, updated_at, last_tended_at, eligibility_at, valence, energy
-	            FROM thoughts
-	            WHERE current_state IN (?, ?)
-	              AND eligibility_at <= ?
-	            ORDER BY eligibility_at ASC, id ASC
-	            LIMIT ? OFFSET ?`
-
-	rows, err := s.db.Query(sqlList, string(core.StateCaptured), string(core.StateResting), nowStr, limit, offset)
-	if err != nil {
-		return nil, fmt.Errorf("list tend thoughts: query: %w", err)
-	}
-	defer rows.Close()
-
-	thoughts := make([]core.Thought, 0, limit)
-	for rows.Next() {
-		var thought core.Thought
-
-		var stateStr string
-		var tendCounter int
-		var createdAtStr, updatedAtStr string
-		var lastTendedAtStr sql.NullString
-		var eligibilityAtStr string
-		var valence sql.NullInt64
-		var energy sql.NullInt64
-
-		err = rows.Scan(&thought.ID, &thought.Content, &stateStr, &tendCounter, &createdAtStr, &updatedAtStr, &lastTendedAtStr, &eligibilityAtStr, &valence, &energy)
-		if err != nil {
-			return nil, fmt.Errorf("list tend thoughts: scan: %w", err)
-		}
-
-		thought.CurrentState = core.State(stateStr)
-		thought.TendCounter = tendCounter
-
-		var err error
-		thought.CreatedAt, err = time.Parse(time.RFC3339Nano, createdAtStr)
-		if err != nil {
-			return nil, fmt.Errorf("list tend thoughts: parse created_at: %w", err)
-		}
-
-		thought.UpdatedAt, err = time.Parse(time.RFC3339Nano, updatedAtStr)
-		if err != nil {
-			return nil, fmt.Errorf("list tend thoughts: parse updated_at: %w", err)
-		}
-
-		thought.EligibilityAt, err = time.Parse(time.RFC3339Nano, eligibilityAtStr)
-		if err != nil {
-			return nil, fmt.Errorf("list tend thoughts: parse eligibility_at: %w", err)
-		}
-
-		if lastTendedAtStr.Valid {
-			t, err := time.Parse(time.RFC3339Nano, lastTendedAtStr.String)
-			if err != nil {
-				return nil, fmt.Errorf("list tend thoughts: parse last_tended_at: %w", err)
-			}
-			thought.LastTendedAt = &t
-		}
-
-		if valence.Valid {
-			v := int(valence.Int64)
-			thought.Valence = &v
-		}
-		if energy.Valid {
-			e := int(energy.Int64)
-			thought.Energy = &e
-		}
-
-		thoughts = append(thoughts, thought)
-	}
-
-	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("list tend thoughts: rows: %w", err)
-	}
-
-	return thoughts, nil
+	return s.listPage("list tend thoughts", limit, offset, TendQuery(time.Now().UTC()))
 }
 
+// FilterViewByPagination returns a page of thoughts in the state filter names, ordered by ID.
 func (s *Store) FilterViewByPagination(limit, offset int, filter string) ([]core.Thought, error) {
-	if s == nil {
-		return nil, fmt.Errorf("list view thoughts: store is nil")
-	}
-	if s.db == nil {
-		return nil, fmt.Errorf("list view thoughts: db is nil")
-	}
+	return s.listPage("list view thoughts", limit, offset, StateQuery(core.State(filter)))
+}
+
+// listPage runs q for one page of a paginated list. op prefixes errors.
+func (s *Store) listPage(op string, limit, offset int, q ThoughtQuery) ([]core.Thought, error) {
 	if limit <= 0 {
-		return nil, fmt.Errorf("list view thoughts: limit must be > 0")
+		return nil, fmt.Errorf("%s: limit must be > 0", op)
 	}
 	if offset < 0 {
-		return nil, fmt.Errorf("list view thoughts: offset must be >= 0")
+		return nil, fmt.Errorf("%s: offset must be >= 0", op)
 	}
+	q.Limit, q.Offset = limit, offset
 
-	sqlList := `SELECT id, content, current_state, tend_counter, created_at, updated_at, last_tended_at, eligibility_at, valence, energy
-	            FROM thoughts
-	            WHERE current_state IN (?)
-	            ORDER BY id ASC
-	            LIMIT ? OFFSET ?`
-
-	rows, err := s.db.Query(sqlList, filter, limit, offset)
+	items, err := s.queryThoughts(op, q, false)
 	if err != nil {
-		return nil, fmt.Errorf("list view thoughts: query: %w", err)
-	}
-	defer rows.Close()
-
-	thoughts := make([]core.Thought, 0, limit)
-	for rows.Next() {
-		var thought core.Thought
-
-		var stateStr string
-		var tendCounter int
-		var createdAtStr, updatedAtStr string
-		var lastTendedAtStr sql.NullString
-		var eligibilityAtStr string
-		var valence sql.NullInt64
-		var energy sql.NullInt64
-
-		err = rows.Scan(&thought.ID, &thought.Content, &stateStr, &tendCounter, &createdAtStr, &updatedAtStr, &lastTendedAtStr, &eligibilityAtStr, &valence, &energy)
-		if err != nil {
-			return nil, fmt.Errorf("list view thoughts: scan: %w", err)
-		}
-
-		thought.CurrentState = core.State(stateStr)
-		thought.TendCounter = tendCounter
-
-		var err error
-		thought.CreatedAt, err = time.Parse(time.RFC3339Nano, createdAtStr)
-		if err != nil {
-			return nil, fmt.Errorf("list view thoughts: parse created_at: %w", err)
-		}
-
-		thought.UpdatedAt, err = time.Parse(time.RFC3339Nano, updatedAtStr)
-		if err != nil {
-			return nil, fmt.Errorf("list view thoughts: parse updated_at: %w", err)
-		}
-
-		thought.EligibilityAt, err = time.Parse(time.RFC3339Nano, eligibilityAtStr)
-		if err != nil {
-			return nil, fmt.Errorf("list view thoughts: parse eligibility_at: %w", err)
-		}
-
-		if lastTendedAtStr.Valid {
-			t, err := time.Parse(time.RFC3339Nano, lastTendedAtStr.String)
-			if err != nil {
-				return nil, fmt.Errorf("list view thoughts: parse last_tended_at: %w", err)
-			}
-			thought.LastTendedAt = &t
-		}
-
-		if valence.Valid {
-			v := int(valence.Int64)
-			thought.Valence = &v
-		}
-		if energy.Valid {
-			e := int(energy.Int64)
-			thought.Energy = &e
-		}
-
-		thoughts = append(thoughts, thought)
+		return nil, err
 	}
-
-	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("list view thoughts: rows: %w", err)
+	thoughts := make([]core.Thought, 0, len(items))
+	for _, item := range items {
+		thoughts = append(thoughts, item.Thought)
 	}
-
 	return thoughts, nil
 }
 
@@ -657,7 +240,7 @@ func (s *Store) UpdateThoughtContent(id int64, content string) error {
 	}
 
 	now := time.Now().UTC().Format(time.RFC3339Nano)
-	result, err := s.db.Exec(
+	result, err := s.exec(
 		`UPDATE thoughts SET content = ?, updated_at = ? WHERE id = ?`,
 		content,
 		now,
@@ -690,7 +273,7 @@ func (s *Store) MarkThoughtTended(id int64, note *string) error {
 		return fmt.Errorf("mark thought tended: invalid thought ID")
 	}
 
-	tx, err := s.db.Begin()
+	tx, err := s.begin()
 	if err != nil {
 		return fmt.Errorf("mark thought tended: begin tx: %w", err)
 	}
@@ -698,58 +281,8 @@ func (s *Store) MarkThoughtTended(id int64, note *string) error {
 		_ = tx.Rollback()
 	}()
 
-	var prevStateStr string
-	row := tx.QueryRow(`SELECT current_state FROM thoughts WHERE id = ?`, id)
-	if err := row.Scan(&prevStateStr); err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return fmt.Errorf("mark thought tended: not found")
-		}
-		return fmt.Errorf("mark thought tended: read current_state: %w", err)
-	}
-
-	prev := core.State(prevStateStr)
-	if prev == core.StateEvolved || prev == core.StateReleased || prev == core.StateArchived {
-		return fmt.Errorf("mark thought tended: thought is in terminal state (%s)", prev)
-	}
-
-	now := time.Now().UTC().Format(time.RFC3339Nano)
-	next := core.StateTended
-
-	_, err = tx.Exec(
-		`UPDATE thoughts
-		 SET current_state = ?,
-		     tend_counter = tend_counter + 1,
-		     last_tended_at = ?,
-		     updated_at = ?
-		 WHERE id = ?`,
-		string(next),
-		now,
-		now,
-		id,
-	)
-	if err != nil {
-		return fmt.Errorf("mark thought tended: update thoughts: %w", err)
-	}
-
-	var noteValue any
-	if note != nil && strings.TrimSpace(*note) != "" {
-		noteValue = *note
-	} else {
-		noteValue = nil
-	}
-
-	_, err = tx.Exec(
-		`INSERT INTO events (thought_id, kind, at, previous_state, next_state, note)
-		 VALUES (?, ?, ?, ?, ?, ?)`,
-		id,
-		"state_change",
-		now,
-		string(prev),
-		string(next),
-		noteValue,
-	)
-	if err != nil {
-		return fmt.Errorf("mark thought tended: insert event: %w", err)
+	if _, err := transitionTx(tx, "mark thought tended", id, core.ActionTend, core.StateTended, note, 0); err != nil {
+		return err
 	}
 
 	if err := tx.Commit(); err != nil {
@@ -759,7 +292,15 @@ func (s *Store) MarkThoughtTended(id int64, note *string) error {
 }
 
 // TransitionPostTendResolutionStrict transitions a tended thought into resting or a terminal state and appends exactly one event.
+// A resting thought settles for core.SettleDuration.
 func (s *Store) TransitionPostTendResolutionStrict(id int64, next core.State, note *string) error {
+	return s.TransitionPostTendResolutionFor(id, next, note, 0)
+}
+
+// TransitionPostTendResolutionFor is TransitionPostTendResolutionStrict with a chosen rest length.
+// When next is resting, eligibility_at moves settle into the future (core.SettleDuration when settle is zero)
+// and the event records the length used. settle is ignored for every other state.
+func (s *Store) TransitionPostTendResolutionFor(id int64, next core.State, note *string, settle time.Duration) error {
 	if s == nil {
 		return fmt.Errorf("post-tend transition: store is nil")
 	}
@@ -769,12 +310,11 @@ func (s *Store) TransitionPostTendResolutionStrict(id int64, next core.State, no
 	if id <= 0 {
 		return fmt.Errorf("post-tend transition: invalid thought ID")
 	}
-
-	if next != core.StateResting && next != core.StateEvolved && next != core.StateReleased && next != core.StateArchived {
-		return fmt.Errorf("post-tend transition: invalid next state %q", next)
+	if settle < 0 {
+		return fmt.Errorf("post-tend transition: settle duration must not be negative")
 	}
 
-	tx, err := s.db.Begin()
+	tx, err := s.begin()
 	if err != nil {
 		return fmt.Errorf("post-tend transition: begin tx: %w", err)
 	}
@@ -782,82 +322,17 @@ func (s *Store) TransitionPostTendResolutionStrict(id int64, next core.State, no
 		_ = tx.Rollback()
 	}()
 
-	var prevStateStr string
-	row := tx.QueryRow(`SELECT current_state FROM thoughts WHERE id = ?`, id)
-	if err := row.Scan(&prevStateStr); err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return fmt.Errorf("post-tend transition: not found")
-		}
-		return fmt.Errorf("post-tend transition: read current_state: %w", err)
-	}
-
-	prev := core.State(prevStateStr)
-	if prev != core.StateTended {
-		return fmt.Errorf("post-tend transition: thought is not in tended state (currently %s)", prev)
-	}
-
-	nowTime := time.Now().UTC()
-	now := nowTime.Format(time.RFC3339Nano)
-
-	var noteValue any
-	if note != nil && strings.TrimSpace(*note) != "" {
-		noteValue = *note
-	} else {
-		noteValue = nil
-	}
-
-	if next == core.StateResting {
-		eligibilityAt := nowTime.Add(core.SettleDuration).Format(time.RFC3339Nano)
-		_, err = tx.Exec(
-			`UPDATE thoughts
-			 SET current_state = ?,
-			     updated_at = ?,
-			     eligibility_at = ?
-			 WHERE id = ?`,
-			string(next),
-			now,
-			eligibilityAt,
-			id,
-		)
-		if err != nil {
-			return fmt.Errorf("post-tend transition: update thoughts (rest): %w", err)
-		}
-	} else {
-		_, err = tx.Exec(
-			`UPDATE thoughts
-			 SET current_state = ?,
-			     updated_at = ?
-			 WHERE id = ?`,
-			string(next),
-			now,
-			id,
-		)
-		if err != nil {
-			return fmt.Errorf("post-tend transition: update thoughts: %w", err)
-		}
-	}
-
-	_, err = tx.Exec(
-		`INSERT INTO events (thought_id, kind, at, previous_state, next_state, note)
-		 VALUES (?, ?, ?, ?, ?, ?)`,
-		id,
-		"state_change",
-		now,
-		string(prev),
-		string(next),
-		noteValue,
-	)
-	if err != nil {
-		return fmt.Errorf("post-tend transition: insert event: %w", err)
+	if _, err := transitionTx(tx, "post-tend transition", id, core.ActionResolve, next, note, settle); err != nil {
+		return err
 	}
 
 	if err := tx.Commit(); err != nil {
 		return fmt.Errorf("post-tend transition: commit: %w", err)
 	}
-
 	return nil
 }
 
+// ToEvolve marks a thought as evolved and appends a state-change event.
 func (s *Store) ToEvolve(id int64) error {
 	if s == nil {
 		return fmt.Errorf("to evolve: store is nil")
@@ -869,7 +344,7 @@ func (s *Store) ToEvolve(id int64) error {
 		return fmt.Errorf("to evolve: invalid thought ID")
 	}
 
-	tx, err := s.db.Begin()
+	tx, err := s.begin()
 	if err != nil {
 		return fmt.Errorf("to evolve: begin tx: %w", err)
 	}
@@ -877,44 +352,8 @@ func (s *Store) ToEvolve(id int64) error {
 		_ = tx.Rollback()
 	}()
 
-	var prevStateStr string
-	row := tx.QueryRow(`SELECT current_state FROM thoughts WHERE id = ?`, id)
-	if err := row.Scan(&prevStateStr); err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return fmt.Errorf("to evolve: not found")
-		}
-		return fmt.Errorf("to evolve: read current_state: %w", err)
-	}
-
-	prev := core.State(prevStateStr)
-	if prev == core.StateEvolved || prev == core.StateReleased || prev == core.StateArchived {
-		return fmt.Errorf("to evolve: thought is in terminal state (%s)", prev)
-	}
-
-	now := time.Now().UTC().Format(time.RFC3339Nano)
-	state := core.StateEvolved
-
-	sqlQuery := `UPDATE thoughts
-		 		 SET current_state = ?,
-		     		updated_at = ?
-		 		 WHERE id = ?`
-	res, err := tx.Exec(sqlQuery, state, now, id)
-
-	if err != nil {
-		return fmt.Errorf("to evolve: update thoughts: %w", err)
-	}
-
-	rows, err := res.RowsAffected()
-	if err != nil {
-		return fmt.Errorf("to evolve: rows affected: %w", err)
-	}
-	if rows == 0 {
-		return fmt.Errorf("to evolve: not found")
-	}
-
-	_, err = tx.Exec(`INSERT INTO events (thought_id, kind, at, previous_state, next_state, note) VALUES (?, ?, ?, ?, ?, ?)`, id, "state_change", now, string(prev), string(state), nil)
-	if err != nil {
-		return fmt.Errorf("to evolve: insert event: %w", err)
+	if _, err := transitionTx(tx, "to evolve", id, core.ActionEvolve, core.StateEvolved, nil, 0); err != nil {
+		return err
 	}
 
 	if err := tx.Commit(); err != nil {
@@ -925,6 +364,11 @@ func (s *Store) ToEvolve(id int64) error {
 
 // ToArchive marks a thought as archived and appends a state-change event.
 func (s *Store) ToArchive(id int64) error {
+	return s.ToArchiveWithNote(id, nil)
+}
+
+// ToArchiveWithNote is ToArchive with an optional note kept on the state_change event.
+func (s *Store) ToArchiveWithNote(id int64, note *string) error {
 	if s == nil {
 		return fmt.Errorf("to archive: store is nil")
 	}
@@ -935,7 +379,7 @@ func (s *Store) ToArchive(id int64) error {
 		return fmt.Errorf("to archive: invalid thought ID")
 	}
 
-	tx, err := s.db.Begin()
+	tx, err := s.begin()
 	if err != nil {
 		return fmt.Errorf("to archive: begin tx: %w", err)
 	}
@@ -943,65 +387,53 @@ func (s *Store) ToArchive(id int64) error {
 		_ = tx.Rollback()
 	}()
 
-	var prevStateStr string
-	row := tx.QueryRow(`SELECT current_state FROM thoughts WHERE id = ?`, id)
-	if err := row.Scan(&prevStateStr); err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return fmt.Errorf("to archive: not found")
-		}
-		return fmt.Errorf("to archive: read current_state: %w", err)
+	if _, err := transitionTx(tx, "to archive", id, core.ActionArchive, core.StateArchived, note, 0); err != nil {
+		return err
 	}
 
-	prev := core.State(prevStateStr)
-	if prev == core.StateEvolved || prev == core.StateReleased || prev == core.StateArchived {
-		return fmt.Errorf("to archive: thought is in terminal state (%s)", prev)
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("to archive: commit: %w", err)
 	}
+	return nil
+}
 
-	now := time.Now().UTC().Format(time.RFC3339Nano)
-	state := core.StateArchived
-
-	res, err := tx.Exec(
-		`UPDATE thoughts
-		 SET current_state = ?,
-		     updated_at = ?
-		 WHERE id = ?`,
-		string(state),
-		now,
-		id,
-	)
-	if err != nil {
-		return fmt.Errorf("to archive: update thoughts: %w", err)
+// ReviveThought moves an archived or evolved thought back to resting and appends a "revived" event.
+// Eligibility starts over: the thought settles for settle, or core.SettleDuration when settle is zero.
+// Earlier history is kept as it was.
+func (s *Store) ReviveThought(id int64, note *string, settle time.Duration) error {
+	if s == nil {
+		return fmt.Errorf("revive: store is nil")
 	}
-
-	rows, err := res.RowsAffected()
-	if err != nil {
-		return fmt.Errorf("to archive: rows affected: %w", err)
+	if s.db == nil {
+		return fmt.Errorf("revive: db is nil")
 	}
-	if rows == 0 {
-		return fmt.Errorf("to archive: not found")
+	if id <= 0 {
+		return fmt.Errorf("revive: invalid thought ID")
+	}
+	if settle < 0 {
+		return fmt.Errorf("revive: settle duration must not be negative")
 	}
 
-	_, err = tx.Exec(
-		`INSERT INTO events (thought_id, kind, at, previous_state, next_state, note)
-		 VALUES (?, ?, ?, ?, ?, ?)`,
-		id,
-		"state_change",
-		now,
-		string(prev),
-		string(state),
-		nil,
-	)
+	tx, err := s.begin()
 	if err != nil {
-		return fmt.Errorf("to archive: insert event: %w", err)
+		return fmt.Errorf("revive: begin tx: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	if _, err := transitionTx(tx, "revive", id, core.ActionRevive, core.StateResting, note, settle); err != nil {
+		return err
 	}
 
 	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("to archive: commit: %w", err)
+		return fmt.Errorf("revive: commit: %w", err)
 	}
 	return nil
 }
 
-// ReleaseThought permanently deletes a thought and its associated events.
+// ReleaseThought permanently deletes a thought and its associated events and tag links.
+// SoftReleaseThought is the recoverable form; this is what purging and release --now use.
 func (s *Store) ReleaseThought(id int64) error {
 	if s == nil {
 		return fmt.Errorf("release thought: store is nil")
@@ -1013,7 +445,7 @@ func (s *Store) ReleaseThought(id int64) error {
 		return fmt.Errorf("release thought: invalid thought ID")
 	}
 
-	tx, err := s.db.Begin()
+	tx, err := s.begin()
 	if err != nil {
 		return fmt.Errorf("release thought: begin tx: %w", err)
 	}
@@ -1021,22 +453,12 @@ func (s *Store) ReleaseThought(id int64) error {
 		_ = tx.Rollback()
 	}()
 
-	_, err = tx.Exec(`DELETE FROM events WHERE thought_id = ?`, id)
+	found, err := deleteThoughtTx(tx, id)
 	if err != nil {
-		return fmt.Errorf("release thought: delete events: %w", err)
+		return fmt.Errorf("release thought: %w", err)
 	}
-
-	res, err := tx.Exec(`DELETE FROM thoughts WHERE id = ?`, id)
-	if err != nil {
-		return fmt.Errorf("release thought: delete thought: %w", err)
-	}
-
-	affected, err := res.RowsAffected()
-	if err != nil {
-		return fmt.Errorf("release thought: rows affected: %w", err)
-	}
-	if affected == 0 {
-		return fmt.Errorf("release thought: not found")
+	if !found {
+		return fmt.Errorf("release thought: %w", ErrNotFound)
 	}
 
 	if err := tx.Commit(); err != nil {
@@ -1111,7 +533,7 @@ func (s *Store) ensureAppStateTable() error {
 	if s == nil || s.db == nil {
 		return fmt.Errorf("ensure app_state: store/db is nil")
 	}
-	_, err := s.db.Exec(`
+	_, err := s.exec(`
 		CREATE TABLE IF NOT EXISTS app_state (
 			key TEXT PRIMARY KEY,
 			value TEXT NOT NULL,
@@ -1132,7 +554,7 @@ func (s *Store) setAppStateInt(key string, value int) error {
 		return fmt.Errorf("set app_state: empty key")
 	}
 	now := time.Now().UTC().Format(time.RFC3339Nano)
-	_, err := s.db.Exec(
+	_, err := s.exec(
 		`INSERT INTO app_state(key, value, updated_at)
 		 VALUES (?, ?, ?)
 		 ON CONFLICT(key) DO UPDATE SET value = excluded.value, updated_at = excluded.updated_at`,
@@ -1146,8 +568,10 @@ func (s *Store) setAppStateInt(key string, value int) error {
 	return nil
 }
 
-// ReindexThoughtIDs renumbers thought IDs to be contiguous (1..N) and rewrites event foreign keys.
+// ReindexThoughtIDs renumbers thought IDs to be contiguous (1..N) and rewrites event and tag foreign keys.
 // This is a UX nicety for a local-only CLI and is intended to be called after deletions.
+// Public IDs are carried over unchanged; see core.ReindexOnRelease. Because every table is
+// rebuilt, an automatic backup is taken first.
 func (s *Store) ReindexThoughtIDs() error {
 	if s == nil {
 		return fmt.Errorf("reindex thought ids: store is nil")
@@ -1155,8 +579,11 @@ func (s *Store) ReindexThoughtIDs() error {
 	if s.db == nil {
 		return fmt.Errorf("reindex thought ids: db is nil")
 	}
+	if _, err := automaticBackup(s.db, "reindex"); err != nil {
+		return fmt.Errorf("reindex thought ids: backup: %w", err)
+	}
 
-	tx, err := s.db.Begin()
+	tx, err := s.begin()
 	if err != nil {
 		return fmt.Errorf("reindex thought ids: begin tx: %w", err)
 	}
@@ -1195,7 +622,9 @@ func (s *Store) ReindexThoughtIDs() error {
 			last_tended_at TEXT NULL,
 			eligibility_at TEXT NOT NULL,
 			valence INTEGER NULL,
-			energy INTEGER NULL
+			energy INTEGER NULL,
+			public_id TEXT NULL,
+			released_at TEXT NULL
 		);
 	`)
 	if err != nil {
@@ -1211,6 +640,7 @@ func (s *Store) ReindexThoughtIDs() error {
 			previous_state TEXT NULL,
 			next_state TEXT NULL,
 			note TEXT NULL,
+			settle_seconds INTEGER NULL,
 			FOREIGN KEY(thought_id) REFERENCES thoughts_new(id)
 		);
 	`)
@@ -1218,10 +648,24 @@ func (s *Store) ReindexThoughtIDs() error {
 		return fmt.Errorf("reindex thought ids: create events_new: %w", err)
 	}
 
+	_, err = tx.Exec(`
+		CREATE TABLE thought_tags_new (
+			thought_id INTEGER NOT NULL,
+			tag_id INTEGER NOT NULL,
+			created_at TEXT NOT NULL,
+			PRIMARY KEY(thought_id, tag_id),
+			FOREIGN KEY(thought_id) REFERENCES thoughts_new(id),
+			FOREIGN KEY(tag_id) REFERENCES tags(id)
+		);
+	`)
+	if err != nil {
+		return fmt.Errorf("reindex thought ids: create thought_tags_new: %w", err)
+	}
+
 	// Copy data with remapped IDs.
 	_, err = tx.Exec(`
-		INSERT INTO thoughts_new (id, content, current_state, tend_counter, created_at, updated_at, last_tended_at, eligibility_at, valence, energy)
-		SELECT m.new_id, t.content, t.current_state, t.tend_counter, t.created_at, t.updated_at, t.last_tended_at, t.eligibility_at, t.valence, t.energy
+		INSERT INTO thoughts_new (id, content, current_state, tend_counter, created_at, updated_at, last_tended_at, eligibility_at, valence, energy, public_id, released_at)
+		SELECT m.new_id, t.content, t.current_state, t.tend_counter, t.created_at, t.updated_at, t.last_tended_at, t.eligibility_at, t.valence, t.energy, t.public_id, t.released_at
 		FROM thoughts t
 		JOIN thought_id_map m ON m.old_id = t.id
 		ORDER BY m.new_id;
@@ -1231,8 +675,8 @@ func (s *Store) ReindexThoughtIDs() error {
 	}
 
 	_, err = tx.Exec(`
-		INSERT INTO events_new (id, thought_id, kind, at, previous_state, next_state, note)
-		SELECT e.id, m.new_id, e.kind, e.at, e.previous_state, e.next_state, e.note
+		INSERT INTO events_new (id, thought_id, kind, at, previous_state, next_state, note, settle_seconds)
+		SELECT e.id, m.new_id, e.kind, e.at, e.previous_state, e.next_state, e.note, e.settle_seconds
 		FROM events e
 		JOIN thought_id_map m ON m.old_id = e.thought_id
 		ORDER BY e.id;
@@ -1241,7 +685,21 @@ func (s *Store) ReindexThoughtIDs() error {
 		return fmt.Errorf("reindex thought ids: copy events: %w", err)
 	}
 
+	_, err = tx.Exec(`
+		INSERT INTO thought_tags_new (thought_id, tag_id, created_at)
+		SELECT m.new_id, tt.tag_id, tt.created_at
+		FROM thought_tags tt
+		JOIN thought_id_map m ON m.old_id = tt.thought_id;
+	`)
+	if err != nil {
+		return fmt.Errorf("reindex thought ids: copy thought_tags: %w", err)
+	}
+
 	// Drop old tables and swap in the new ones.
+	_, err = tx.Exec(`DROP TABLE thought_tags;`)
+	if err != nil {
+		return fmt.Errorf("reindex thought ids: drop thought_tags: %w", err)
+	}
 	_, err = tx.Exec(`DROP TABLE events;`)
 	if err != nil {
 		return fmt.Errorf("reindex thought ids: drop events: %w", err)
@@ -1259,6 +717,10 @@ func (s *Store) ReindexThoughtIDs() error {
 	if err != nil {
 		return fmt.Errorf("reindex thought ids: rename events: %w", err)
 	}
+	_, err = tx.Exec(`ALTER TABLE thought_tags_new RENAME TO thought_tags;`)
+	if err != nil {
+		return fmt.Errorf("reindex thought ids: rename thought_tags: %w", err)
+	}
 
 	// Recreate indexes.
 	_, err = tx.Exec(`CREATE INDEX IF NOT EXISTS idx_thoughts_state_eligibility ON thoughts(current_state, eligibility_at);`)
@@ -1269,6 +731,21 @@ func (s *Store) ReindexThoughtIDs() error {
 	if err != nil {
 		return fmt.Errorf("reindex thought ids: create idx_events_thought_id_at: %w", err)
 	}
+	_, err = tx.Exec(`CREATE UNIQUE INDEX IF NOT EXISTS idx_thoughts_public_id ON thoughts(public_id);`)
+	if err != nil {
+		return fmt.Errorf("reindex thought ids: create idx_thoughts_public_id: %w", err)
+	}
+	_, err = tx.Exec(`CREATE INDEX IF NOT EXISTS idx_thought_tags_tag_id ON thought_tags(tag_id, thought_id);`)
+	if err != nil {
+		return fmt.Errorf("reindex thought ids: create idx_thought_tags_tag_id: %w", err)
+	}
+	_, err = tx.Exec(`CREATE INDEX IF NOT EXISTS idx_thoughts_released_at ON thoughts(released_at);`)
+	if err != nil {
+		return fmt.Errorf("reindex thought ids: create idx_thoughts_released_at: %w", err)
+	}
+	if err := rebuildSearchIndex(tx); err != nil {
+		return fmt.Errorf("reindex thought ids: %w", err)
+	}
 
 	if err := tx.Commit(); err != nil {
 		return fmt.Errorf("reindex thought ids: commit: %w", err)
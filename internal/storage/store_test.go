@@ -2,15 +2,22 @@ package storage
 
 import (
 	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
 	"path/filepath"
+	"reflect"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/divijg19/peony/internal/core"
 )
 
-func openTestStore(t *testing.T) (*Store, *sql.DB) {
+func openTestStore(t testing.TB) (*Store, *sql.DB) {
 	t.Helper()
 	db, err := Open(filepath.Join(t.TempDir(), "peony.db"))
 	if err != nil {
@@ -43,7 +50,7 @@ func TestMigrateIsIdempotentAndCreatesExpectedTables(t *testing.T) {
 		t.Fatalf("second migrate: %v", err)
 	}
 
-	for _, table := range []string{"schema_migrations", "thoughts", "events", "app_state"} {
+	for _, table := range []string{"schema_migrations", "thoughts", "events", "app_state", "tags", "thought_tags", "thought_search"} {
 		var name string
 		err := db.QueryRow(`SELECT name FROM sqlite_master WHERE type = 'table' AND name = ?`, table).Scan(&name)
 		if err != nil {
@@ -60,6 +67,72 @@ func TestMigrateIsIdempotentAndCreatesExpectedTables(t *testing.T) {
 	}
 }
 
+func TestMigrateRecordsEveryStepAndRefusesNewerSchemas(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "peony.db")
+
+	status, err := ReadSchemaStatus(path)
+	if err != nil {
+		t.Fatalf("status before create: %v", err)
+	}
+	if status.Exists || status.Pending() != len(migrations) {
+		t.Fatalf("missing db status = %+v, want every step pending", status)
+	}
+
+	db, err := Open(path)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	for _, step := range migrations {
+		var version int
+		if err := db.QueryRow(`SELECT version FROM schema_migrations WHERE version = ?`, step.Version).Scan(&version); err != nil {
+			t.Fatalf("step %d not recorded: %v", step.Version, err)
+		}
+	}
+
+	// Rewind to the last step and check it alone is reported and re-applied.
+	last := migrations[len(migrations)-1].Version
+	if _, err := db.Exec(`DELETE FROM schema_migrations WHERE version = ?`, last); err != nil {
+		t.Fatalf("rewind: %v", err)
+	}
+	status, err = ReadSchemaStatus(path)
+	if err != nil {
+		t.Fatalf("status: %v", err)
+	}
+	if status.Pending() != 1 || status.Steps[len(status.Steps)-1].Applied {
+		t.Fatalf("rewound status = %+v, want only version %d pending", status, last)
+	}
+	if err := Migrate(db); err != nil {
+		t.Fatalf("re-migrate: %v", err)
+	}
+
+	// Every step checks for what it adds, so replaying all of them over a current schema is harmless.
+	if _, err := db.Exec(`DELETE FROM schema_migrations`); err != nil {
+		t.Fatalf("rewind all: %v", err)
+	}
+	if err := Migrate(db); err != nil {
+		t.Fatalf("replay every step: %v", err)
+	}
+
+	if _, err := db.Exec(`INSERT INTO schema_migrations(version) VALUES (?)`, SchemaVersion+1); err != nil {
+		t.Fatalf("record future version: %v", err)
+	}
+	_ = db.Close()
+
+	if _, err := Open(path); !errors.Is(err, ErrSchemaTooNew) {
+		t.Fatalf("open newer db error = %v, want ErrSchemaTooNew", err)
+	}
+	if _, err := OpenReadOnly(path); !errors.Is(err, ErrSchemaTooNew) {
+		t.Fatalf("read-only open newer db error = %v, want ErrSchemaTooNew", err)
+	}
+	status, err = ReadSchemaStatus(path)
+	if err != nil {
+		t.Fatalf("status of newer db: %v", err)
+	}
+	if !status.TooNew() || status.Current != SchemaVersion+1 {
+		t.Fatalf("newer status = %+v", status)
+	}
+}
+
 func TestListTendThoughtsHonorsEligibilityAndTerminalStates(t *testing.T) {
 	withStoreSettleDuration(t, time.Hour)
 	st, _ := openTestStore(t)
@@ -124,6 +197,217 @@ func TestStrictTendResolutionAndTerminalGuards(t *testing.T) {
 	}
 }
 
+func TestRestForSetsEligibilityAndRecordsDurationThroughReindex(t *testing.T) {
+	withStoreSettleDuration(t, 18*time.Hour)
+	st, _ := openTestStore(t)
+
+	releasedID, err := st.CreateThought("gone before the reindex")
+	if err != nil {
+		t.Fatalf("create released: %v", err)
+	}
+	weekID, err := st.CreateThought("needs a week")
+	if err != nil {
+		t.Fatalf("create week: %v", err)
+	}
+	defaultID, err := st.CreateThought("the usual rest")
+	if err != nil {
+		t.Fatalf("create default: %v", err)
+	}
+	for _, id := range []int64{weekID, defaultID} {
+		if err := st.MarkThoughtTended(id, nil); err != nil {
+			t.Fatalf("mark #%d tended: %v", id, err)
+		}
+	}
+	if err := st.TransitionPostTendResolutionFor(weekID, core.StateResting, nil, -time.Hour); err == nil {
+		t.Fatal("negative settle duration was accepted")
+	}
+
+	before := time.Now().UTC()
+	if err := st.TransitionPostTendResolutionFor(weekID, core.StateResting, nil, 7*24*time.Hour); err != nil {
+		t.Fatalf("rest for a week: %v", err)
+	}
+	if err := st.TransitionPostTendResolutionStrict(defaultID, core.StateResting, nil); err != nil {
+		t.Fatalf("rest for the default: %v", err)
+	}
+
+	if err := st.ReleaseThought(releasedID); err != nil {
+		t.Fatalf("release: %v", err)
+	}
+	if err := st.ReindexThoughtIDs(); err != nil {
+		t.Fatalf("reindex: %v", err)
+	}
+
+	for id, want := range map[int64]time.Duration{1: 7 * 24 * time.Hour, 2: 18 * time.Hour} {
+		thought, events, err := st.GetThought(id)
+		if err != nil {
+			t.Fatalf("get #%d: %v", id, err)
+		}
+		if got := thought.EligibilityAt.Sub(before); got < want || got > want+time.Minute {
+			t.Fatalf("#%d eligible %v after rest, want about %v", id, got, want)
+		}
+		last := events[len(events)-1]
+		if last.NextState == nil || *last.NextState != core.StateResting || last.SettleFor == nil || *last.SettleFor != want {
+			t.Fatalf("#%d rest event = %+v, want resting for %v", id, last, want)
+		}
+		for _, event := range events[:len(events)-1] {
+			if event.SettleFor != nil {
+				t.Fatalf("#%d %s event carries a settle duration", id, event.Kind)
+			}
+		}
+	}
+}
+
+func TestReviveReturnsArchivedAndEvolvedThoughtsToRest(t *testing.T) {
+	withStoreSettleDuration(t, 2*time.Hour)
+	st, _ := openTestStore(t)
+
+	archivedID, err := st.CreateThought("set aside")
+	if err != nil {
+		t.Fatalf("create archived: %v", err)
+	}
+	evolvedID, err := st.CreateThought("became a project")
+	if err != nil {
+		t.Fatalf("create evolved: %v", err)
+	}
+	if err := st.ToArchive(archivedID); err != nil {
+		t.Fatalf("archive: %v", err)
+	}
+	if err := st.ToEvolve(evolvedID); err != nil {
+		t.Fatalf("evolve: %v", err)
+	}
+	if err := st.MarkThoughtTended(archivedID, nil); err == nil {
+		t.Fatal("archived thought was tended before revive")
+	}
+
+	note := "spring again"
+	before := time.Now().UTC()
+	if err := st.ReviveThought(archivedID, &note, 0); err != nil {
+		t.Fatalf("revive archived: %v", err)
+	}
+	if err := st.ReviveThought(evolvedID, nil, 3*24*time.Hour); err != nil {
+		t.Fatalf("revive evolved: %v", err)
+	}
+	if err := st.ReviveThought(archivedID, nil, 0); err == nil || !strings.Contains(err.Error(), "only archived or evolved") {
+		t.Fatalf("revive resting error = %v, want state guard", err)
+	}
+
+	for id, want := range map[int64]struct {
+		prev   core.State
+		settle time.Duration
+	}{archivedID: {core.StateArchived, 2 * time.Hour}, evolvedID: {core.StateEvolved, 3 * 24 * time.Hour}} {
+		thought, events, err := st.GetThought(id)
+		if err != nil {
+			t.Fatalf("get #%d: %v", id, err)
+		}
+		if thought.CurrentState != core.StateResting {
+			t.Fatalf("#%d state = %s, want resting", id, thought.CurrentState)
+		}
+		if got := thought.EligibilityAt.Sub(before); got < want.settle || got > want.settle+time.Minute {
+			t.Fatalf("#%d eligible %v after revive, want about %v", id, got, want.settle)
+		}
+		if len(events) != 2 || *events[0].NextState != want.prev {
+			t.Fatalf("#%d history = %+v, want %s then revived", id, events, want.prev)
+		}
+		revived := events[1]
+		if revived.Kind != "revived" || *revived.PreviousState != want.prev || *revived.NextState != core.StateResting || *revived.SettleFor != want.settle {
+			t.Fatalf("#%d revived event = %+v", id, revived)
+		}
+	}
+
+	if err := st.MarkThoughtTended(archivedID, nil); err != nil {
+		t.Fatalf("tend after revive: %v", err)
+	}
+}
+
+func TestSoftReleaseRestoreAndPurge(t *testing.T) {
+	st, _ := openTestStore(t)
+
+	keptID, err := st.CreateThought("kept")
+	if err != nil {
+		t.Fatalf("create kept: %v", err)
+	}
+	archivedID, err := st.CreateThought("set aside, then let go")
+	if err != nil {
+		t.Fatalf("create archived: %v", err)
+	}
+	goneID, err := st.CreateThought("let go for good")
+	if err != nil {
+		t.Fatalf("create gone: %v", err)
+	}
+	if err := st.ToArchive(archivedID); err != nil {
+		t.Fatalf("archive: %v", err)
+	}
+
+	before := time.Now().UTC()
+	note := "not now"
+	if err := st.SoftReleaseThought(archivedID, &note); err != nil {
+		t.Fatalf("release archived: %v", err)
+	}
+	if err := st.SoftReleaseThought(goneID, nil); err != nil {
+		t.Fatalf("release gone: %v", err)
+	}
+	if err := st.SoftReleaseThought(goneID, nil); err == nil {
+		t.Fatal("releasing a released thought succeeded")
+	}
+
+	thought, events, err := st.GetThought(archivedID)
+	if err != nil {
+		t.Fatalf("get released: %v", err)
+	}
+	if thought.CurrentState != core.StateReleased || thought.ReleasedAt == nil || thought.ReleasedAt.Before(before) {
+		t.Fatalf("released thought = %+v, want released with released_at", thought)
+	}
+	if last := events[len(events)-1]; *last.PreviousState != core.StateArchived || *last.NextState != core.StateReleased || *last.Note != note {
+		t.Fatalf("release event = %+v", last)
+	}
+
+	listed, err := st.ListThoughtsByPagination(10, 0)
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(listed) != 1 || listed[0].ID != keptID {
+		t.Fatalf("listed = %+v, want only the kept thought", listed)
+	}
+	trash, err := st.ListReleasedThoughts()
+	if err != nil {
+		t.Fatalf("list released: %v", err)
+	}
+	if len(trash) != 2 || trash[0].ID != archivedID || trash[1].ID != goneID {
+		t.Fatalf("trash = %+v, want archived then gone", trash)
+	}
+
+	state, err := st.RestoreThought(archivedID)
+	if err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	if state != core.StateArchived {
+		t.Fatalf("restored state = %s, want archived", state)
+	}
+	thought, events, err = st.GetThought(archivedID)
+	if err != nil {
+		t.Fatalf("get restored: %v", err)
+	}
+	if thought.CurrentState != core.StateArchived || thought.ReleasedAt != nil || events[len(events)-1].Kind != "restored" {
+		t.Fatalf("restored thought = %+v, last event %+v", thought, events[len(events)-1])
+	}
+	if _, err := st.RestoreThought(keptID); err == nil || !strings.Contains(err.Error(), "only released thoughts") {
+		t.Fatalf("restore captured error = %v, want state guard", err)
+	}
+
+	if n, err := st.PurgeReleased(before.Add(-time.Hour)); err != nil || n != 0 {
+		t.Fatalf("purge before release = %d, %v; want 0", n, err)
+	}
+	if n, err := st.PurgeReleased(time.Now().UTC()); err != nil || n != 1 {
+		t.Fatalf("purge after release = %d, %v; want 1", n, err)
+	}
+	if _, _, err := st.GetThought(goneID); err == nil {
+		t.Fatal("purged thought still found")
+	}
+	if _, _, err := st.GetThought(keptID); err != nil {
+		t.Fatalf("kept thought after purge: %v", err)
+	}
+}
+
 func TestReleaseAndReindexPreservesRemainingEvents(t *testing.T) {
 	withStoreSettleDuration(t, 0)
 	st, _ := openTestStore(t)
@@ -166,16 +450,1168 @@ func TestReleaseAndReindexPreservesRemainingEvents(t *testing.T) {
 	}
 }
 
-func TestDidCountTendChangePersistsOnlyChanges(t *testing.T) {
+func TestPublicIDsSurviveReindexAndResolve(t *testing.T) {
+	st, db := openTestStore(t)
+
+	firstID, err := st.CreateThought("first")
+	if err != nil {
+		t.Fatalf("create first: %v", err)
+	}
+	secondID, err := st.CreateThought("second")
+	if err != nil {
+		t.Fatalf("create second: %v", err)
+	}
+	second, _, err := st.GetThought(secondID)
+	if err != nil {
+		t.Fatalf("get second: %v", err)
+	}
+	if !core.IsPublicID(second.PublicID) {
+		t.Fatalf("public id = %q, want stable ref", second.PublicID)
+	}
+
+	if err := st.ReleaseThought(firstID); err != nil {
+		t.Fatalf("release first: %v", err)
+	}
+	if err := st.ReindexThoughtIDs(); err != nil {
+		t.Fatalf("reindex: %v", err)
+	}
+
+	for _, ref := range []string{second.PublicID, "#" + strings.ToUpper(second.PublicID), "1"} {
+		id, err := st.ResolveThoughtID(ref)
+		if err != nil {
+			t.Fatalf("resolve %q: %v", ref, err)
+		}
+		if id != 1 {
+			t.Fatalf("resolve %q = %d, want reindexed 1", ref, id)
+		}
+	}
+	if _, err := st.ResolveThoughtID("zffffffffff"); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Fatalf("unknown ref error = %v, want not found", err)
+	}
+	if _, err := st.ResolveThoughtID("later"); err == nil || !strings.Contains(err.Error(), "invalid") {
+		t.Fatalf("invalid ref error = %v, want invalid", err)
+	}
+
+	var unique int
+	if err := db.QueryRow(`SELECT "unique" FROM pragma_index_list('thoughts') WHERE name = 'idx_thoughts_public_id'`).Scan(&unique); err != nil {
+		t.Fatalf("public id index missing after reindex: %v", err)
+	}
+	if unique != 1 {
+		t.Fatal("public id index should be unique")
+	}
+}
+
+func TestMigrateBackfillsPublicIDs(t *testing.T) {
+	st, db := openTestStore(t)
+
+	id, err := st.CreateThought("from before refs")
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if _, err := db.Exec(`DROP INDEX idx_thoughts_public_id`); err != nil {
+		t.Fatalf("drop index: %v", err)
+	}
+	if _, err := db.Exec(`UPDATE thoughts SET public_id = NULL`); err != nil {
+		t.Fatalf("clear public ids: %v", err)
+	}
+	if _, err := db.Exec(`DELETE FROM schema_migrations WHERE version >= 3`); err != nil {
+		t.Fatalf("rewind schema version: %v", err)
+	}
+	if _, err := db.Exec(`ALTER TABLE thoughts DROP COLUMN public_id`); err != nil {
+		t.Fatalf("drop public id column: %v", err)
+	}
+
+	if err := Migrate(db); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	thought, _, err := st.GetThought(id)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if !core.IsPublicID(thought.PublicID) {
+		t.Fatalf("backfilled public id = %q", thought.PublicID)
+	}
+}
+
+func TestThoughtTagsAddRemoveAndSurviveReindex(t *testing.T) {
 	st, _ := openTestStore(t)
 
-	if !st.DidCountTendChange(2) {
-		t.Fatal("first count should be treated as a change")
+	firstID, err := st.CreateThought("first")
+	if err != nil {
+		t.Fatalf("create first: %v", err)
 	}
-	if st.DidCountTendChange(2) {
-		t.Fatal("same count should not be treated as a change")
+	secondID, err := st.CreateThought("second")
+	if err != nil {
+		t.Fatalf("create second: %v", err)
 	}
-	if !st.DidCountTendChange(3) {
-		t.Fatal("new count should be treated as a change")
+
+	tags, err := st.UpdateThoughtTags(secondID, []string{"#Work", "later", "work"}, nil)
+	if err != nil {
+		t.Fatalf("add tags: %v", err)
+	}
+	if strings.Join(tags, ",") != "later,work" {
+		t.Fatalf("tags = %v, want [later work]", tags)
+	}
+	tags, err = st.UpdateThoughtTags(secondID, nil, []string{"later"})
+	if err != nil {
+		t.Fatalf("remove tag: %v", err)
+	}
+	if strings.Join(tags, ",") != "work" {
+		t.Fatalf("tags after removal = %v, want [work]", tags)
+	}
+	if _, err := st.UpdateThoughtTags(firstID, []string{"work"}, nil); err != nil {
+		t.Fatalf("tag first: %v", err)
+	}
+	if _, err := st.UpdateThoughtTags(secondID, []string{"9lives"}, nil); err == nil {
+		t.Fatal("tags must start with a letter")
+	}
+	if _, err := st.UpdateThoughtTags(99, []string{"work"}, nil); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Fatalf("missing thought error = %v, want not found", err)
+	}
+
+	counts, err := st.ListTags()
+	if err != nil {
+		t.Fatalf("list tags: %v", err)
+	}
+	if len(counts) != 1 || counts[0].Name != "work" || counts[0].Count != 2 {
+		t.Fatalf("tag counts = %+v, want work:2 (later pruned)", counts)
+	}
+
+	if err := st.ReleaseThought(firstID); err != nil {
+		t.Fatalf("release first: %v", err)
+	}
+	if err := st.ReindexThoughtIDs(); err != nil {
+		t.Fatalf("reindex: %v", err)
+	}
+
+	thought, events, err := st.GetThought(1)
+	if err != nil {
+		t.Fatalf("get reindexed: %v", err)
+	}
+	if thought.Content != "second" || strings.Join(thought.Tags, ",") != "work" {
+		t.Fatalf("reindexed thought = %q tags %v, want second with [work]", thought.Content, thought.Tags)
+	}
+	tagged := 0
+	for _, event := range events {
+		if event.Kind == "tagged" {
+			tagged++
+		}
+	}
+	if tagged != 2 {
+		t.Fatalf("tagged events = %d, want 2", tagged)
+	}
+
+	thoughts, err := st.FilterViewByTagPagination(10, 0, "#WORK")
+	if err != nil {
+		t.Fatalf("filter by tag: %v", err)
+	}
+	if len(thoughts) != 1 || thoughts[0].ID != 1 {
+		t.Fatalf("tagged thoughts = %+v, want only #1", thoughts)
+	}
+}
+
+func TestSearchIndexFollowsContentNotesReleaseAndReindex(t *testing.T) {
+	st, _ := openTestStore(t)
+
+	cabinID, err := st.CreateThought("build a log cabin by the lake")
+	if err != nil {
+		t.Fatalf("create cabin: %v", err)
+	}
+	treeID, err := st.CreateThought("a treehouse for the garden")
+	if err != nil {
+		t.Fatalf("create treehouse: %v", err)
+	}
+	goneID, err := st.CreateThought("a cabin I will let go")
+	if err != nil {
+		t.Fatalf("create gone: %v", err)
+	}
+	note := "ask about [timber] prices"
+	if err := st.AppendEvent(treeID, "state_change", nil, nil, &note); err != nil {
+		t.Fatalf("append note: %v", err)
+	}
+
+	search := func(query string) []int64 {
+		t.Helper()
+		hits, err := st.SearchThoughts(query, 10)
+		if err != nil {
+			t.Fatalf("search %q: %v", query, err)
+		}
+		ids := make([]int64, 0, len(hits))
+		for _, hit := range hits {
+			ids = append(ids, hit.ID)
+		}
+		return ids
+	}
+
+	if ids := search("cabin"); len(ids) != 2 {
+		t.Fatalf("cabin hits = %v, want two", ids)
+	}
+	if ids := search(`"log cabin"`); len(ids) != 1 || ids[0] != cabinID {
+		t.Fatalf("phrase hits = %v, want [%d]", ids, cabinID)
+	}
+	if ids := search("tree*"); len(ids) != 1 || ids[0] != treeID {
+		t.Fatalf("prefix hits = %v, want [%d]", ids, treeID)
+	}
+	if ids := search("cabin NOT lake"); len(ids) != 1 || ids[0] != goneID {
+		t.Fatalf("NOT hits = %v, want [%d]", ids, goneID)
+	}
+	if ids := search("lake OR treehouse"); len(ids) != 2 {
+		t.Fatalf("OR hits = %v, want two", ids)
+	}
+
+	hits, err := st.SearchThoughts("timber", 10)
+	if err != nil {
+		t.Fatalf("search note: %v", err)
+	}
+	if len(hits) != 1 || hits[0].ID != treeID || !hits[0].FromNote || !strings.Contains(hits[0].Snippet, "[timber]") {
+		t.Fatalf("note hits = %+v, want treehouse snippet from note", hits)
+	}
+	if _, err := st.SearchThoughts(" ( AND ", 10); !errors.Is(err, ErrInvalidSearch) {
+		t.Fatalf("empty query error = %v, want ErrInvalidSearch", err)
+	}
+
+	if err := st.UpdateThoughtContent(cabinID, "build a stone cottage by the lake"); err != nil {
+		t.Fatalf("update content: %v", err)
+	}
+	if ids := search("cottage"); len(ids) != 1 || ids[0] != cabinID {
+		t.Fatalf("updated content hits = %v, want [%d]", ids, cabinID)
+	}
+
+	if err := st.ReleaseThought(goneID); err != nil {
+		t.Fatalf("release: %v", err)
+	}
+	if ids := search("cabin"); len(ids) != 0 {
+		t.Fatalf("released thought still found: %v", ids)
+	}
+
+	if err := st.ReleaseThought(cabinID); err != nil {
+		t.Fatalf("release cabin: %v", err)
+	}
+	if err := st.ReindexThoughtIDs(); err != nil {
+		t.Fatalf("reindex: %v", err)
+	}
+	if ids := search("timber"); len(ids) != 1 || ids[0] != 1 {
+		t.Fatalf("note hits after reindex = %v, want [1]", ids)
+	}
+	if _, err := st.CreateThought("a cabin after reindexing"); err != nil {
+		t.Fatalf("create after reindex: %v", err)
+	}
+	if ids := search("cabin"); len(ids) != 1 || ids[0] != 2 {
+		t.Fatalf("hits after reindex = %v, want [2]; triggers must be recreated", ids)
+	}
+}
+
+func TestSearchLeavesOutThoughtsInTheTrash(t *testing.T) {
+	st, _ := openTestStore(t)
+
+	pieID, err := st.CreateThought("apple pie recipe")
+	if err != nil {
+		t.Fatalf("create pie: %v", err)
+	}
+	treeID, err := st.CreateThought("plant an apple tree")
+	if err != nil {
+		t.Fatalf("create tree: %v", err)
+	}
+	search := func() []int64 {
+		t.Helper()
+		hits, err := st.SearchThoughts("apple", 10)
+		if err != nil {
+			t.Fatalf("search: %v", err)
+		}
+		ids := []int64{}
+		for _, hit := range hits {
+			ids = append(ids, hit.ID)
+		}
+		return ids
+	}
+
+	if err := st.SoftReleaseThought(pieID, nil); err != nil {
+		t.Fatalf("release: %v", err)
+	}
+	if ids := search(); !reflect.DeepEqual(ids, []int64{treeID}) {
+		t.Fatalf("hits with #%d in the trash = %v, want [%d]", pieID, ids, treeID)
+	}
+
+	if _, err := st.RestoreThought(pieID); err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	if ids := search(); len(ids) != 2 {
+		t.Fatalf("hits after restore = %v, want both thoughts", ids)
+	}
+}
+
+func TestMatchQueryQuotesTermsAndDropsDanglingOperators(t *testing.T) {
+	tests := []struct {
+		query  string
+		prefix bool
+		want   string
+	}{
+		{query: "log cabin", want: `"log" AND "cabin"`},
+		{query: "log cabin", prefix: true, want: `"log"* AND "cabin"*`},
+		{query: `"log cabin" OR tree*`, want: `"log cabin" OR "tree"*`},
+		{query: `"log cab"*`, want: `"log cab"*`},
+		{query: "cabin NOT (lake OR river)", want: `"cabin" NOT ( "lake" OR "river" )`},
+		{query: "c++ don't", want: `"c++" AND "don't"`},
+		{query: "AND cabin OR", want: `"cabin"`},
+		{query: "cabin () (lake", want: `"cabin" AND ( "lake" )`},
+		{query: `say "hi`, want: `"say" AND "hi"`},
+		{query: "and or", want: `"and" AND "or"`},
+		{query: " - * ", want: ""},
+	}
+	for _, tt := range tests {
+		if got := MatchQuery(tt.query, tt.prefix); got != tt.want {
+			t.Errorf("MatchQuery(%q, %v) = %s, want %s", tt.query, tt.prefix, got, tt.want)
+		}
+	}
+}
+
+func TestSetThoughtFeelingRecordsOnlyChanges(t *testing.T) {
+	st, _ := openTestStore(t)
+
+	id, err := st.CreateThought("a heavy afternoon")
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	valence, energy := -1, 2
+
+	changed, err := st.SetThoughtFeeling(id, &valence, &energy)
+	if err != nil || !changed {
+		t.Fatalf("first feeling changed = %v, err = %v; want true", changed, err)
+	}
+	changed, err = st.SetThoughtFeeling(id, &valence, &energy)
+	if err != nil || changed {
+		t.Fatalf("same feeling changed = %v, err = %v; want false", changed, err)
+	}
+	changed, err = st.SetThoughtFeeling(id, nil, &energy)
+	if err != nil || !changed {
+		t.Fatalf("cleared valence changed = %v, err = %v; want true", changed, err)
+	}
+
+	tooHigh := 6
+	if _, err := st.SetThoughtFeeling(id, nil, &tooHigh); err == nil || !strings.Contains(err.Error(), "outside 1..5") {
+		t.Fatalf("energy 6 error = %v, want out of range", err)
+	}
+	if _, err := st.SetThoughtFeeling(99, nil, nil); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Fatalf("missing thought error = %v, want not found", err)
+	}
+
+	thought, events, err := st.GetThought(id)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if thought.Valence != nil || thought.Energy == nil || *thought.Energy != 2 {
+		t.Fatalf("feeling = %v/%v, want none/2", thought.Valence, thought.Energy)
+	}
+	var notes []string
+	for _, event := range events {
+		if event.Kind == "feel" {
+			notes = append(notes, *event.Note)
+		}
+	}
+	if strings.Join(notes, "|") != "valence -1, energy 2|valence none, energy 2" {
+		t.Fatalf("feel events = %q", notes)
+	}
+}
+
+func TestImportThoughtsRemapsIDsSkipsDuplicatesAndDryRuns(t *testing.T) {
+	st, db := openTestStore(t)
+
+	existingID, err := st.CreateThought("already here")
+	if err != nil {
+		t.Fatalf("create existing: %v", err)
+	}
+	existing, _, err := st.GetThought(existingID)
+	if err != nil {
+		t.Fatalf("get existing: %v", err)
+	}
+
+	created := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	note := "carried over"
+	resting := core.StateResting
+	captured := core.StateCaptured
+	records := []ImportRecord{
+		{
+			Thought: core.Thought{ID: 40, PublicID: "bcafe000001", Content: "from another machine", CurrentState: core.StateResting, TendCounter: 2, CreatedAt: created, UpdatedAt: created, EligibilityAt: created, Tags: []string{"travel"}},
+			Events: []core.Event{
+				{Kind: "captured", At: created, NextState: &captured},
+				{Kind: "state_change", At: created.Add(time.Hour), PreviousState: &captured, NextState: &resting, Note: &note},
+			},
+		},
+		{
+			Thought: core.Thought{ID: 41, PublicID: existing.PublicID, Content: existing.Content, CurrentState: core.StateCaptured, CreatedAt: existing.CreatedAt, UpdatedAt: existing.UpdatedAt, EligibilityAt: existing.EligibilityAt},
+		},
+	}
+
+	dry, err := st.ImportThoughts(records, ImportOptions{SkipDuplicates: true, DryRun: true})
+	if err != nil {
+		t.Fatalf("dry run: %v", err)
+	}
+	if !dry.DryRun || len(dry.Imported) != 1 || len(dry.Skipped) != 1 || dry.Skipped[0] != 41 || dry.Events != 2 {
+		t.Fatalf("dry run result = %+v", dry)
+	}
+	var count int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM thoughts`).Scan(&count); err != nil {
+		t.Fatalf("count: %v", err)
+	}
+	if count != 1 {
+		t.Fatalf("dry run wrote thoughts: count = %d, want 1", count)
+	}
+
+	result, err := st.ImportThoughts(records, ImportOptions{SkipDuplicates: true})
+	if err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	if len(result.Imported) != 1 || result.Imported[0].SourceID != 40 || result.Imported[0].ID != existingID+1 {
+		t.Fatalf("import result = %+v", result)
+	}
+	thought, events, err := st.GetThought(result.Imported[0].ID)
+	if err != nil {
+		t.Fatalf("get imported: %v", err)
+	}
+	if thought.PublicID != "bcafe000001" || thought.CurrentState != core.StateResting || thought.TendCounter != 2 || !thought.CreatedAt.Equal(created) {
+		t.Fatalf("imported thought = %+v", thought)
+	}
+	if len(thought.Tags) != 1 || thought.Tags[0] != "travel" {
+		t.Fatalf("imported tags = %v", thought.Tags)
+	}
+	if len(events) != 2 || events[1].Note == nil || *events[1].Note != note || events[1].ThoughtID != thought.ID {
+		t.Fatalf("imported events = %+v", events)
+	}
+
+	// Without skipping, the duplicate comes in with a fresh ref because its ref is taken.
+	result, err = st.ImportThoughts(records[1:], ImportOptions{})
+	if err != nil {
+		t.Fatalf("import duplicate: %v", err)
+	}
+	if len(result.Imported) != 1 || result.Imported[0].PublicID == existing.PublicID || !core.IsPublicID(result.Imported[0].PublicID) {
+		t.Fatalf("duplicate import result = %+v", result)
+	}
+
+	bad := []ImportRecord{{Thought: core.Thought{Content: "bad", CurrentState: "wilting", CreatedAt: created, UpdatedAt: created, EligibilityAt: created}}}
+	if _, err := st.ImportThoughts(bad, ImportOptions{}); err == nil || !strings.Contains(err.Error(), "unknown state") {
+		t.Fatalf("bad state import error = %v", err)
+	}
+
+	loud := 9
+	bad = []ImportRecord{{Thought: core.Thought{Content: "too loud", CurrentState: core.StateCaptured, CreatedAt: created, UpdatedAt: created, EligibilityAt: created, Valence: &loud}}}
+	if _, err := st.ImportThoughts(bad, ImportOptions{}); err == nil || !strings.Contains(err.Error(), "valence 9 is outside") {
+		t.Fatalf("bad valence import error = %v", err)
+	}
+}
+
+func TestDidCountTendChangePersistsOnlyChanges(t *testing.T) {
+	st, _ := openTestStore(t)
+
+	if !st.DidCountTendChange(2) {
+		t.Fatal("first count should be treated as a change")
+	}
+	if st.DidCountTendChange(2) {
+		t.Fatal("same count should not be treated as a change")
+	}
+	if !st.DidCountTendChange(3) {
+		t.Fatal("new count should be treated as a change")
+	}
+}
+
+func TestBackupRestoreSwapsGardensAndRefusesBadBackups(t *testing.T) {
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "peony.db")
+	db, err := Open(dbPath)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	st, err := New(db)
+	if err != nil {
+		t.Fatalf("new store: %v", err)
+	}
+	if _, err := st.CreateThought("kept in the backup"); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	backup := filepath.Join(dir, "snapshot.db")
+	if err := st.Backup(backup); err != nil {
+		t.Fatalf("backup: %v", err)
+	}
+	if err := st.Backup(backup); !errors.Is(err, fs.ErrExist) {
+		t.Fatalf("backup over an existing file error = %v, want fs.ErrExist", err)
+	}
+	if _, err := st.CreateThought("written after the backup"); err != nil {
+		t.Fatalf("create after backup: %v", err)
+	}
+	_ = db.Close()
+
+	notADB := filepath.Join(dir, "notes.db")
+	if err := os.WriteFile(notADB, []byte("just some notes, not a database at all"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if _, err := RestoreBackup(notADB, dbPath); err == nil {
+		t.Fatal("restoring a file that is not a database succeeded")
+	}
+
+	future := filepath.Join(dir, "future.db")
+	futureDB, err := Open(future)
+	if err != nil {
+		t.Fatalf("open future: %v", err)
+	}
+	if _, err := futureDB.Exec(`INSERT INTO schema_migrations(version) VALUES (?)`, SchemaVersion+1); err != nil {
+		t.Fatalf("record future version: %v", err)
+	}
+	_ = futureDB.Close()
+	if _, err := RestoreBackup(future, dbPath); !errors.Is(err, ErrSchemaTooNew) {
+		t.Fatalf("restoring a newer backup error = %v, want ErrSchemaTooNew", err)
+	}
+
+	open, err := Open(dbPath)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	if _, err := RestoreBackup(backup, dbPath); !errors.Is(err, ErrGardenInUse) {
+		t.Fatalf("restoring over an open garden error = %v, want ErrGardenInUse", err)
+	}
+	var count int
+	if err := open.QueryRow(`SELECT COUNT(*) FROM thoughts`).Scan(&count); err != nil || count != 2 {
+		t.Fatalf("open garden after a refused restore has %d thoughts (%v), want 2", count, err)
+	}
+	_ = open.Close()
+
+	result, err := RestoreBackup(backup, dbPath)
+	if err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	if result.Schema != SchemaVersion || filepath.Dir(result.Previous) != BackupDir(dbPath) {
+		t.Fatalf("restore result = %+v", result)
+	}
+
+	for path, want := range map[string]int{dbPath: 1, result.Previous: 2} {
+		db, err := Open(path)
+		if err != nil {
+			t.Fatalf("open %s: %v", path, err)
+		}
+		var count int
+		if err := db.QueryRow(`SELECT COUNT(*) FROM thoughts`).Scan(&count); err != nil {
+			t.Fatalf("count %s: %v", path, err)
+		}
+		_ = db.Close()
+		if count != want {
+			t.Fatalf("%s has %d thoughts, want %d", path, count, want)
+		}
+	}
+}
+
+func TestMigrateAndReindexKeepRotatingBackups(t *testing.T) {
+	st, db := openTestStore(t)
+	dbPath, err := databaseFile(db)
+	if err != nil {
+		t.Fatalf("database file: %v", err)
+	}
+	automatic := func() []string {
+		t.Helper()
+		paths, err := filepath.Glob(filepath.Join(BackupDir(dbPath), "auto-*.db"))
+		if err != nil {
+			t.Fatalf("glob: %v", err)
+		}
+		return paths
+	}
+	if got := automatic(); len(got) != 0 {
+		t.Fatalf("creating a garden took backups: %q", got)
+	}
+
+	if _, err := st.CreateThought("before the migration"); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if _, err := db.Exec(`DELETE FROM schema_migrations WHERE version = ?`, SchemaVersion); err != nil {
+		t.Fatalf("rewind: %v", err)
+	}
+	if err := Migrate(db); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	if got := automatic(); len(got) != 1 || !strings.HasSuffix(got[0], "-migrate.db") {
+		t.Fatalf("backups after migrating = %q, want one migrate backup", got)
+	}
+	if err := Migrate(db); err != nil {
+		t.Fatalf("migrate again: %v", err)
+	}
+	if got := automatic(); len(got) != 1 {
+		t.Fatalf("migrating with nothing pending took a backup: %q", got)
+	}
+
+	for i := 0; i < keepAutomaticBackups+2; i++ {
+		if err := st.ReindexThoughtIDs(); err != nil {
+			t.Fatalf("reindex %d: %v", i, err)
+		}
+	}
+	got := automatic()
+	if len(got) != keepAutomaticBackups {
+		t.Fatalf("kept %d automatic backups, want %d: %q", len(got), keepAutomaticBackups, got)
+	}
+	for _, path := range got {
+		if !strings.HasSuffix(path, "-reindex.db") {
+			t.Fatalf("oldest backups were not pruned first: %q", got)
+		}
+	}
+}
+
+func TestListThoughtsWithEventsMatchesGetThought(t *testing.T) {
+	withStoreSettleDuration(t, 0)
+	st, _ := openTestStore(t)
+
+	note := "kept for later"
+	ids := make([]int64, 0, 5)
+	for i := 0; i < 5; i++ {
+		id, err := st.CreateThought(fmt.Sprintf("thought %d", i))
+		if err != nil {
+			t.Fatalf("create %d: %v", i, err)
+		}
+		ids = append(ids, id)
+	}
+	if _, err := st.UpdateThoughtTags(ids[0], []string{"work", "later"}, nil); err != nil {
+		t.Fatalf("tag: %v", err)
+	}
+	if err := st.MarkThoughtTended(ids[1], &note); err != nil {
+		t.Fatalf("tend: %v", err)
+	}
+	if err := st.TransitionPostTendResolutionFor(ids[1], core.StateResting, &note, time.Hour); err != nil {
+		t.Fatalf("rest: %v", err)
+	}
+	if err := st.ToArchiveWithNote(ids[2], &note); err != nil {
+		t.Fatalf("archive: %v", err)
+	}
+	if err := st.SoftReleaseThought(ids[3], nil); err != nil {
+		t.Fatalf("release: %v", err)
+	}
+	valence, energy := -1, 2
+	if _, err := st.SetThoughtFeeling(ids[4], &valence, &energy); err != nil {
+		t.Fatalf("feel: %v", err)
+	}
+
+	all, err := st.ListThoughtsByPagination(100, 0)
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	bloom, err := st.ListBloomThoughtsByPagination(100, 0)
+	if err != nil {
+		t.Fatalf("list bloom: %v", err)
+	}
+	if len(all) != 4 || len(bloom) != 3 {
+		t.Fatalf("listed %d thoughts and %d in bloom, want 4 and 3", len(all), len(bloom))
+	}
+
+	for _, tc := range []struct {
+		exclude []core.State
+		want    []core.Thought
+	}{
+		{[]core.State{core.StateReleased}, all},
+		{[]core.State{core.StateArchived, core.StateReleased}, bloom},
+	} {
+		items, err := st.ListThoughtsWithEvents(tc.exclude...)
+		if err != nil {
+			t.Fatalf("list excluding %v: %v", tc.exclude, err)
+		}
+		if len(items) != len(tc.want) {
+			t.Fatalf("excluding %v listed %d thoughts, want %d", tc.exclude, len(items), len(tc.want))
+		}
+		for i, item := range items {
+			if item.Thought.ID != tc.want[i].ID {
+				t.Fatalf("excluding %v: thought %d is #%d, want #%d", tc.exclude, i, item.Thought.ID, tc.want[i].ID)
+			}
+			thought, events, err := st.GetThought(item.Thought.ID)
+			if err != nil {
+				t.Fatalf("get %d: %v", item.Thought.ID, err)
+			}
+			if !reflect.DeepEqual(item.Thought, thought) || !reflect.DeepEqual(item.Events, events) {
+				t.Fatalf("thought %d differs from GetThought:\n%+v %+v\n%+v %+v", thought.ID, item.Thought, item.Events, thought, events)
+			}
+		}
+	}
+
+	items, err := st.ListThoughtsWithEvents()
+	if err != nil || len(items) != len(ids) {
+		t.Fatalf("list everything = %d thoughts, %v; want %d", len(items), err, len(ids))
+	}
+
+	empty, _ := openTestStore(t)
+	items, err = empty.ListThoughtsWithEvents()
+	if err != nil || items == nil || len(items) != 0 {
+		t.Fatalf("empty garden = %v, %v", items, err)
+	}
+}
+
+func TestQueryThoughtsCombinesFilters(t *testing.T) {
+	st, _ := openTestStore(t)
+
+	day := func(n int) time.Time { return time.Date(2026, 1, n, 9, 0, 0, 0, time.UTC) }
+	note := "the river at dusk"
+	records := []ImportRecord{
+		{Thought: core.Thought{Content: "walk by the water", CurrentState: core.StateResting, TendCounter: 4, CreatedAt: day(1), UpdatedAt: day(5), EligibilityAt: day(6), Tags: []string{"outside", "later"}}},
+		{Thought: core.Thought{Content: "fix the bike", CurrentState: core.StateCaptured, CreatedAt: day(2), UpdatedAt: day(3), EligibilityAt: day(2), Tags: []string{"outside"}}},
+		{Thought: core.Thought{Content: "letters to answer", CurrentState: core.StateArchived, CreatedAt: day(3), UpdatedAt: day(4), EligibilityAt: day(3)},
+			Events: []core.Event{{Kind: "tended", At: day(4), Note: &note}}},
+		{Thought: core.Thought{Content: "old plan", CurrentState: core.StateReleased, CreatedAt: day(4), UpdatedAt: day(7), EligibilityAt: day(4), Tags: []string{"later"}}},
+	}
+	result, err := st.ImportThoughts(records, ImportOptions{})
+	if err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	id := func(i int) int64 { return result.Imported[i].ID }
+
+	for _, tc := range []struct {
+		name  string
+		query ThoughtQuery
+		want  []int64
+	}{
+		{"everything", ThoughtQuery{}, []int64{id(0), id(1), id(2), id(3)}},
+		{"states", ThoughtQuery{States: []core.State{core.StateResting, core.StateCaptured}}, []int64{id(0), id(1)}},
+		{"exclude", ThoughtQuery{Exclude: []core.State{core.StateReleased}, Order: OrderByUpdated}, []int64{id(1), id(2), id(0)}},
+		{"every tag", ThoughtQuery{Tags: []string{"outside", "#Later"}}, []int64{id(0)}},
+		{"text in notes", ThoughtQuery{Text: "river"}, []int64{id(2)}},
+		{"created range", ThoughtQuery{Created: TimeRange{From: day(2), To: day(3)}}, []int64{id(1), id(2)}},
+		{"updated since", ThoughtQuery{Updated: TimeRange{From: day(5)}, Order: OrderByCreated}, []int64{id(0), id(3)}},
+		{"eligible by", ThoughtQuery{Eligible: TimeRange{To: day(3)}, Order: OrderByEligibility}, []int64{id(1), id(2)}},
+		{"tended at least", ThoughtQuery{MinTends: 4}, []int64{id(0)}},
+		{"page", ThoughtQuery{Order: OrderByUpdated, Limit: 2, Offset: 1}, []int64{id(2), id(0)}},
+		{"after position", ThoughtQuery{Order: OrderByUpdated, After: &Position{Key: OrderKey(core.Thought{UpdatedAt: day(4)}, OrderByUpdated), ID: id(2)}}, []int64{id(0), id(3)}},
+		{"after id", ThoughtQuery{After: &Position{ID: id(1)}, Limit: 1}, []int64{id(2)}},
+	} {
+		thoughts, err := st.QueryThoughts(tc.query)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		got := make([]int64, 0, len(thoughts))
+		for _, thought := range thoughts {
+			got = append(got, thought.ID)
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Fatalf("%s = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+
+	withEvents, err := st.QueryThoughtsWithEvents(ThoughtQuery{IDs: []int64{id(0), id(2)}})
+	if err != nil {
+		t.Fatalf("with events: %v", err)
+	}
+	if len(withEvents) != 2 || !reflect.DeepEqual(withEvents[0].Thought.Tags, []string{"later", "outside"}) || len(withEvents[1].Events) != 1 {
+		t.Fatalf("with events = %+v", withEvents)
+	}
+
+	for _, bad := range []ThoughtQuery{
+		{Order: "sideways"},
+		{After: &Position{ID: 1}, Offset: 2},
+		{Limit: -1},
+		{Tags: []string{"not a tag"}},
+	} {
+		if _, err := st.QueryThoughts(bad); err == nil {
+			t.Fatalf("query %+v did not fail", bad)
+		}
+	}
+	if _, err := st.QueryThoughts(ThoughtQuery{Text: "AND"}); !errors.Is(err, ErrInvalidSearch) {
+		t.Fatalf("empty search error = %v", err)
+	}
+}
+
+func TestPageThoughtsFollowsCursorsAcrossChanges(t *testing.T) {
+	st, _ := openTestStore(t)
+
+	var ids []int64
+	for i := 0; i < 5; i++ {
+		id, err := st.CreateThought(fmt.Sprintf("thought %d", i))
+		if err != nil {
+			t.Fatalf("create %d: %v", i, err)
+		}
+		ids = append(ids, id)
+	}
+
+	page, next, err := st.PageThoughts(GardenQuery(), 2, "")
+	if err != nil {
+		t.Fatalf("first page: %v", err)
+	}
+	if len(page) != 2 || page[1].ID != ids[1] || next == "" {
+		t.Fatalf("first page = %v, next %q", page, next)
+	}
+
+	// Releasing a thought already seen would shift every later row back one under OFFSET.
+	if err := st.SoftReleaseThought(ids[0], nil); err != nil {
+		t.Fatalf("release: %v", err)
+	}
+	page, next, err = st.PageThoughts(GardenQuery(), 2, next)
+	if err != nil {
+		t.Fatalf("second page: %v", err)
+	}
+	if len(page) != 2 || page[0].ID != ids[2] || page[1].ID != ids[3] || next == "" {
+		t.Fatalf("second page = %v, next %q", page, next)
+	}
+	page, next, err = st.PageThoughts(GardenQuery(), 2, next)
+	if err != nil {
+		t.Fatalf("last page: %v", err)
+	}
+	if len(page) != 1 || page[0].ID != ids[4] || next != "" {
+		t.Fatalf("last page = %v, next %q", page, next)
+	}
+
+	all, next, err := st.PageThoughts(StateQuery(core.StateCaptured), 0, EncodeCursor(OrderByID, page[0]))
+	if err != nil || len(all) != 0 || next != "" {
+		t.Fatalf("after the last thought = %v, %q, %v", all, next, err)
+	}
+
+	for _, cursor := range []string{"not-a-cursor", EncodeCursor(OrderByID, page[0])} {
+		if _, _, err := st.PageThoughts(GardenQuery(), 2, cursor); !errors.Is(err, ErrInvalidCursor) {
+			t.Fatalf("cursor %q error = %v, want ErrInvalidCursor", cursor, err)
+		}
+	}
+	position, err := DecodeCursor(EncodeCursor(OrderByUpdated, page[0]), OrderByUpdated)
+	if err != nil || position.ID != ids[4] || position.Key != formatTime(page[0].UpdatedAt) {
+		t.Fatalf("decoded position = %+v, %v", position, err)
+	}
+}
+
+// syntheticGarden builds n years-old thoughts spread over every state, each with a short history,
+// and a tag on every third.
+func syntheticGarden(n int) []ImportRecord {
+	start := time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+	tags := []string{"work", "home", "later", "reading"}
+	note := "looked again"
+	records := make([]ImportRecord, 0, n)
+	for i := 0; i < n; i++ {
+		created := start.Add(time.Duration(i) * 17 * time.Minute)
+		state := core.States[i%len(core.States)]
+		captured := core.StateCaptured
+		tended := core.StateTended
+		thought := core.Thought{
+			ID:            int64(i + 1),
+			Content:       fmt.Sprintf("synthetic thought %d about something worth keeping", i),
+			CurrentState:  state,
+			TendCounter:   i % 4,
+			CreatedAt:     created,
+			UpdatedAt:     created.Add(48 * time.Hour),
+			EligibilityAt: created.Add(24 * time.Hour),
+		}
+		if state == core.StateReleased {
+			released := thought.UpdatedAt
+			thought.ReleasedAt = &released
+		}
+		if i%3 == 0 {
+			thought.Tags = []string{tags[i%len(tags)]}
+		}
+		records = append(records, ImportRecord{
+			Thought: thought,
+			Events: []core.Event{
+				{Kind: "captured", At: created, NextState: &captured},
+				{Kind: "tended", At: created.Add(24 * time.Hour), Note: &note},
+				{Kind: "state_change", At: created.Add(48 * time.Hour), PreviousState: &tended, NextState: &state},
+			},
+		})
+	}
+	return records
+}
+
+// BenchmarkListThoughtsWithEvents loads large gardens the way Bloom does, against the older
+// query-per-thought loading it replaced.
+func BenchmarkListThoughtsWithEvents(b *testing.B) {
+	for _, size := range []struct {
+		name string
+		n    int
+	}{{"10k", 10_000}, {"100k", 100_000}} {
+		if size.n > 10_000 && testing.Short() {
+			continue
+		}
+		st, _ := openTestStore(b)
+		if _, err := st.ImportThoughts(syntheticGarden(size.n), ImportOptions{}); err != nil {
+			b.Fatalf("seed %s: %v", size.name, err)
+		}
+
+		b.Run(size.name+"/batched", func(b *testing.B) {
+			for b.Loop() {
+				if _, err := st.ListThoughtsWithEvents(core.StateArchived, core.StateReleased); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+		if size.n > 10_000 {
+			continue // paging by OFFSET makes the old loading quadratic; 10k shows the gap
+		}
+		b.Run(size.name+"/per-thought", func(b *testing.B) {
+			for b.Loop() {
+				for offset := 0; ; offset += 100 {
+					page, err := st.ListBloomThoughtsByPagination(100, offset)
+					if err != nil {
+						b.Fatal(err)
+					}
+					for _, th := range page {
+						if _, _, err := st.GetThought(th.ID); err != nil {
+							b.Fatal(err)
+						}
+					}
+					if len(page) < 100 {
+						break
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestOpenConfiguresJournalModeAndBusyTimeout(t *testing.T) {
+	pragmas := func(db *sql.DB) (string, int) {
+		t.Helper()
+		var mode string
+		var timeout int
+		if err := db.QueryRow(`PRAGMA journal_mode;`).Scan(&mode); err != nil {
+			t.Fatalf("journal mode: %v", err)
+		}
+		if err := db.QueryRow(`PRAGMA busy_timeout;`).Scan(&timeout); err != nil {
+			t.Fatalf("busy timeout: %v", err)
+		}
+		return mode, timeout
+	}
+
+	_, db := openTestStore(t)
+	if mode, timeout := pragmas(db); mode != "wal" || timeout != 5000 {
+		t.Fatalf("default open = %s, %dms; want wal, 5000ms", mode, timeout)
+	}
+
+	path := filepath.Join(t.TempDir(), "peony.db")
+	custom, err := OpenWith(path, Options{JournalMode: "DELETE", BusyTimeout: 250 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("open with delete: %v", err)
+	}
+	if mode, timeout := pragmas(custom); mode != "delete" || timeout != 250 {
+		t.Fatalf("custom open = %s, %dms; want delete, 250ms", mode, timeout)
+	}
+	_ = custom.Close()
+
+	// While one connection holds the file in WAL, asking for another mode keeps WAL rather than
+	// failing or waiting; the change is made by a later open that has the file to itself.
+	wal, err := OpenWith(path, Options{JournalMode: "wal", BusyTimeout: time.Second})
+	if err != nil {
+		t.Fatalf("open with wal: %v", err)
+	}
+	start := time.Now()
+	other, err := OpenWith(path, Options{JournalMode: "delete", BusyTimeout: time.Second})
+	if err != nil {
+		t.Fatalf("open with delete while wal is open: %v", err)
+	}
+	if mode, _ := pragmas(other); mode != "wal" {
+		t.Fatalf("mode while another connection is open = %s, want wal", mode)
+	}
+	if waited := time.Since(start); waited > 500*time.Millisecond {
+		t.Fatalf("open waited %v for the journal mode", waited)
+	}
+	_ = other.Close()
+	_ = wal.Close()
+
+	for _, opts := range []Options{{JournalMode: "memory"}, {JournalMode: "wal", BusyTimeout: -time.Second}} {
+		if db, err := OpenWith(filepath.Join(t.TempDir(), "peony.db"), opts); err == nil {
+			_ = db.Close()
+			t.Fatalf("OpenWith(%+v) succeeded", opts)
+		}
+	}
+}
+
+func TestWriteRetriesWhileAnotherConnectionHoldsTheLock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "peony.db")
+	holder, err := OpenWith(path, Options{BusyTimeout: 0})
+	if err != nil {
+		t.Fatalf("open holder: %v", err)
+	}
+	defer holder.Close()
+	db, err := OpenWith(path, Options{BusyTimeout: 0})
+	if err != nil {
+		t.Fatalf("open writer: %v", err)
+	}
+	defer db.Close()
+	st, err := New(db)
+	if err != nil {
+		t.Fatalf("new store: %v", err)
+	}
+
+	tx, err := holder.Begin()
+	if err != nil {
+		t.Fatalf("hold write lock: %v", err)
+	}
+	released := make(chan struct{})
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		_ = tx.Rollback()
+		close(released)
+	}()
+
+	// Without a busy timeout, only the retries let the write through once the lock is released.
+	id, err := st.CreateThought("written after the wait")
+	if err != nil {
+		t.Fatalf("create while locked: %v", err)
+	}
+	<-released
+	if _, err := st.UpdateThoughtTags(id, []string{"waited"}, nil); err != nil {
+		t.Fatalf("tag after the wait: %v", err)
+	}
+
+	tx, err = holder.Begin()
+	if err != nil {
+		t.Fatalf("hold write lock again: %v", err)
+	}
+	defer tx.Rollback()
+	if _, err := st.UpdateThoughtTags(id, []string{"never"}, nil); !isBusy(err) {
+		t.Fatalf("tag while the lock is never released = %v, want busy", err)
+	}
+}
+
+// hammerStore writes n thoughts tagged name through st, reading the garden back after each one.
+func hammerStore(st *Store, name string, n int) error {
+	for i := 0; i < n; i++ {
+		id, err := st.CreateThought(fmt.Sprintf("%s thought %d", name, i))
+		if err != nil {
+			return fmt.Errorf("%s create %d: %w", name, i, err)
+		}
+		if _, err := st.UpdateThoughtTags(id, []string{name}, nil); err != nil {
+			return fmt.Errorf("%s tag %d: %w", name, i, err)
+		}
+		if _, err := st.ListThoughtsWithEvents(core.StateReleased); err != nil {
+			return fmt.Errorf("%s read %d: %w", name, i, err)
+		}
+	}
+	return nil
+}
+
+// checkHammered checks that every writer's n thoughts arrived.
+func checkHammered(t *testing.T, st *Store, names []string, n int) {
+	t.Helper()
+	all, err := st.QueryThoughts(ThoughtQuery{})
+	if err != nil {
+		t.Fatalf("query all: %v", err)
+	}
+	if len(all) != len(names)*n {
+		t.Fatalf("thoughts = %d, want %d", len(all), len(names)*n)
+	}
+	for _, name := range names {
+		tagged, err := st.QueryThoughts(ThoughtQuery{Tags: []string{name}})
+		if err != nil {
+			t.Fatalf("query %s: %v", name, err)
+		}
+		if len(tagged) != n {
+			t.Fatalf("%s thoughts = %d, want %d", name, len(tagged), n)
+		}
+	}
+}
+
+func TestStoreWritesFromConcurrentGoroutines(t *testing.T) {
+	const writers, each = 8, 25
+	path := filepath.Join(t.TempDir(), "peony.db")
+	st, err := openStoreAt(path)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	t.Cleanup(func() { _ = st.db.Close() })
+
+	var names []string
+	var wg sync.WaitGroup
+	errs := make(chan error, writers)
+	for w := 0; w < writers; w++ {
+		name := fmt.Sprintf("writer%d", w)
+		names = append(names, name)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// Half the writers share the store's pool; the rest open their own, like separate
+			// processes do.
+			writer := st
+			if w%2 == 1 {
+				own, err := openStoreAt(path)
+				if err != nil {
+					errs <- err
+					return
+				}
+				defer own.db.Close()
+				writer = own
+			}
+			errs <- hammerStore(writer, name, each)
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	checkHammered(t, st, names, each)
+}
+
+func TestStoreWritesFromConcurrentProcesses(t *testing.T) {
+	if testing.Short() {
+		t.Skip("starts writer processes")
+	}
+	const processes, each = 4, 25
+	path := filepath.Join(t.TempDir(), "peony.db")
+	st, err := openStoreAt(path)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	t.Cleanup(func() { _ = st.db.Close() })
+	exe, err := os.Executable()
+	if err != nil {
+		t.Fatalf("test binary: %v", err)
+	}
+
+	names := []string{"parent"}
+	var cmds []*exec.Cmd
+	var outputs []*strings.Builder
+	for p := 0; p < processes; p++ {
+		name := fmt.Sprintf("process%d", p)
+		names = append(names, name)
+		cmd := exec.Command(exe, "-test.run=^TestConcurrentWriterProcess$", "-test.count=1")
+		cmd.Env = append(os.Environ(), "PEONY_TEST_WRITER_DB="+path, "PEONY_TEST_WRITER_NAME="+name, fmt.Sprintf("PEONY_TEST_WRITER_COUNT=%d", each))
+		output := &strings.Builder{}
+		cmd.Stdout, cmd.Stderr = output, output
+		if err := cmd.Start(); err != nil {
+			t.Fatalf("start %s: %v", name, err)
+		}
+		cmds = append(cmds, cmd)
+		outputs = append(outputs, output)
+	}
+	if err := hammerStore(st, "parent", each); err != nil {
+		t.Error(err)
+	}
+	for i, cmd := range cmds {
+		if err := cmd.Wait(); err != nil {
+			t.Errorf("%s: %v\n%s", names[i+1], err, outputs[i])
+		}
+	}
+	if t.Failed() {
+		t.FailNow()
+	}
+	checkHammered(t, st, names, each)
+}
+
+// TestConcurrentWriterProcess is one writer of TestStoreWritesFromConcurrentProcesses, run in
+// its own process.
+func TestConcurrentWriterProcess(t *testing.T) {
+	path := os.Getenv("PEONY_TEST_WRITER_DB")
+	if path == "" {
+		t.Skip("runs as a writer process of TestStoreWritesFromConcurrentProcesses")
+	}
+	var each int
+	if _, err := fmt.Sscan(os.Getenv("PEONY_TEST_WRITER_COUNT"), &each); err != nil {
+		t.Fatalf("writer count: %v", err)
+	}
+	st, err := openStoreAt(path)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer st.db.Close()
+	if err := hammerStore(st, os.Getenv("PEONY_TEST_WRITER_NAME"), each); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// openStoreAt opens the store at path with the default connection options.
+func openStoreAt(path string) (*Store, error) {
+	db, err := Open(path)
+	if err != nil {
+		return nil, err
+	}
+	st, err := New(db)
+	if err != nil {
+		_ = db.Close()
+		return nil, err
 	}
+	return st, nil
 }
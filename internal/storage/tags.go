@@ -0,0 +1,236 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/divijg19/peony/internal/core"
+)
+
+// TagCount is a tag name with the number of thoughts carrying it.
+type TagCount struct {
+	Name  string
+	Count int
+}
+
+// UpdateThoughtTags adds and removes tags on a thought in one transaction and returns the resulting tags.
+// Tag names are normalized with core.NormalizeTag. When anything changes a "tagged" event is appended,
+// and tags no longer used by any thought are pruned.
+func (s *Store) UpdateThoughtTags(id int64, add, remove []string) ([]string, error) {
+	if s == nil {
+		return nil, fmt.Errorf("update thought tags: store is nil")
+	}
+	if s.db == nil {
+		return nil, fmt.Errorf("update thought tags: db is nil")
+	}
+	if id <= 0 {
+		return nil, fmt.Errorf("update thought tags: invalid thought ID")
+	}
+
+	addNames, err := normalizeTags(add)
+	if err != nil {
+		return nil, fmt.Errorf("update thought tags: %w", err)
+	}
+	removeNames, err := normalizeTags(remove)
+	if err != nil {
+		return nil, fmt.Errorf("update thought tags: %w", err)
+	}
+	for _, name := range addNames {
+		for _, other := range removeNames {
+			if name == other {
+				return nil, fmt.Errorf("update thought tags: %q is both added and removed", name)
+			}
+		}
+	}
+
+	tx, err := s.begin()
+	if err != nil {
+		return nil, fmt.Errorf("update thought tags: begin tx: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	var exists int
+	err = tx.QueryRow(`SELECT 1 FROM thoughts WHERE id = ?`, id).Scan(&exists)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("update thought tags: %w", ErrNotFound)
+		}
+		return nil, fmt.Errorf("update thought tags: lookup thought: %w", err)
+	}
+
+	now := time.Now().UTC().Format(time.RFC3339Nano)
+	changes := make([]string, 0, len(addNames)+len(removeNames))
+
+	for _, name := range addNames {
+		attached, err := attachTag(tx, id, name, now)
+		if err != nil {
+			return nil, fmt.Errorf("update thought tags: %w", err)
+		}
+		if attached {
+			changes = append(changes, "+"+name)
+		}
+	}
+
+	for _, name := range removeNames {
+		res, err := tx.Exec(
+			`DELETE FROM thought_tags
+			 WHERE thought_id = ?
+			   AND tag_id = (SELECT id FROM tags WHERE name = ?)`,
+			id, name,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("update thought tags: detach tag: %w", err)
+		}
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return nil, fmt.Errorf("update thought tags: rows affected: %w", err)
+		}
+		if affected > 0 {
+			changes = append(changes, "-"+name)
+		}
+	}
+
+	if len(changes) > 0 {
+		_, err = tx.Exec(`DELETE FROM tags WHERE id NOT IN (SELECT tag_id FROM thought_tags)`)
+		if err != nil {
+			return nil, fmt.Errorf("update thought tags: prune tags: %w", err)
+		}
+		_, err = tx.Exec(
+			`INSERT INTO events (thought_id, kind, at, previous_state, next_state, note) VALUES (?, ?, ?, NULL, NULL, ?)`,
+			id, "tagged", now, strings.Join(changes, " "),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("update thought tags: insert event: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("update thought tags: commit: %w", err)
+	}
+
+	return s.ThoughtTags(id)
+}
+
+// ThoughtTags returns a thought's tag names in alphabetical order.
+func (s *Store) ThoughtTags(id int64) ([]string, error) {
+	if s == nil {
+		return nil, fmt.Errorf("thought tags: store is nil")
+	}
+	if s.db == nil {
+		return nil, fmt.Errorf("thought tags: db is nil")
+	}
+
+	rows, err := s.db.Query(
+		`SELECT t.name
+		 FROM thought_tags tt
+		 JOIN tags t ON t.id = tt.tag_id
+		 WHERE tt.thought_id = ?
+		 ORDER BY t.name ASC`,
+		id,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("thought tags: query: %w", err)
+	}
+	defer rows.Close()
+
+	tags := make([]string, 0)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("thought tags: scan: %w", err)
+		}
+		tags = append(tags, name)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("thought tags: rows: %w", err)
+	}
+	return tags, nil
+}
+
+// ListTags returns every tag in use with how many thoughts carry it, ordered by name.
+func (s *Store) ListTags() ([]TagCount, error) {
+	if s == nil {
+		return nil, fmt.Errorf("list tags: store is nil")
+	}
+	if s.db == nil {
+		return nil, fmt.Errorf("list tags: db is nil")
+	}
+
+	rows, err := s.db.Query(
+		`SELECT t.name, COUNT(tt.thought_id)
+		 FROM tags t
+		 JOIN thought_tags tt ON tt.tag_id = t.id
+		 GROUP BY t.id
+		 ORDER BY t.name ASC`,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list tags: query: %w", err)
+	}
+	defer rows.Close()
+
+	tags := make([]TagCount, 0)
+	for rows.Next() {
+		var tag TagCount
+		if err := rows.Scan(&tag.Name, &tag.Count); err != nil {
+			return nil, fmt.Errorf("list tags: scan: %w", err)
+		}
+		tags = append(tags, tag)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list tags: rows: %w", err)
+	}
+	return tags, nil
+}
+
+// FilterViewByTagPagination returns a page of thoughts carrying tag, ordered by ID.
+func (s *Store) FilterViewByTagPagination(limit, offset int, tag string) ([]core.Thought, error) {
+	name, err := core.NormalizeTag(tag)
+	if err != nil {
+		return nil, fmt.Errorf("list tagged thoughts: %w", err)
+	}
+	return s.listPage("list tagged thoughts", limit, offset, TagQuery(name))
+}
+
+// attachTag links an already-normalized tag to a thought, creating the tag if needed.
+// It reports whether the link is new.
+func attachTag(tx *sql.Tx, thoughtID int64, name string, now string) (bool, error) {
+	_, err := tx.Exec(`INSERT INTO tags(name, created_at) VALUES (?, ?) ON CONFLICT(name) DO NOTHING`, name, now)
+	if err != nil {
+		return false, fmt.Errorf("insert tag: %w", err)
+	}
+	res, err := tx.Exec(
+		`INSERT OR IGNORE INTO thought_tags(thought_id, tag_id, created_at)
+		 SELECT ?, id, ? FROM tags WHERE name = ?`,
+		thoughtID, now, name,
+	)
+	if err != nil {
+		return false, fmt.Errorf("attach tag: %w", err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("attach tag: rows affected: %w", err)
+	}
+	return affected > 0, nil
+}
+
+func normalizeTags(values []string) ([]string, error) {
+	names := make([]string, 0, len(values))
+	seen := make(map[string]bool, len(values))
+	for _, value := range values {
+		name, err := core.NormalizeTag(strings.TrimSpace(value))
+		if err != nil {
+			return nil, err
+		}
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		names = append(names, name)
+	}
+	return names, nil
+}
@@ -0,0 +1,190 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/divijg19/peony/internal/core"
+)
+
+// SoftReleaseThought moves a thought into the released state and stamps released_at.
+// The thought and its history stay in the trash until RestoreThought brings them back
+// or PurgeReleased deletes them once the grace period is over.
+func (s *Store) SoftReleaseThought(id int64, note *string) error {
+	if s == nil {
+		return fmt.Errorf("release: store is nil")
+	}
+	if s.db == nil {
+		return fmt.Errorf("release: db is nil")
+	}
+	if id <= 0 {
+		return fmt.Errorf("release: invalid thought ID")
+	}
+
+	tx, err := s.begin()
+	if err != nil {
+		return fmt.Errorf("release: begin tx: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	if _, err := transitionTx(tx, "release", id, core.ActionRelease, core.StateReleased, note, 0); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("release: commit: %w", err)
+	}
+	return nil
+}
+
+// RestoreThought takes a released thought out of the trash and returns it to the state it was
+// released from, appending a "restored" event. It reports the state the thought is back in.
+func (s *Store) RestoreThought(id int64) (core.State, error) {
+	if s == nil {
+		return "", fmt.Errorf("restore: store is nil")
+	}
+	if s.db == nil {
+		return "", fmt.Errorf("restore: db is nil")
+	}
+	if id <= 0 {
+		return "", fmt.Errorf("restore: invalid thought ID")
+	}
+
+	tx, err := s.begin()
+	if err != nil {
+		return "", fmt.Errorf("restore: begin tx: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	// Go back to where the last release came from. Imported or hand-edited histories may not say,
+	// so anything the lifecycle cannot restore to rests instead.
+	next := core.StateResting
+	var fromStr sql.NullString
+	err = tx.QueryRow(
+		`SELECT previous_state FROM events WHERE thought_id = ? AND next_state = ? ORDER BY at DESC, id DESC LIMIT 1`,
+		id,
+		string(core.StateReleased),
+	).Scan(&fromStr)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return "", fmt.Errorf("restore: read release event: %w", err)
+	}
+	if from := core.State(fromStr.String); from != "" {
+		if _, err := core.LookupTransition(core.ActionRestore, core.StateReleased, from); err == nil {
+			next = from
+		}
+	}
+
+	if _, err := transitionTx(tx, "restore", id, core.ActionRestore, next, nil, 0); err != nil {
+		return "", err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return "", fmt.Errorf("restore: commit: %w", err)
+	}
+	return next, nil
+}
+
+// ListReleasedThoughts returns the thoughts in the trash, the one released longest ago first.
+func (s *Store) ListReleasedThoughts() ([]core.Thought, error) {
+	items, err := s.queryThoughts("list released", ThoughtQuery{
+		States: []core.State{core.StateReleased},
+		Order:  OrderByReleased,
+	}, false)
+	if err != nil {
+		return nil, err
+	}
+
+	thoughts := make([]core.Thought, 0, len(items))
+	for _, item := range items {
+		thought := item.Thought
+		// A thought released by an import or an older Peony has no stamp; its last update stands in.
+		if thought.ReleasedAt == nil {
+			releasedAt := thought.UpdatedAt
+			thought.ReleasedAt = &releasedAt
+		}
+		thoughts = append(thoughts, thought)
+	}
+	return thoughts, nil
+}
+
+// PurgeReleased permanently deletes released thoughts whose release happened at or before cutoff,
+// along with their events and tag links. It returns how many thoughts were deleted.
+// IDs are not renumbered here; callers decide whether to reindex.
+func (s *Store) PurgeReleased(cutoff time.Time) (int, error) {
+	if s == nil {
+		return 0, fmt.Errorf("purge released: store is nil")
+	}
+	if s.db == nil {
+		return 0, fmt.Errorf("purge released: db is nil")
+	}
+
+	tx, err := s.begin()
+	if err != nil {
+		return 0, fmt.Errorf("purge released: begin tx: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	rows, err := tx.Query(
+		`SELECT id FROM thoughts WHERE current_state = ? AND COALESCE(released_at, updated_at) <= ?`,
+		string(core.StateReleased),
+		cutoff.UTC().Format(time.RFC3339Nano),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("purge released: query: %w", err)
+	}
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			_ = rows.Close()
+			return 0, fmt.Errorf("purge released: scan: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		_ = rows.Close()
+		return 0, fmt.Errorf("purge released: rows: %w", err)
+	}
+	_ = rows.Close()
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	for _, id := range ids {
+		if _, err := deleteThoughtTx(tx, id); err != nil {
+			return 0, fmt.Errorf("purge released: #%d: %w", id, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("purge released: commit: %w", err)
+	}
+	return len(ids), nil
+}
+
+// deleteThoughtTx removes a thought with its events and tag links and reports whether it existed.
+func deleteThoughtTx(tx *sql.Tx, id int64) (bool, error) {
+	if _, err := tx.Exec(`DELETE FROM events WHERE thought_id = ?`, id); err != nil {
+		return false, fmt.Errorf("delete events: %w", err)
+	}
+	if _, err := tx.Exec(`DELETE FROM thought_tags WHERE thought_id = ?`, id); err != nil {
+		return false, fmt.Errorf("delete tags: %w", err)
+	}
+	res, err := tx.Exec(`DELETE FROM thoughts WHERE id = ?`, id)
+	if err != nil {
+		return false, fmt.Errorf("delete thought: %w", err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("rows affected: %w", err)
+	}
+	return affected > 0, nil
+}
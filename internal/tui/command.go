@@ -7,29 +7,29 @@ import (
 	"time"
 
 	"github.com/divijg19/peony/internal/app"
+	"github.com/divijg19/peony/internal/command"
 	"github.com/divijg19/peony/internal/config"
 	"github.com/divijg19/peony/internal/core"
+	"github.com/divijg19/peony/internal/storage"
+	"github.com/divijg19/peony/internal/version"
 )
 
-const peonyVersion = "v0.4"
-
-type commandSpec struct {
-	Name    string
-	Aliases []string
-	Usage   string
-	Help    string
-}
-
-var commandSpecs = []commandSpec{
-	{Name: "help", Aliases: []string{"h"}, Usage: "help [command]", Help: "Show Bloom command help."},
-	{Name: "version", Aliases: []string{"-v"}, Usage: "version", Help: "Show the Peony version."},
-	{Name: "add", Aliases: []string{"a"}, Usage: "add [content]", Help: "Capture a thought, or open capture when content is omitted."},
-	{Name: "view", Aliases: []string{"v"}, Usage: "view [id|state]", Help: "Read visible thoughts, a thought by id, or a state filter."},
-	{Name: "tend", Aliases: []string{"t"}, Usage: "tend [id]", Help: "List ready thoughts or open a thought for tending."},
-	{Name: "release", Aliases: []string{"r"}, Usage: "release <id>", Help: "Ask before permanently releasing a thought."},
-	{Name: "evolve", Aliases: []string{"e"}, Usage: "evolve [id]", Help: "List evolved thoughts or mark one evolved."},
-	{Name: "config", Aliases: []string{"configure", "c"}, Usage: "config [settleDuration <duration>|editor]", Help: "View or update configuration."},
-	{Name: "tui", Usage: "tui", Help: "Report that Bloom is already open."},
+// bloomCommands binds the registry's Bloom commands to their handlers. Commands without a Bloom
+// usage in the registry stay CLI-only.
+var bloomCommands = map[string]func(*Model, command.Args){
+	"help":    (*Model).commandHelp,
+	"version": (*Model).commandVersion,
+	"add":     (*Model).commandAdd,
+	"view":    (*Model).commandView,
+	"tend":    (*Model).commandTend,
+	"release": (*Model).commandRelease,
+	"evolve":  (*Model).commandEvolve,
+	"revive":  (*Model).commandRevive,
+	"trash":   (*Model).commandTrash,
+	"restore": (*Model).commandRestore,
+	"tag":     (*Model).commandTag,
+	"config":  (*Model).commandConfig,
+	"tui":     (*Model).commandTUI,
 }
 
 func (m *Model) runCommand(line string) {
@@ -49,35 +49,19 @@ func (m *Model) runCommand(line string) {
 	}
 
 	cmd := args[0]
-	rest := args[1:]
 	m.mode = ModeBrowse
 	m.focus = FocusQueue
 	m.clearOutput()
 	m.status = ""
 
-	switch canonicalCommand(cmd) {
-	case "help", "h":
-		m.setOutput("Help", commandHelp(rest), OutputHelp, "help", true)
-		m.status = "Help opened."
-	case "version", "-v":
-		m.setOutput("Version", []string{"Peony " + peonyVersion}, OutputCommand, "version", false)
-		m.status = "Version shown."
-	case "add", "a":
-		m.commandAdd(rest)
-	case "view", "v":
-		m.commandView(rest)
-	case "tend", "t":
-		m.commandTend(rest)
-	case "release", "r":
-		m.commandRelease(rest)
-	case "evolve", "e":
-		m.commandEvolve(rest)
-	case "config", "configure", "c":
-		m.commandConfig(rest)
-	case "tui":
-		m.setOutput("TUI", []string{"Bloom is already open."}, OutputCommand, "tui", false)
-		m.status = "Already in Bloom."
-	default:
+	spec, ok := command.Lookup(cmd)
+	run, bound := bloomCommands[spec.Name]
+	if ok && !spec.Hidden && !spec.InBloom() {
+		m.setOutput("Command error", []string{fmt.Sprintf("%s is only available from the peony command line", spec.Name)}, OutputError, line, true)
+		m.status = "Command needs the CLI."
+		return
+	}
+	if !ok || spec.Hidden || !bound {
 		lines := []string{fmt.Sprintf("Unknown command: %s", cmd)}
 		if suggestion := commandSuggestion(cmd); suggestion != "" {
 			lines = append(lines, "Did you mean: "+suggestion+"?")
@@ -85,11 +69,44 @@ func (m *Model) runCommand(line string) {
 		lines = append(lines, "Try : help")
 		m.setOutput("Command error", lines, OutputError, line, true)
 		m.status = "Command not recognized."
+		return
 	}
+
+	in, err := spec.Parse(args[1:])
+	if err == nil {
+		err = spec.CheckBloom(in)
+	}
+	if err != nil {
+		m.setOutput("Command error", []string{fmt.Sprintf("%s: %v", spec.Name, err)}, OutputError, line, true)
+		m.status = "Command could not be parsed."
+		return
+	}
+	run(m, in)
+}
+
+func (m *Model) commandHelp(in command.Args) {
+	m.setOutput("Help", commandHelp(in.Positional), OutputHelp, "help", true)
+	m.status = "Help opened."
 }
 
-func (m *Model) commandAdd(args []string) {
-	content := strings.TrimSpace(strings.Join(args, " "))
+func (m *Model) commandVersion(command.Args) {
+	line := fmt.Sprintf("Peony %s, schema %d", version.Get().Summary(), storage.SchemaVersion)
+	m.setOutput("Version", []string{line}, OutputCommand, "version", false)
+	m.status = "Version shown."
+}
+
+func (m *Model) commandTUI(command.Args) {
+	m.setOutput("TUI", []string{"Bloom is already open."}, OutputCommand, "tui", false)
+	m.status = "Already in Bloom."
+}
+
+func (m *Model) commandAdd(in command.Args) {
+	valence, energy, err := commandFeeling(in)
+	if err != nil {
+		m.commandError(fmt.Errorf("add: %w", err))
+		return
+	}
+	content := strings.TrimSpace(strings.Join(in.Positional, " "))
 	if content == "" {
 		m.mode = ModeCapture
 		m.focus = FocusPrompt
@@ -99,7 +116,7 @@ func (m *Model) commandAdd(args []string) {
 		m.setOutput("Capture", []string{"Capture opened."}, OutputCommand, "add", false)
 		return
 	}
-	id, err := m.service.Capture(content)
+	id, err := m.service.CaptureFeeling(content, valence, energy)
 	if err != nil {
 		m.status = err.Error()
 		m.setOutput("Command error", []string{err.Error()}, OutputError, "add", true)
@@ -110,7 +127,21 @@ func (m *Model) commandAdd(args []string) {
 	m.setOutput("Capture", []string{fmt.Sprintf("Saved as #%d", id)}, OutputCommand, "add", false)
 }
 
-func (m *Model) commandView(args []string) {
+func (m *Model) commandView(in command.Args) {
+	args := in.Positional
+	if command.HasViewFilter(in) {
+		m.commandViewFiltered(in)
+		return
+	}
+	if tag, ok := in.Value("--tag"); ok {
+		if len(args) != 0 {
+			m.setOutput("Command error", []string{"view: usage: view --tag <tag>"}, OutputError, "view", true)
+			m.status = "Command needs one view target."
+			return
+		}
+		m.commandTag(command.Args{Positional: []string{tag}})
+		return
+	}
 	if len(args) == 0 {
 		snapshot, err := m.service.SnapshotBloom(m.filter.appFilter(), m.query)
 		if err != nil {
@@ -128,7 +159,12 @@ func (m *Model) commandView(args []string) {
 		return
 	}
 	arg := strings.TrimPrefix(args[0], "--")
-	if id, err := strconv.ParseInt(arg, 10, 64); err == nil && id > 0 {
+	if core.LooksLikeThoughtRef(arg) {
+		id, err := m.service.ResolveID(arg)
+		if err != nil {
+			m.commandError(err)
+			return
+		}
 		item, err := m.service.Thought(id)
 		if err != nil {
 			m.commandError(err)
@@ -139,8 +175,8 @@ func (m *Model) commandView(args []string) {
 		m.status = fmt.Sprintf("Viewing #%d.", id)
 		return
 	}
-	state, ok := parseState(arg)
-	if !ok {
+	state, err := core.ParseState(arg)
+	if err != nil {
 		m.setOutput("Command error", []string{"view: invalid filter"}, OutputError, "view", true)
 		m.status = "Command filter was not recognized."
 		return
@@ -155,7 +191,47 @@ func (m *Model) commandView(args []string) {
 	m.status = "View shown."
 }
 
-func (m *Model) commandTend(args []string) {
+// commandViewFiltered lists the thoughts a :view with filter flags selects, reading positional
+// arguments as states and --tag as the tag to narrow to, the same way peony view does.
+func (m *Model) commandViewFiltered(in command.Args) {
+	var states []core.State
+	for _, arg := range in.Positional {
+		state, err := core.ParseState(strings.TrimPrefix(arg, "--"))
+		if err != nil {
+			m.setOutput("Command error", []string{"view: invalid filter"}, OutputError, "view", true)
+			m.status = "Command filter was not recognized."
+			return
+		}
+		states = append(states, state)
+	}
+	title := "View"
+	tag, tagged := in.Value("--tag")
+	if tagged {
+		name, err := core.NormalizeTag(tag)
+		if err != nil {
+			m.commandError(fmt.Errorf("view: %w", err))
+			return
+		}
+		tag = name
+		title += " #" + tag
+	}
+	filter, err := command.ViewFilter(in, states, tag)
+	if err != nil {
+		m.commandError(fmt.Errorf("view: %w", err))
+		return
+	}
+	thoughts, err := m.service.View(filter)
+	if err != nil {
+		m.commandError(err)
+		return
+	}
+	lines := thoughtTable(title, thoughts, 10)
+	m.setOutput(title, lines, OutputCommand, "view", len(lines) > 3)
+	m.status = fmt.Sprintf("View shown: %d matching.", len(thoughts))
+}
+
+func (m *Model) commandTend(in command.Args) {
+	args := in.Positional
 	if len(args) == 0 {
 		thoughts, err := m.service.TendReady(10)
 		if err != nil {
@@ -172,25 +248,27 @@ func (m *Model) commandTend(args []string) {
 		m.status = "Command needs one thought id."
 		return
 	}
-	id, err := strconv.ParseInt(args[0], 10, 64)
-	if err != nil || id <= 0 {
-		m.setOutput("Command error", []string{"tend: invalid id"}, OutputError, "tend", true)
-		m.status = "Command id was not valid."
+	id, ok := m.resolveCommandID("tend", args[0])
+	if !ok {
 		return
 	}
 	m.startTendByID(id)
 }
 
-func (m *Model) commandRelease(args []string) {
+func (m *Model) commandRelease(in command.Args) {
+	args := in.Positional
 	if len(args) != 1 {
-		m.setOutput("Command error", []string{"release: usage: release <id>"}, OutputError, "release", true)
+		m.setOutput("Command error", []string{"release: usage: release <id|ref> [--note text]"}, OutputError, "release", true)
 		m.status = "Command needs a thought id."
 		return
 	}
-	id, err := strconv.ParseInt(args[0], 10, 64)
-	if err != nil || id <= 0 {
-		m.setOutput("Command error", []string{"release: invalid id"}, OutputError, "release", true)
-		m.status = "Command id was not valid."
+	note, err := commandNote(in)
+	if err != nil {
+		m.commandError(fmt.Errorf("release: %w", err))
+		return
+	}
+	id, ok := m.resolveCommandID("release", args[0])
+	if !ok {
 		return
 	}
 	if _, err := m.service.Thought(id); err != nil {
@@ -198,13 +276,15 @@ func (m *Model) commandRelease(args []string) {
 		return
 	}
 	m.pendingReleaseID = id
+	m.pendingReleaseNote = note
 	m.mode = ModeReleaseConfirm
 	m.focus = FocusPrompt
 	m.setOutput("Release", []string{fmt.Sprintf("Confirm release of #%d.", id)}, OutputWarning, "release", false)
 	m.status = ""
 }
 
-func (m *Model) commandEvolve(args []string) {
+func (m *Model) commandEvolve(in command.Args) {
+	args := in.Positional
 	if len(args) == 0 {
 		snapshot, err := m.service.Snapshot(core.StateEvolved, "")
 		if err != nil {
@@ -221,10 +301,8 @@ func (m *Model) commandEvolve(args []string) {
 		m.status = "Command needs one thought id."
 		return
 	}
-	id, err := strconv.ParseInt(args[0], 10, 64)
-	if err != nil || id <= 0 {
-		m.setOutput("Command error", []string{"evolve: invalid id"}, OutputError, "evolve", true)
-		m.status = "Command id was not valid."
+	id, ok := m.resolveCommandID("evolve", args[0])
+	if !ok {
 		return
 	}
 	if err := m.service.Evolve(id); err != nil {
@@ -236,7 +314,167 @@ func (m *Model) commandEvolve(args []string) {
 	m.setOutput("Evolve", []string{fmt.Sprintf("Evolved #%d.", id)}, OutputCommand, "evolve", false)
 }
 
-func (m *Model) commandConfig(args []string) {
+func (m *Model) commandRevive(in command.Args) {
+	args := in.Positional
+	if len(args) != 1 {
+		m.setOutput("Command error", []string{"revive: usage: revive <id|ref> [--for 3d] [--note text]"}, OutputError, "revive", true)
+		m.status = "Command needs one thought id."
+		return
+	}
+	var settle time.Duration
+	if value, ok := in.Value("--for"); ok {
+		d, err := core.ParseSettleDuration(value)
+		if err != nil {
+			m.commandError(fmt.Errorf("revive: %w", err))
+			return
+		}
+		settle = d
+	}
+	note, err := commandNote(in)
+	if err != nil {
+		m.commandError(fmt.Errorf("revive: %w", err))
+		return
+	}
+	id, ok := m.resolveCommandID("revive", args[0])
+	if !ok {
+		return
+	}
+	if err := m.service.ReviveFor(id, note, settle); err != nil {
+		m.commandError(err)
+		return
+	}
+	m.reloadPreserving(id)
+	line := fmt.Sprintf("Revived #%d. It rests for %s.", id, core.FormatSettleDuration(core.SettleFor(settle)))
+	m.status = line
+	m.setOutput("Revive", []string{line}, OutputCommand, "revive", false)
+}
+
+func (m *Model) commandTrash(in command.Args) {
+	args := in.Positional
+	if len(args) != 0 {
+		m.setOutput("Command error", []string{"trash: usage: trash"}, OutputError, "trash", true)
+		m.status = "Trash takes no arguments."
+		return
+	}
+	thoughts, err := m.service.Trash()
+	if err != nil {
+		m.commandError(err)
+		return
+	}
+	lines := []string{fmt.Sprintf("Trash, kept for %s", core.FormatSettleDuration(core.ReleaseGracePeriod))}
+	if len(thoughts) == 0 {
+		lines = append(lines, "The trash is empty.")
+	}
+	for _, th := range thoughts {
+		lines = append(lines, fmt.Sprintf("#%-3d purged %-16s %s",
+			th.ID,
+			core.PurgeAt(th).UTC().Format("2006-01-02 15:04"),
+			oneLine(th.Content, 60),
+		))
+	}
+	m.setOutput("Trash", lines, OutputCommand, "trash", len(lines) > 3)
+	m.status = "Trash shown."
+}
+
+func (m *Model) commandRestore(in command.Args) {
+	args := in.Positional
+	if len(args) != 1 {
+		m.setOutput("Command error", []string{"restore: usage: restore <id|ref>"}, OutputError, "restore", true)
+		m.status = "Command needs one thought id."
+		return
+	}
+	id, ok := m.resolveCommandID("restore", args[0])
+	if !ok {
+		return
+	}
+	state, err := m.service.Restore(id)
+	if err != nil {
+		m.commandError(err)
+		return
+	}
+	m.reloadPreserving(id)
+	line := fmt.Sprintf("Restored #%d. It is %s again.", id, state)
+	m.status = line
+	m.setOutput("Restore", []string{line}, OutputCommand, "restore", false)
+}
+
+func (m *Model) commandTag(in command.Args) {
+	args := in.Positional
+	if len(args) == 0 {
+		tags, err := m.service.Tags()
+		if err != nil {
+			m.commandError(err)
+			return
+		}
+		lines := []string{"Tags"}
+		if len(tags) == 0 {
+			lines = append(lines, "No tags yet.")
+		}
+		for _, tag := range tags {
+			lines = append(lines, fmt.Sprintf("%-24s %d", "#"+tag.Name, tag.Count))
+		}
+		m.setOutput("Tags", lines, OutputCommand, "tag", len(lines) > 3)
+		m.status = "Tags shown."
+		return
+	}
+	if !core.LooksLikeThoughtRef(args[0]) {
+		if len(args) != 1 {
+			m.setOutput("Command error", []string{"tag: usage: tag <id> [+tag] [-tag]"}, OutputError, "tag", true)
+			m.status = "Command needs a thought id."
+			return
+		}
+		tag, err := core.NormalizeTag(args[0])
+		if err != nil {
+			m.commandError(err)
+			return
+		}
+		m.query = "#" + tag
+		m.search.SetValue(m.query)
+		m.pushSearchHistory(m.query)
+		m.reloadPreserving(0)
+		m.setOutput("Tag", []string{fmt.Sprintf("%d thought(s) tagged #%s.", len(m.snapshot.Thoughts), tag)}, OutputSearch, "tag", false)
+		m.status = "Tag search applied."
+		return
+	}
+	id, ok := m.resolveCommandID("tag", args[0])
+	if !ok {
+		return
+	}
+	var add, remove []string
+	for _, arg := range args[1:] {
+		switch {
+		case strings.HasPrefix(arg, "+"):
+			add = append(add, arg[1:])
+		case strings.HasPrefix(arg, "-"):
+			remove = append(remove, arg[1:])
+		default:
+			add = append(add, arg)
+		}
+	}
+	item, err := m.service.Thought(id)
+	if err != nil {
+		m.commandError(err)
+		return
+	}
+	tags := item.Thought.Tags
+	if len(add) > 0 || len(remove) > 0 {
+		tags, err = m.service.Tag(id, add, remove)
+		if err != nil {
+			m.commandError(err)
+			return
+		}
+		m.reloadPreserving(id)
+	}
+	line := fmt.Sprintf("#%d has no tags.", id)
+	if len(tags) > 0 {
+		line = fmt.Sprintf("#%d tags: %s", id, tagChips(tags))
+	}
+	m.setOutput("Tag", []string{line}, OutputCommand, "tag", false)
+	m.status = fmt.Sprintf("Tags for #%d.", id)
+}
+
+func (m *Model) commandConfig(in command.Args) {
+	args := in.Positional
 	cfg, err := config.Load()
 	if err != nil {
 		m.commandError(fmt.Errorf("config: %w", err))
@@ -259,13 +497,13 @@ func (m *Model) commandConfig(args []string) {
 			m.status = "Config duration missing."
 			return
 		}
-		dur, err := time.ParseDuration(args[1])
+		dur, err := core.ParseSettleDuration(args[1])
 		if err != nil {
 			m.setOutput("Command error", []string{"config: invalid settle duration"}, OutputError, "config", true)
 			m.status = "Config duration was invalid."
 			return
 		}
-		cfg.SettleDuration = dur.String()
+		cfg.SettleDuration = core.FormatSettleDuration(dur)
 		core.SettleDuration = dur
 		if err := config.Save(cfg); err != nil {
 			m.commandError(fmt.Errorf("config: %w", err))
@@ -276,10 +514,103 @@ func (m *Model) commandConfig(args []string) {
 		m.status = "Config saved."
 		return
 	}
+	if len(args) >= 1 && (args[0] == "--reindexOnRelease" || args[0] == "reindexOnRelease") {
+		if len(args) < 2 {
+			m.setOutput("Command error", []string{"config reindexOnRelease: provide true or false"}, OutputError, "config", true)
+			m.status = "Config value missing."
+			return
+		}
+		reindex, err := strconv.ParseBool(args[1])
+		if err != nil {
+			m.setOutput("Command error", []string{"config: reindexOnRelease must be true or false"}, OutputError, "config", true)
+			m.status = "Config value was invalid."
+			return
+		}
+		cfg.ReindexOnRelease = &reindex
+		core.ReindexOnRelease = reindex
+		if err := config.Save(cfg); err != nil {
+			m.commandError(fmt.Errorf("config: %w", err))
+			return
+		}
+		lines := configLines(cfg)
+		m.setOutput("Config", lines, OutputCommand, "config", len(lines) > 3)
+		m.status = "Config saved."
+		return
+	}
+	if len(args) >= 1 && (args[0] == "--releaseGracePeriod" || args[0] == "releaseGracePeriod") {
+		if len(args) < 2 || strings.TrimSpace(args[1]) == "" {
+			m.setOutput("Command error", []string{"config releaseGracePeriod: provide a duration, for example 30d"}, OutputError, "config", true)
+			m.status = "Config duration missing."
+			return
+		}
+		grace, err := core.ParseSettleDuration(args[1])
+		if err != nil {
+			m.setOutput("Command error", []string{"config: invalid release grace period"}, OutputError, "config", true)
+			m.status = "Config duration was invalid."
+			return
+		}
+		cfg.ReleaseGracePeriod = core.FormatSettleDuration(grace)
+		core.ReleaseGracePeriod = grace
+		if err := config.Save(cfg); err != nil {
+			m.commandError(fmt.Errorf("config: %w", err))
+			return
+		}
+		lines := configLines(cfg)
+		m.setOutput("Config", lines, OutputCommand, "config", len(lines) > 3)
+		m.status = "Config saved."
+		return
+	}
+	if len(args) >= 1 && (args[0] == "--journalMode" || args[0] == "journalMode") {
+		if len(args) < 2 || strings.TrimSpace(args[1]) == "" {
+			m.setOutput("Command error", []string{"config journalMode: provide a mode, for example wal"}, OutputError, "config", true)
+			m.status = "Config value missing."
+			return
+		}
+		mode, err := config.ParseJournalMode(args[1])
+		if err != nil {
+			m.setOutput("Command error", []string{"config: " + err.Error()}, OutputError, "config", true)
+			m.status = "Config value was invalid."
+			return
+		}
+		cfg.JournalMode = mode
+		m.saveConnectionConfig(cfg)
+		return
+	}
+	if len(args) >= 1 && (args[0] == "--busyTimeout" || args[0] == "busyTimeout") {
+		if len(args) < 2 || strings.TrimSpace(args[1]) == "" {
+			m.setOutput("Command error", []string{"config busyTimeout: provide a duration, for example 5s"}, OutputError, "config", true)
+			m.status = "Config duration missing."
+			return
+		}
+		timeout, err := config.ParseBusyTimeout(args[1])
+		if err != nil {
+			m.setOutput("Command error", []string{"config: " + err.Error()}, OutputError, "config", true)
+			m.status = "Config duration was invalid."
+			return
+		}
+		cfg.BusyTimeout = timeout.String()
+		m.saveConnectionConfig(cfg)
+		return
+	}
 	m.setOutput("Command error", []string{fmt.Sprintf("config: unknown argument %s", strings.Join(args, " "))}, OutputError, "config", true)
 	m.status = "Config command was not recognized."
 }
 
+// resolveCommandID turns a numeric ID or stable ref into a thought ID, reporting invalid input as command output.
+func (m *Model) resolveCommandID(name string, arg string) (int64, bool) {
+	if !core.LooksLikeThoughtRef(arg) {
+		m.setOutput("Command error", []string{name + ": invalid id"}, OutputError, name, true)
+		m.status = "Command id was not valid."
+		return 0, false
+	}
+	id, err := m.service.ResolveID(arg)
+	if err != nil {
+		m.commandError(err)
+		return 0, false
+	}
+	return id, true
+}
+
 func (m *Model) commandError(err error) {
 	m.status = err.Error()
 	m.setOutput("Command error", []string{err.Error()}, OutputError, "command", true)
@@ -307,51 +638,72 @@ func (m *Model) startTendByID(id int64) {
 	m.status = ""
 }
 
+// commandNote reads --note, rejecting one that is only whitespace.
+func commandNote(in command.Args) (*string, error) {
+	value, ok := in.Value("--note")
+	if !ok {
+		return nil, nil
+	}
+	if strings.TrimSpace(value) == "" {
+		return nil, fmt.Errorf("--note is empty")
+	}
+	return &value, nil
+}
+
+// commandFeeling reads --valence and --energy; "none" and an absent flag both leave the field unset.
+func commandFeeling(in command.Args) (*int, *int, error) {
+	var values [2]*int
+	for i, name := range []string{"--valence", "--energy"} {
+		value, ok := in.Value(name)
+		if !ok {
+			continue
+		}
+		parsed, err := core.ParseFeelingValue(value)
+		if err != nil {
+			return nil, nil, err
+		}
+		values[i] = parsed
+	}
+	return values[0], values[1], nil
+}
+
 func commandHelp(args []string) []string {
 	if len(args) == 0 {
 		lines := []string{"Peony commands"}
-		for _, spec := range commandSpecs {
-			lines = append(lines, fmt.Sprintf("%-28s %s", spec.Usage, spec.Help))
+		for _, spec := range command.Specs {
+			if spec.InBloom() {
+				lines = append(lines, fmt.Sprintf("%-28s %s", spec.Bloom, spec.Summary))
+			}
 		}
 		return lines
 	}
-	name := canonicalCommand(strings.TrimPrefix(args[0], "--"))
-	for _, spec := range commandSpecs {
-		if spec.Name == name {
-			return []string{
-				"peony " + spec.Name,
-				spec.Help,
-				"Usage: " + spec.Usage,
-			}
-		}
+	spec, ok := command.Lookup(strings.TrimPrefix(args[0], "--"))
+	if !ok || !spec.InBloom() {
+		return []string{fmt.Sprintf("No help available for: %s", args[0])}
 	}
-	return []string{fmt.Sprintf("No help available for: %s", args[0])}
-}
-
-func canonicalCommand(value string) string {
-	for _, spec := range commandSpecs {
-		if value == spec.Name {
-			return spec.Name
-		}
-		for _, alias := range spec.Aliases {
-			if value == alias {
-				return spec.Name
-			}
+	lines := []string{
+		"peony " + spec.Name,
+		spec.Summary,
+		"Usage: " + spec.Bloom,
+	}
+	var flags []command.Flag
+	for _, flag := range spec.Flags {
+		if !flag.CLIOnly {
+			flags = append(flags, flag)
 		}
 	}
-	return value
+	if len(flags) > 0 {
+		lines = append(lines, "Options:")
+		lines = append(lines, strings.Split(strings.TrimSuffix(command.FlagTable(flags, "  "), "\n"), "\n")...)
+	}
+	return lines
 }
 
+// commandSuggestion returns the Bloom command a mistyped name most likely meant, or "".
 func commandSuggestion(value string) string {
-	for _, spec := range commandSpecs {
-		if strings.HasPrefix(spec.Name, value) || strings.HasPrefix(value, spec.Name) {
-			return spec.Name
-		}
-		for _, alias := range spec.Aliases {
-			if strings.HasPrefix(alias, value) || strings.HasPrefix(value, alias) {
-				return spec.Name
-			}
-		}
+	name := command.Suggest(value)
+	if spec, ok := command.Lookup(name); ok && spec.InBloom() {
+		return name
 	}
 	return ""
 }
@@ -404,15 +756,6 @@ func parseCommandLine(line string) ([]string, error) {
 	return args, nil
 }
 
-func parseState(value string) (core.State, bool) {
-	switch value {
-	case "captured", "resting", "tended", "evolved", "released", "archived":
-		return core.State(value), true
-	default:
-		return "", false
-	}
-}
-
 func thoughtTable(title string, thoughts []core.Thought, limit int) []string {
 	if len(thoughts) == 0 {
 		return []string{title, "No thoughts yet."}
@@ -445,10 +788,16 @@ func thoughtDetail(item app.BloomThought) []string {
 		t.Content,
 		"",
 		"META",
-		"Created:  " + t.CreatedAt.UTC().Format("2006-01-02 15:04Z"),
-		"Updated:  " + t.UpdatedAt.UTC().Format("2006-01-02 15:04Z"),
-		"Eligible: " + t.EligibilityAt.UTC().Format("2006-01-02 15:04Z"),
+		"Ref:      " + t.PublicID,
+	}
+	if len(t.Tags) > 0 {
+		lines = append(lines, "Tags:     "+tagChips(t.Tags))
 	}
+	lines = append(lines,
+		"Created:  "+t.CreatedAt.UTC().Format("2006-01-02 15:04Z"),
+		"Updated:  "+t.UpdatedAt.UTC().Format("2006-01-02 15:04Z"),
+		"Eligible: "+t.EligibilityAt.UTC().Format("2006-01-02 15:04Z"),
+	)
 	if len(item.Events) > 0 {
 		lines = append(lines, "", "EVENTS")
 		for _, event := range item.Events {
@@ -474,6 +823,19 @@ func gardenThoughts(items []app.GardenThought) []core.Thought {
 	return thoughts
 }
 
+// saveConnectionConfig saves a changed journal mode or busy timeout. Bloom's own connection keeps
+// the settings it opened with; they apply from the next time the garden is opened.
+func (m *Model) saveConnectionConfig(cfg config.Config) {
+	if err := config.Save(cfg); err != nil {
+		m.commandError(fmt.Errorf("config: %w", err))
+		return
+	}
+	storage.Connection = config.Connection(cfg)
+	lines := append(configLines(cfg), "", "Connection settings apply the next time the garden is opened.")
+	m.setOutput("Config", lines, OutputCommand, "config", true)
+	m.status = "Config saved."
+}
+
 func configLines(cfg config.Config) []string {
 	path, err := config.ConfigPath()
 	lines := []string{}
@@ -487,5 +849,10 @@ func configLines(cfg config.Config) []string {
 		lines = append(lines, "Editor: "+cfg.Editor)
 	}
 	lines = append(lines, "SettleDuration: "+config.SettleDuration(cfg).String())
+	lines = append(lines, fmt.Sprintf("ReindexOnRelease: %t", config.ReindexOnRelease(cfg)))
+	lines = append(lines, "ReleaseGracePeriod: "+core.FormatSettleDuration(config.ReleaseGracePeriod(cfg)))
+	connection := config.Connection(cfg)
+	lines = append(lines, "JournalMode: "+connection.JournalMode)
+	lines = append(lines, "BusyTimeout: "+connection.BusyTimeout.String())
 	return lines
 }
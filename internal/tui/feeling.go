@@ -0,0 +1,98 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/divijg19/peony/internal/core"
+)
+
+// feelingField identifies which half of a feeling picker has focus.
+type feelingField int
+
+const (
+	feelingValence feelingField = iota
+	feelingEnergy
+)
+
+// feelingInput holds the valence and energy chosen in a capture or tend sheet. nil means unnamed.
+type feelingInput struct {
+	valence *int
+	energy  *int
+}
+
+func newFeelingInput(valence, energy *int) feelingInput {
+	return feelingInput{valence: copyInt(valence), energy: copyInt(energy)}
+}
+
+// step moves one field by delta. An unnamed field starts from its middle: valence 0, energy 3.
+func (f *feelingInput) step(field feelingField, delta int) {
+	target, low, high, middle := &f.valence, core.MinValence, core.MaxValence, 0
+	if field == feelingEnergy {
+		target, low, high, middle = &f.energy, core.MinEnergy, core.MaxEnergy, 3
+	}
+	next := middle
+	if *target != nil {
+		next = clampInt(**target+delta, low, high)
+	}
+	*target = &next
+}
+
+// clear leaves one field unnamed.
+func (f *feelingInput) clear(field feelingField) {
+	if field == feelingEnergy {
+		f.energy = nil
+		return
+	}
+	f.valence = nil
+}
+
+// lines renders the picker. focused is the field with focus, or -1 when neither has it.
+func (f feelingInput) lines(focused feelingField) []string {
+	valence := make([]string, 0, core.MaxValence-core.MinValence+1)
+	for v := core.MinValence; v <= core.MaxValence; v++ {
+		valence = append(valence, feelingChoice(core.FormatValence(&v), f.valence != nil && *f.valence == v))
+	}
+	energy := make([]string, 0, core.MaxEnergy-core.MinEnergy+1)
+	for e := core.MinEnergy; e <= core.MaxEnergy; e++ {
+		energy = append(energy, feelingChoice(core.FormatEnergy(&e), f.energy != nil && *f.energy == e))
+	}
+	return []string{
+		feelingLabel("Valence", focused == feelingValence) + "  " + strings.Join(valence, " ") + feelingUnset(f.valence),
+		feelingLabel("Energy", focused == feelingEnergy) + "   " + strings.Join(energy, " ") + feelingUnset(f.energy),
+	}
+}
+
+func feelingChoice(label string, selected bool) string {
+	if selected {
+		return activeLabelStyle.Render("[" + label + "]")
+	}
+	return subtleStyle.Render(" " + label + " ")
+}
+
+func feelingLabel(label string, focused bool) string {
+	if focused {
+		return keyStyle.Render("> " + label)
+	}
+	return labelStyle.Render("  " + label)
+}
+
+func feelingUnset(value *int) string {
+	if value == nil {
+		return subtleStyle.Render("  (unnamed)")
+	}
+	return ""
+}
+
+// feelingSummary is a short form for the detail pane, e.g. "Feeling  valence +1, energy 4".
+func feelingSummary(valence, energy *int) string {
+	return fmt.Sprintf("Feeling  %s", core.FormatFeeling(valence, energy))
+}
+
+func copyInt(v *int) *int {
+	if v == nil {
+		return nil
+	}
+	c := *v
+	return &c
+}
@@ -38,12 +38,15 @@ var filterKeyHints = []keyHint{
 }
 
 var captureKeyHints = []keyHint{
+	{Key: "tab", Label: "field"},
+	{Key: "←/→", Label: "feeling"},
 	{Key: "ctrl+s", Label: "save"},
 	{Key: "esc", Label: "cancel"},
 }
 
 var tendKeyHints = []keyHint{
 	{Key: "tab", Label: "field"},
+	{Key: "←/→", Label: "feeling"},
 	{Key: "ctrl+s", Label: "mark tended"},
 	{Key: "esc", Label: "cancel"},
 }
@@ -82,11 +85,11 @@ func keyHelpLines(mode Mode) []string {
 		labelStyle.Render("Work"),
 		"a capture a thought",
 		"t tend a ready thought",
-		"r rest a tended thought",
-		"e evolve, A remember, x release permanently",
+		"r rest a tended thought, or fill Rest for (3d, 1w) while tending",
+		"e evolve, A remember, x release to the trash (:restore brings it back)",
 		"",
 		labelStyle.Render("Find"),
-		"/ search",
+		"/ search: \"a phrase\", prefix*, AND, OR, NOT, #tag",
 		": command prompt",
 		"f choose what is shown",
 		"R reload",
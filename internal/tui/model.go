@@ -3,6 +3,7 @@ package tui
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/bubbles/textarea"
 	"github.com/charmbracelet/bubbles/textinput"
@@ -103,6 +104,10 @@ func NewModel(service *app.Service) Model {
 	m.tendNote.SetWidth(60)
 	m.tendNote.SetHeight(4)
 
+	m.tendRestFor = textinput.New()
+	m.tendRestFor.CharLimit = 24
+	m.tendRestFor.Width = 24
+
 	m.search = textinput.New()
 	m.search.Placeholder = "search thoughts, states, notes, or ids"
 	m.search.CharLimit = 120
@@ -133,6 +138,8 @@ type Model struct {
 	status           string
 	output           CommandResult
 	pendingReleaseID int64
+	// pendingReleaseNote is the --note given to :release, kept until the release is confirmed.
+	pendingReleaseNote *string
 
 	selected     int
 	queueOffset  int
@@ -140,13 +147,17 @@ type Model struct {
 
 	snapshot app.BloomSnapshot
 
-	addBox      textarea.Model
-	tendContent textarea.Model
-	tendNote    textarea.Model
-	tendFocus   int
-	tendID      int64
-	search      textinput.Model
-	command     textinput.Model
+	addBox         textarea.Model
+	captureFocus   int
+	captureFeeling feelingInput
+	tendContent    textarea.Model
+	tendNote       textarea.Model
+	tendFocus      int
+	tendFeeling    feelingInput
+	tendRestFor    textinput.Model
+	tendID         int64
+	search         textinput.Model
+	command        textinput.Model
 
 	searchHistory       []string
 	searchHistoryIndex  int
@@ -256,6 +267,8 @@ func (m Model) updateBrowse(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.focus = FocusPrompt
 		m.addBox.Reset()
 		m.addBox.Focus()
+		m.captureFocus = 0
+		m.captureFeeling = feelingInput{}
 		m.status = ""
 	case "/":
 		m.mode = ModeSearch
@@ -303,7 +316,7 @@ func (m Model) updateCapture(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.status = "Capture cancelled."
 		return m, nil
 	case "ctrl+s":
-		id, err := m.service.Capture(m.addBox.Value())
+		id, err := m.service.CaptureFeeling(m.addBox.Value(), m.captureFeeling.valence, m.captureFeeling.energy)
 		if err != nil {
 			m.status = err.Error()
 			return m, nil
@@ -314,6 +327,19 @@ func (m Model) updateCapture(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.reloadPreserving(id)
 		m.status = fmt.Sprintf("Saved as #%d.", id)
 		return m, nil
+	case "tab":
+		m.captureFocus = (m.captureFocus + 1) % 3
+		if m.captureFocus == 0 {
+			m.addBox.Focus()
+		} else {
+			m.addBox.Blur()
+		}
+		return m, nil
+	}
+
+	if m.captureFocus > 0 {
+		updateFeeling(&m.captureFeeling, feelingField(m.captureFocus-1), msg)
+		return m, nil
 	}
 
 	var cmd tea.Cmd
@@ -321,6 +347,18 @@ func (m Model) updateCapture(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	return m, cmd
 }
 
+// updateFeeling lets left/right (or h/l) move the focused feeling field and backspace unname it.
+func updateFeeling(feeling *feelingInput, field feelingField, msg tea.KeyMsg) {
+	switch msg.String() {
+	case "left", "h", "down", "j":
+		feeling.step(field, -1)
+	case "right", "l", "up", "k":
+		feeling.step(field, 1)
+	case "backspace", "delete", "x":
+		feeling.clear(field)
+	}
+}
+
 func (m Model) updateSearch(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
 	case "esc":
@@ -464,10 +502,11 @@ func (m Model) updateTend(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.focus = FocusQueue
 		m.tendContent.Blur()
 		m.tendNote.Blur()
+		m.tendRestFor.Blur()
 		m.status = "Tend cancelled."
 		return m, nil
 	case "tab":
-		m.tendFocus = (m.tendFocus + 1) % 2
+		m.tendFocus = (m.tendFocus + 1) % tendFields
 		m.focusTendInput()
 		return m, nil
 	case "ctrl+s":
@@ -480,6 +519,15 @@ func (m Model) updateTend(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.focus = FocusQueue
 			return m, nil
 		}
+		var settle time.Duration
+		if value := strings.TrimSpace(m.tendRestFor.Value()); value != "" {
+			d, err := core.ParseSettleDuration(value)
+			if err != nil {
+				m.status = err.Error()
+				return m, nil
+			}
+			settle = d
+		}
 		noteValue := strings.TrimSpace(m.tendNote.Value())
 		var note *string
 		if noteValue != "" {
@@ -489,21 +537,40 @@ func (m Model) updateTend(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.status = err.Error()
 			return m, nil
 		}
+		if _, err := m.service.Feel(id, m.tendFeeling.valence, m.tendFeeling.energy); err != nil {
+			m.status = err.Error()
+			return m, nil
+		}
+		if settle > 0 {
+			if err := m.service.RestFor(id, nil, settle); err != nil {
+				m.status = err.Error()
+				return m, nil
+			}
+		}
 		m.mode = ModeBrowse
 		m.focus = FocusQueue
 		m.tendID = 0
 		m.tendContent.Blur()
 		m.tendNote.Blur()
+		m.tendRestFor.Blur()
 		m.reloadPreserving(id)
 		m.status = "Choose rest, evolve, archive, or release when it feels resolved."
+		if settle > 0 {
+			m.status = fmt.Sprintf("Tended. Resting for %s.", core.FormatSettleDuration(settle))
+		}
 		return m, nil
 	}
 
 	var cmd tea.Cmd
-	if m.tendFocus == 0 {
+	switch m.tendFocus {
+	case 0:
 		m.tendContent, cmd = m.tendContent.Update(msg)
-	} else {
+	case 1:
 		m.tendNote, cmd = m.tendNote.Update(msg)
+	case tendFieldRestFor:
+		m.tendRestFor, cmd = m.tendRestFor.Update(msg)
+	default:
+		updateFeeling(&m.tendFeeling, feelingField(m.tendFocus-2), msg)
 	}
 	return m, cmd
 }
@@ -518,7 +585,7 @@ func (m Model) updateReleaseConfirm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			return m, nil
 		}
 		oldIndex := m.selected
-		if err := m.service.ReleasePermanent(id); err != nil {
+		if err := m.service.Release(id, m.pendingReleaseNote); err != nil {
 			m.status = err.Error()
 			m.mode = ModeBrowse
 			m.focus = FocusQueue
@@ -527,13 +594,15 @@ func (m Model) updateReleaseConfirm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.mode = ModeBrowse
 		m.focus = FocusQueue
 		m.pendingReleaseID = 0
+		m.pendingReleaseNote = nil
 		m.reloadPreserving(0)
 		m.selectIndex(oldIndex)
-		m.status = fmt.Sprintf("Released #%d permanently.", id)
+		m.status = fmt.Sprintf("Released #%d to the trash. :restore %d brings it back.", id, id)
 	case "n", "N", "esc":
 		m.mode = ModeBrowse
 		m.focus = FocusQueue
 		m.pendingReleaseID = 0
+		m.pendingReleaseNote = nil
 		m.status = "Release cancelled."
 	}
 	return m, nil
@@ -555,6 +624,9 @@ func (m *Model) startTend() {
 	m.tendFocus = 0
 	m.tendContent.SetValue(item.Thought.Content)
 	m.tendNote.Reset()
+	m.tendFeeling = newFeelingInput(item.Thought.Valence, item.Thought.Energy)
+	m.tendRestFor.Reset()
+	m.tendRestFor.Placeholder = "later, or e.g. " + core.FormatSettleDuration(core.SettleDuration) + ", 3d, 1w"
 	m.focusTendInput()
 	m.status = ""
 }
@@ -594,7 +666,7 @@ func (m *Model) archiveSelected() {
 	if !ok {
 		return
 	}
-	if err := m.service.Archive(item.Thought.ID); err != nil {
+	if err := m.service.Archive(item.Thought.ID, nil); err != nil {
 		m.status = err.Error()
 		return
 	}
@@ -866,14 +938,24 @@ func (m *Model) applyFilterIndex(index int) {
 	}
 }
 
+// The tend sheet's fields, in tab order: content, note, valence, energy, then how long to rest.
+const (
+	tendFieldRestFor = 4
+	tendFields       = 5
+)
+
 func (m *Model) focusTendInput() {
-	if m.tendFocus == 0 {
+	m.tendContent.Blur()
+	m.tendNote.Blur()
+	m.tendRestFor.Blur()
+	switch m.tendFocus {
+	case 0:
 		m.tendContent.Focus()
-		m.tendNote.Blur()
-		return
+	case 1:
+		m.tendNote.Focus()
+	case tendFieldRestFor:
+		m.tendRestFor.Focus()
 	}
-	m.tendContent.Blur()
-	m.tendNote.Focus()
 }
 
 func (m *Model) resizeInputs() {
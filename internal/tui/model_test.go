@@ -13,6 +13,7 @@ import (
 	"github.com/divijg19/peony/internal/app"
 	"github.com/divijg19/peony/internal/core"
 	"github.com/divijg19/peony/internal/storage"
+	"github.com/divijg19/peony/internal/version"
 )
 
 func newTestModel(t *testing.T) Model {
@@ -154,6 +155,102 @@ func TestModelTendSaveCancelAndRest(t *testing.T) {
 
 }
 
+func TestCaptureAndTendSheetsNameFeelings(t *testing.T) {
+	withSettleDuration(t, 0)
+	m := sized(newTestModel(t), 120, 40)
+
+	m = press(m, runeKey('a'))
+	m.addBox.SetValue("a bright morning")
+	m = press(m, tea.KeyMsg{Type: tea.KeyTab})
+	m = press(m, tea.KeyMsg{Type: tea.KeyRight})
+	m = press(m, tea.KeyMsg{Type: tea.KeyRight})
+	m = press(m, tea.KeyMsg{Type: tea.KeyTab})
+	m = press(m, runeKey('h'))
+	m = press(m, runeKey('h'))
+	if !strings.Contains(m.View(), "[+1]") || !strings.Contains(m.View(), "[2]") {
+		t.Fatalf("capture sheet should show chosen feeling:\n%s", m.View())
+	}
+	if m.addBox.Value() != "a bright morning" {
+		t.Fatalf("feeling keys leaked into content: %q", m.addBox.Value())
+	}
+	m = press(m, tea.KeyMsg{Type: tea.KeyCtrlS})
+	item, ok := m.selectedItem()
+	if !ok || item.Thought.Valence == nil || *item.Thought.Valence != 1 || item.Thought.Energy == nil || *item.Thought.Energy != 2 {
+		t.Fatalf("captured feeling = %+v, want valence 1 energy 2", item.Thought)
+	}
+	if !strings.Contains(strings.Join(m.detailLines(80), "\n"), "Feeling  valence +1, energy 2") {
+		t.Fatalf("detail should show the feeling:\n%s", strings.Join(m.detailLines(80), "\n"))
+	}
+
+	m = press(m, runeKey('t'))
+	if m.tendFeeling.valence == nil || *m.tendFeeling.valence != 1 {
+		t.Fatalf("tend sheet should start from the current feeling, got %+v", m.tendFeeling)
+	}
+	for i := 0; i < 2; i++ {
+		m = press(m, tea.KeyMsg{Type: tea.KeyTab})
+	}
+	m = press(m, tea.KeyMsg{Type: tea.KeyBackspace})
+	m = press(m, tea.KeyMsg{Type: tea.KeyCtrlS})
+	item, _ = m.selectedItem()
+	if item.Thought.Valence != nil || item.Thought.Energy == nil || *item.Thought.Energy != 2 {
+		t.Fatalf("tended feeling = %+v, want valence cleared and energy 2", item.Thought)
+	}
+
+	var feels []string
+	for _, event := range item.Events {
+		if event.Kind == "feel" && event.Note != nil {
+			feels = append(feels, *event.Note)
+		}
+	}
+	if strings.Join(feels, "|") != "valence +1, energy 2|valence none, energy 2" {
+		t.Fatalf("feel events = %q", feels)
+	}
+}
+
+func TestTendSheetRestForRestsOnSave(t *testing.T) {
+	withSettleDuration(t, 0)
+	m := sized(newTestModel(t), 120, 40)
+	id, err := m.service.Capture("needs a long rest")
+	if err != nil {
+		t.Fatalf("capture: %v", err)
+	}
+	m.reloadPreserving(id)
+
+	m = press(m, runeKey('t'))
+	for i := 0; i < tendFieldRestFor; i++ {
+		m = press(m, tea.KeyMsg{Type: tea.KeyTab})
+	}
+	if !strings.Contains(m.View(), "> Rest for") {
+		t.Fatalf("tend sheet should focus Rest for:\n%s", m.View())
+	}
+	m.tendRestFor.SetValue("someday")
+	m = press(m, tea.KeyMsg{Type: tea.KeyCtrlS})
+	if m.mode != ModeTend || !strings.Contains(m.status, "invalid") {
+		t.Fatalf("bad duration should keep the sheet open, mode = %v status = %q", m.mode, m.status)
+	}
+
+	before := time.Now().UTC()
+	m.tendRestFor.SetValue("1w")
+	m = press(m, tea.KeyMsg{Type: tea.KeyCtrlS})
+	if m.status != "Tended. Resting for 1w." {
+		t.Fatalf("status = %q", m.status)
+	}
+	item, err := m.service.Thought(id)
+	if err != nil {
+		t.Fatalf("thought: %v", err)
+	}
+	if item.Thought.CurrentState != core.StateResting {
+		t.Fatalf("state = %s, want resting", item.Thought.CurrentState)
+	}
+	if got := item.Thought.EligibilityAt.Sub(before); got < 7*24*time.Hour || got > 7*24*time.Hour+time.Minute {
+		t.Fatalf("eligible %v after tending, want about a week", got)
+	}
+	last := item.Events[len(item.Events)-1]
+	if last.SettleFor == nil || *last.SettleFor != 7*24*time.Hour {
+		t.Fatalf("rest event = %+v, want a week recorded", last)
+	}
+}
+
 func TestFocusedQueueFilteringSearchAndOrdering(t *testing.T) {
 	m := newTestModel(t)
 	withSettleDuration(t, 0)
@@ -189,7 +286,7 @@ func TestFocusedQueueFilteringSearchAndOrdering(t *testing.T) {
 	if err != nil {
 		t.Fatalf("capture archived: %v", err)
 	}
-	if err := m.service.Archive(archivedID); err != nil {
+	if err := m.service.Archive(archivedID, nil); err != nil {
 		t.Fatalf("archive: %v", err)
 	}
 
@@ -320,9 +417,13 @@ func TestCommandBarRunsReadableCommands(t *testing.T) {
 	if err != nil {
 		t.Fatalf("capture archived: %v", err)
 	}
-	if err := m.service.Archive(archivedID); err != nil {
+	if err := m.service.Archive(archivedID, nil); err != nil {
 		t.Fatalf("archive: %v", err)
 	}
+	item, err := m.service.Thought(id)
+	if err != nil {
+		t.Fatalf("thought: %v", err)
+	}
 	m.reloadPreserving(id)
 
 	for _, tc := range []struct {
@@ -330,15 +431,30 @@ func TestCommandBarRunsReadableCommands(t *testing.T) {
 		want    string
 	}{
 		{"help", "Peony commands"},
+		{"view " + item.Thought.PublicID, "visible alpha"},
+		{"view zffffffffff", "not found"},
+		{fmt.Sprintf("tag %d +work", id), fmt.Sprintf("#%d tags: #work", id)},
+		{"tag", "#work"},
+		{fmt.Sprintf("view %d", id), "Tags:     #work"},
 		{"help view", "peony view"},
 		{"view", "Visible thoughts"},
 		{fmt.Sprintf("view %d", id), "CONTENT"},
 		{"view archived", "archived omega"},
+		{"view --state archived,captured --sort id", "archived omega"},
+		{"view --state archived --tag work", "No thoughts yet."},
+		{"view --sort sideways", `unknown order "sideways"`},
+		{"view --created-after someday", "--created-after: invalid time"},
+		{"view blooming --sort id", "view: invalid filter"},
 		{"tend", "Ready to tend"},
-		{"version", "Peony v0.4"},
+		{"version", "Peony " + version.String()},
+		{"version", fmt.Sprintf("schema %d", storage.SchemaVersion)},
 		{"config", "Current configuration"},
 		{"later", "Unknown command"},
 		{"tui", "Bloom is already open"},
+		{"feel 1", "only available from the peony command line"},
+		{"tend --json", "--json is only available from the peony command line"},
+		{"revive 1 --for", "--for needs a value"},
+		{"help release", "--note text"},
 	} {
 		m = runCommand(m, tc.command)
 		if !strings.Contains(outputText(m), tc.want) {
@@ -347,6 +463,37 @@ func TestCommandBarRunsReadableCommands(t *testing.T) {
 	}
 }
 
+func TestTagCommandFiltersQueueAndShowsChips(t *testing.T) {
+	withSettleDuration(t, 0)
+	m := sized(newTestModel(t), 120, 32)
+	workID, err := m.service.Capture("work thought")
+	if err != nil {
+		t.Fatalf("capture work: %v", err)
+	}
+	if _, err := m.service.Capture("other thought"); err != nil {
+		t.Fatalf("capture other: %v", err)
+	}
+	if _, err := m.service.Tag(workID, []string{"work"}, nil); err != nil {
+		t.Fatalf("tag: %v", err)
+	}
+	m.reloadPreserving(workID)
+
+	m = runCommand(m, "tag #work")
+	if m.query != "#work" {
+		t.Fatalf("query = %q, want #work", m.query)
+	}
+	if len(m.snapshot.Thoughts) != 1 || m.snapshot.Thoughts[0].Thought.ID != workID {
+		t.Fatalf("tag filter snapshot = %+v", m.snapshot.Thoughts)
+	}
+	row := strings.Join(m.queueRow(m.snapshot.Thoughts[0], 80, false), "\n")
+	if !strings.Contains(row, "#work") {
+		t.Fatalf("queue row missing tag chip: %q", row)
+	}
+	if detail := strings.Join(m.detailLines(80), "\n"); !strings.Contains(detail, "#work") {
+		t.Fatalf("detail missing tag chip: %q", detail)
+	}
+}
+
 func TestCommandBarRunsMutatingAndTUIScreenCommands(t *testing.T) {
 	withSettleDuration(t, 0)
 	m := newTestModel(t)
@@ -385,6 +532,18 @@ func TestCommandBarRunsMutatingAndTUIScreenCommands(t *testing.T) {
 		t.Fatalf("evolve output = %+v", m.output.Lines)
 	}
 
+	m = runCommand(m, fmt.Sprintf("revive %d --for 2d", id))
+	if !strings.Contains(outputText(m), fmt.Sprintf("Revived #%d. It rests for 2d.", id)) {
+		t.Fatalf("revive output = %+v", m.output.Lines)
+	}
+	if item, err := m.service.Thought(id); err != nil || item.Thought.CurrentState != core.StateResting {
+		t.Fatalf("revived thought = %+v, err = %v; want resting", item.Thought, err)
+	}
+	m = runCommand(m, fmt.Sprintf("revive %d", id))
+	if m.output.Kind != OutputError || !strings.Contains(outputText(m), "only archived or evolved") {
+		t.Fatalf("reviving a resting thought output = %+v", m.output)
+	}
+
 	secondID, err := m.service.Capture("release candidate")
 	if err != nil {
 		t.Fatalf("capture release candidate: %v", err)
@@ -397,6 +556,18 @@ func TestCommandBarRunsMutatingAndTUIScreenCommands(t *testing.T) {
 	if _, err := m.service.Thought(secondID); err != nil {
 		t.Fatalf("release command should not delete before confirmation: %v", err)
 	}
+	m = press(m, runeKey('n'))
+
+	m = runCommand(m, fmt.Sprintf(`release %d --note "no longer true"`, secondID))
+	m = press(m, runeKey('y'))
+	item, err := m.service.Thought(secondID)
+	if err != nil {
+		t.Fatalf("thought after release: %v", err)
+	}
+	last := item.Events[len(item.Events)-1]
+	if item.Thought.CurrentState != core.StateReleased || last.Note == nil || *last.Note != "no longer true" {
+		t.Fatalf("released state=%s last event=%+v, want released with note", item.Thought.CurrentState, last)
+	}
 }
 
 func TestContextOutputOnlyForWideOverflow(t *testing.T) {
@@ -591,12 +762,12 @@ func TestPromptBarModes(t *testing.T) {
 
 	m = press(m, runeKey('x'))
 	release := m.View()
-	if !strings.Contains(release, "Release #1 permanently") || !strings.Contains(release, "reindexes local IDs") {
+	if !strings.Contains(release, "Release #1 to the trash") || !strings.Contains(release, "restored for 30d") {
 		t.Fatalf("release prompt incomplete: %q", release)
 	}
 }
 
-func TestReleasePermanentConfirmation(t *testing.T) {
+func TestReleaseConfirmationMovesThoughtToTrash(t *testing.T) {
 	withSettleDuration(t, 0)
 	m := newTestModel(t)
 	if _, err := m.service.Capture("first"); err != nil {
@@ -621,10 +792,22 @@ func TestReleasePermanentConfirmation(t *testing.T) {
 	if len(m.snapshot.Thoughts) != 1 {
 		t.Fatalf("thought count after release = %d, want 1", len(m.snapshot.Thoughts))
 	}
-	if got := m.snapshot.Thoughts[0].Thought.ID; got != 1 {
-		t.Fatalf("remaining id = %d, want reindexed 1", got)
+	if got := m.snapshot.Thoughts[0].Thought.ID; got != 2 {
+		t.Fatalf("remaining id = %d, want 2 with no reindex", got)
 	}
 	if got := m.snapshot.Thoughts[0].Thought.Content; got != "second" {
 		t.Fatalf("remaining content = %q, want second", got)
 	}
+	if !strings.Contains(m.status, "Released #1 to the trash") {
+		t.Fatalf("status = %q, want trash confirmation", m.status)
+	}
+
+	m = runCommand(m, "trash")
+	if !strings.Contains(strings.Join(m.output.Lines, "\n"), "#1") {
+		t.Fatalf("trash output = %q, want #1", m.output.Lines)
+	}
+	m = runCommand(m, "restore 1")
+	if len(m.snapshot.Thoughts) != 2 || !strings.Contains(m.status, "Restored #1. It is captured again.") {
+		t.Fatalf("restore: count=%d status=%q", len(m.snapshot.Thoughts), m.status)
+	}
 }
@@ -5,6 +5,8 @@ import (
 	"strings"
 
 	"github.com/charmbracelet/lipgloss"
+
+	"github.com/divijg19/peony/internal/core"
 )
 
 func (m Model) promptBarView(layout frameLayout) string {
@@ -128,6 +130,9 @@ func (m Model) capturePrompt() string {
 	if strings.TrimSpace(m.status) != "" {
 		return m.status
 	}
+	if m.captureFocus > 0 {
+		return "Name how it feels, if you like. Backspace leaves it unnamed."
+	}
 	return "Hold the thought in its original shape."
 }
 
@@ -138,6 +143,12 @@ func (m Model) tendPrompt() string {
 	if m.tendFocus == 1 {
 		return "Add a note if one belongs with this tending."
 	}
+	if m.tendFocus == tendFieldRestFor {
+		return "Choose how long it rests, like 3d or 1w. Leave it empty to decide later."
+	}
+	if m.tendFocus > 1 {
+		return "Name how it feels now, if it has changed."
+	}
 	return "Revise softly, then decide what comes next."
 }
 
@@ -177,15 +188,19 @@ func (m Model) outputPreview(width int) string {
 }
 
 func (m Model) releasePrompt(width int) string {
-	line := "Release this thought permanently? This deletes the thought, history, and reindexes local IDs."
-	if m.pendingReleaseID != 0 {
-		if item, err := m.service.Thought(m.pendingReleaseID); err == nil {
-			preview := oneLine(item.Thought.Content, maxInt(10, width-96))
-			line = fmt.Sprintf("Release #%d permanently? %s This deletes the thought, history, and reindexes local IDs.", item.Thought.ID, preview)
+	grace := core.FormatSettleDuration(core.ReleaseGracePeriod)
+	line := fmt.Sprintf("Release this thought to the trash? It can be restored for %s, then it is purged.", grace)
+	id := m.pendingReleaseID
+	if id == 0 {
+		if item, ok := m.selectedItem(); ok {
+			id = item.Thought.ID
+		}
+	}
+	if id != 0 {
+		if item, err := m.service.Thought(id); err == nil {
+			preview := oneLine(item.Thought.Content, maxInt(10, width-80))
+			line = fmt.Sprintf("Release #%d to the trash? %s It can be restored for %s, then it is purged.", item.Thought.ID, preview, grace)
 		}
-	} else if item, ok := m.selectedItem(); ok {
-		preview := oneLine(item.Thought.Content, maxInt(10, width-96))
-		line = fmt.Sprintf("Release #%d permanently? %s This deletes the thought, history, and reindexes local IDs.", item.Thought.ID, preview)
 	}
 	return line
 }
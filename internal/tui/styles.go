@@ -8,6 +8,7 @@ var (
 	activeLabelStyle  = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("223"))
 	metaStrongStyle   = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("187"))
 	metaStyle         = lipgloss.NewStyle().Foreground(lipgloss.Color("181"))
+	tagStyle          = lipgloss.NewStyle().Foreground(lipgloss.Color("180"))
 	subtleStyle       = lipgloss.NewStyle().Foreground(lipgloss.Color("248"))
 	hintStyle         = lipgloss.NewStyle().Foreground(lipgloss.Color("245"))
 	keyStyle          = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("230")).Background(lipgloss.Color("239")).Padding(0, 1)
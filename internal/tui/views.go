@@ -93,6 +93,9 @@ func (m Model) queueRows(width, height int) []string {
 func (m Model) queueRow(item app.BloomThought, width int, selected bool) []string {
 	preview := fmt.Sprintf("#%d  %s", item.Thought.ID, oneLine(item.Thought.Content, maxInt(8, width-6)))
 	meta := fmt.Sprintf("%s  |  tended %dx", m.readinessLabel(item), item.Thought.TendCounter)
+	if len(item.Thought.Tags) > 0 {
+		meta += "  |  " + tagChips(item.Thought.Tags)
+	}
 	if selected {
 		return []string{
 			selectedStyle.Width(width).Render(oneLine(preview, width)),
@@ -138,18 +141,25 @@ func (m Model) detailLines(width int) []string {
 		activeLabelStyle.Render("Selected thought"),
 		fmt.Sprintf("#%d  %s", t.ID, m.stateLabel(item)),
 		fmt.Sprintf("%s  |  tended %d times", m.readinessLabel(item), t.TendCounter),
-		"",
 	}
+	if len(t.Tags) > 0 {
+		lines = append(lines, tagStyle.Render(tagChips(t.Tags)))
+	}
+	lines = append(lines, "")
 	lines = append(lines, wrapText(t.Content, width, bodyTextStyle)...)
 	lines = append(lines,
 		"",
 		labelStyle.Render("When"),
+		fmt.Sprintf("Ref      %s", t.PublicID),
 		fmt.Sprintf("Created  %s", t.CreatedAt.UTC().Format("2006-01-02 15:04Z")),
 		fmt.Sprintf("Updated  %s", t.UpdatedAt.UTC().Format("2006-01-02 15:04Z")),
 	)
 	if t.LastTendedAt != nil {
 		lines = append(lines, fmt.Sprintf("Tended   %s", t.LastTendedAt.UTC().Format("2006-01-02 15:04Z")))
 	}
+	if t.Valence != nil || t.Energy != nil {
+		lines = append(lines, feelingSummary(t.Valence, t.Energy))
+	}
 	if len(item.Events) > 0 {
 		lines = append(lines, "", labelStyle.Render("History"))
 		for _, event := range item.Events {
@@ -157,6 +167,9 @@ func (m Model) detailLines(width int) []string {
 			if event.NextState != nil {
 				line += fmt.Sprintf(" -> %s", *event.NextState)
 			}
+			if event.SettleFor != nil {
+				line += " for " + core.FormatSettleDuration(*event.SettleFor)
+			}
 			lines = append(lines, line)
 			if event.Note != nil && strings.TrimSpace(*event.Note) != "" {
 				lines = append(lines, subtleStyle.Render("  "+oneLine(*event.Note, maxInt(8, width-2))))
@@ -166,6 +179,15 @@ func (m Model) detailLines(width int) []string {
 	return lines
 }
 
+// tagChips renders tags as space-separated #chips.
+func tagChips(tags []string) string {
+	chips := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		chips = append(chips, "#"+tag)
+	}
+	return strings.Join(chips, " ")
+}
+
 func (m Model) captureView(layout frameLayout) string {
 	body := lipgloss.JoinVertical(
 		lipgloss.Left,
@@ -173,6 +195,8 @@ func (m Model) captureView(layout frameLayout) string {
 		subtleStyle.Render("Leave a thought here exactly as it arrives."),
 		"",
 		m.addBox.View(),
+		"",
+		strings.Join(m.captureFeeling.lines(feelingField(m.captureFocus-1)), "\n"),
 	)
 	return renderBox(sheetStyle, layout.bodyWidth, layout.bodyHeight, body)
 }
@@ -187,6 +211,10 @@ func (m Model) tendView(layout frameLayout) string {
 		"",
 		labelStyle.Render("Note"),
 		m.tendNote.View(),
+		"",
+		strings.Join(m.tendFeeling.lines(feelingField(m.tendFocus-2)), "\n"),
+		"",
+		feelingLabel("Rest for", m.tendFocus == tendFieldRestFor) + "  " + m.tendRestFor.View(),
 	}, "\n")
 	return renderBox(sheetStyle, layout.bodyWidth, layout.bodyHeight, body)
 }
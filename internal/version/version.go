@@ -0,0 +1,116 @@
+// Package version reports which build of Peony is running. Release builds stamp it with -ldflags:
+//
+//	go build -ldflags "-X github.com/divijg19/peony/internal/version.version=v0.8.0 \
+//	    -X github.com/divijg19/peony/internal/version.commit=$(git rev-parse HEAD) \
+//	    -X github.com/divijg19/peony/internal/version.date=2026-10-18T12:00:00Z" ./cmd/peony
+//
+// Anything left unstamped is read from the module and VCS details Go embeds in every binary, so
+// go install and plain go build report something useful too.
+package version
+
+import (
+	"runtime/debug"
+	"strings"
+)
+
+// Stamped by -ldflags -X; empty in an unstamped build.
+var (
+	version string
+	commit  string
+	date    string
+)
+
+// Dev is the version of a build that was neither stamped nor installed from a tagged module.
+const Dev = "dev"
+
+// Info describes the running binary.
+type Info struct {
+	Version   string
+	Commit    string // full VCS revision, or "" when unknown
+	Date      string // build or commit time, RFC 3339, or "" when unknown
+	Modified  bool   // built from a tree with uncommitted changes
+	GoVersion string
+}
+
+// Get returns the stamped details, filling gaps from the embedded build info.
+func Get() Info {
+	info := Info{Version: version, Commit: commit, Date: date}
+	if build, ok := debug.ReadBuildInfo(); ok {
+		info = fromBuildInfo(info, build)
+	}
+	if info.Version == "" {
+		info.Version = Dev
+	}
+	return info
+}
+
+// String returns the version alone, such as "v0.8.0".
+func String() string {
+	return Get().Version
+}
+
+// fromBuildInfo fills every field of info that was not stamped from what Go recorded at build time.
+func fromBuildInfo(info Info, build *debug.BuildInfo) Info {
+	if info.Version == "" && build.Main.Version != "" && build.Main.Version != "(devel)" {
+		info.Version = build.Main.Version
+	}
+	info.GoVersion = build.GoVersion
+
+	stamped := info.Commit != ""
+	for _, setting := range build.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			if !stamped {
+				info.Commit = setting.Value
+			}
+		case "vcs.time":
+			if info.Date == "" {
+				info.Date = setting.Value
+			}
+		case "vcs.modified":
+			if !stamped {
+				info.Modified = setting.Value == "true"
+			}
+		}
+	}
+	return info
+}
+
+// Summary fits the details on one line, such as "v0.8.0 (0123456789ab, 2026-10-18)".
+func (i Info) Summary() string {
+	var details []string
+	if i.Commit != "" {
+		commit := i.Commit[:min(len(i.Commit), 12)]
+		if i.Modified {
+			commit += "+modified"
+		}
+		details = append(details, commit)
+	}
+	if i.Date != "" {
+		day, _, _ := strings.Cut(i.Date, "T")
+		details = append(details, day)
+	}
+	if len(details) == 0 {
+		return i.Version
+	}
+	return i.Version + " (" + strings.Join(details, ", ") + ")"
+}
+
+// Lines lays the details out for peony version --verbose and Bloom's :version.
+func (i Info) Lines() []string {
+	lines := []string{"Peony " + i.Version}
+	if i.Commit != "" {
+		commit := i.Commit
+		if i.Modified {
+			commit += " (modified)"
+		}
+		lines = append(lines, "Commit:  "+commit)
+	}
+	if i.Date != "" {
+		lines = append(lines, "Built:   "+i.Date)
+	}
+	if i.GoVersion != "" {
+		lines = append(lines, "Go:      "+strings.TrimPrefix(i.GoVersion, "go"))
+	}
+	return lines
+}
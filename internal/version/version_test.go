@@ -0,0 +1,62 @@
+package version
+
+import (
+	"reflect"
+	"runtime/debug"
+	"testing"
+)
+
+func TestFromBuildInfoPrefersStampedValues(t *testing.T) {
+	build := &debug.BuildInfo{
+		GoVersion: "go1.26.0",
+		Main:      debug.Module{Path: "github.com/divijg19/peony", Version: "v0.8.1"},
+		Settings: []debug.BuildSetting{
+			{Key: "vcs.revision", Value: "0123456789abcdef"},
+			{Key: "vcs.time", Value: "2026-10-01T09:00:00Z"},
+			{Key: "vcs.modified", Value: "true"},
+		},
+	}
+
+	got := fromBuildInfo(Info{}, build)
+	want := Info{Version: "v0.8.1", Commit: "0123456789abcdef", Date: "2026-10-01T09:00:00Z", Modified: true, GoVersion: "go1.26.0"}
+	if got != want {
+		t.Fatalf("unstamped = %+v, want %+v", got, want)
+	}
+
+	got = fromBuildInfo(Info{Version: "v0.9.0", Commit: "fedcba", Date: "2026-10-18T12:00:00Z"}, build)
+	want = Info{Version: "v0.9.0", Commit: "fedcba", Date: "2026-10-18T12:00:00Z", GoVersion: "go1.26.0"}
+	if got != want {
+		t.Fatalf("stamped = %+v, want %+v", got, want)
+	}
+
+	build.Main.Version = "(devel)"
+	build.Settings = nil
+	if got := fromBuildInfo(Info{}, build); got.Version != "" || got.Commit != "" {
+		t.Fatalf("local build = %+v, want no version or commit", got)
+	}
+}
+
+func TestLinesShowOnlyWhatIsKnown(t *testing.T) {
+	if got := (Info{Version: Dev}).Lines(); !reflect.DeepEqual(got, []string{"Peony dev"}) {
+		t.Fatalf("bare lines = %q", got)
+	}
+	if got := (Info{Version: Dev}).Summary(); got != "dev" {
+		t.Fatalf("bare summary = %q", got)
+	}
+
+	got := Info{Version: "v0.8.0", Commit: "abc123", Date: "2026-10-18T12:00:00Z", Modified: true, GoVersion: "go1.26.0"}.Lines()
+	want := []string{
+		"Peony v0.8.0",
+		"Commit:  abc123 (modified)",
+		"Built:   2026-10-18T12:00:00Z",
+		"Go:      1.26.0",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("lines = %q, want %q", got, want)
+	}
+
+	summary := Info{Version: "v0.8.0", Commit: "0123456789abcdef", Date: "2026-10-18T12:00:00Z", Modified: true}.Summary()
+	if summary != "v0.8.0 (0123456789ab+modified, 2026-10-18)" {
+		t.Fatalf("summary = %q", summary)
+	}
+}
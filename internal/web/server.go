@@ -0,0 +1,240 @@
+package web
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/divijg19/peony/internal/app"
+	"github.com/divijg19/peony/internal/core"
+)
+
+// DefaultPort is the local port used by `peony web` when none is given.
+const DefaultPort = 7733
+
+// Server renders a read-only window into the garden.
+type Server struct {
+	service *app.Service
+	pages   *template.Template
+}
+
+// New creates a Server bound to a service. The service should be opened read-only.
+func New(service *app.Service) (*Server, error) {
+	if service == nil {
+		return nil, fmt.Errorf("web: service is nil")
+	}
+	pages, err := template.New("layout").Funcs(templateFuncs).Parse(layoutTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("web: parse layout: %w", err)
+	}
+	if _, err := pages.New("list").Parse(listTemplate); err != nil {
+		return nil, fmt.Errorf("web: parse list: %w", err)
+	}
+	if _, err := pages.New("thought").Parse(thoughtTemplate); err != nil {
+		return nil, fmt.Errorf("web: parse thought: %w", err)
+	}
+	return &Server{service: service, pages: pages}, nil
+}
+
+// ListenAddr returns the loopback address for port. The WebUI never binds to other interfaces.
+func ListenAddr(port int) string {
+	return net.JoinHostPort("127.0.0.1", strconv.Itoa(port))
+}
+
+// Handler returns the HTTP routes for the WebUI.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", s.handleList(app.BloomFilterReady))
+	mux.HandleFunc("GET /ready", s.handleList(app.BloomFilterReady))
+	mux.HandleFunc("GET /resting", s.handleList(app.BloomFilterResting))
+	mux.HandleFunc("GET /all", s.handleList(app.BloomFilterAll))
+	mux.HandleFunc("GET /thoughts/{id}", s.handleThought)
+	return mux
+}
+
+// Run opens the configured store read-only and serves the WebUI on the loopback port until the process stops.
+func Run(port int) int {
+	service, closeFn, err := app.OpenReadOnly()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "web: %v\n", err)
+		return 1
+	}
+	defer closeFn()
+
+	server, err := New(service)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "web: %v\n", err)
+		return 1
+	}
+
+	addr := ListenAddr(port)
+	httpServer := &http.Server{
+		Addr:              addr,
+		Handler:           server.Handler(),
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	fmt.Printf("Peony web is open at http://%s (read-only). Press Ctrl+C to close it.\n", addr)
+	if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		fmt.Fprintf(os.Stderr, "web: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+type listPage struct {
+	Title    string
+	Filter   app.BloomFilterKind
+	Query    string
+	Snapshot app.BloomSnapshot
+	Empty    string
+}
+
+type thoughtPage struct {
+	Title string
+	Item  app.BloomThought
+}
+
+func (s *Server) handleList(filter app.BloomFilterKind) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := strings.TrimSpace(r.URL.Query().Get("q"))
+		snapshot, err := s.service.SnapshotBloom(filter, query)
+		if err != nil {
+			http.Error(w, "The garden could not be read right now.", http.StatusInternalServerError)
+			return
+		}
+		s.render(w, "list", listPage{
+			Title:    filterTitle(filter),
+			Filter:   filter,
+			Query:    query,
+			Snapshot: snapshot,
+			Empty:    emptyText(filter, query),
+		})
+	}
+}
+
+func (s *Server) handleThought(w http.ResponseWriter, r *http.Request) {
+	id, err := s.service.ResolveID(r.PathValue("id"))
+	if err != nil || id <= 0 {
+		http.NotFound(w, r)
+		return
+	}
+	item, err := s.service.Thought(id)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	s.render(w, "thought", thoughtPage{
+		Title: fmt.Sprintf("#%d", item.Thought.ID),
+		Item:  item,
+	})
+}
+
+func (s *Server) render(w http.ResponseWriter, name string, data any) {
+	var body strings.Builder
+	if err := s.pages.ExecuteTemplate(&body, name, data); err != nil {
+		http.Error(w, "The page could not be drawn.", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	_, _ = w.Write([]byte(body.String()))
+}
+
+func filterTitle(filter app.BloomFilterKind) string {
+	switch filter {
+	case app.BloomFilterResting:
+		return "Resting"
+	case app.BloomFilterAll:
+		return "All"
+	default:
+		return "Ready"
+	}
+}
+
+func emptyText(filter app.BloomFilterKind, query string) string {
+	if query != "" {
+		return "No matching thought found. Nothing is wrong."
+	}
+	switch filter {
+	case app.BloomFilterResting:
+		return "Your thoughts are settling."
+	case app.BloomFilterAll:
+		return "Nothing is asking for your attention."
+	default:
+		return "Nothing needs you right now."
+	}
+}
+
+var templateFuncs = template.FuncMap{
+	"stamp": func(t time.Time) string {
+		if t.IsZero() {
+			return "later"
+		}
+		return t.UTC().Format("2006-01-02 15:04Z")
+	},
+	"readiness": readiness,
+	"transition": func(event core.Event) string {
+		switch {
+		case event.PreviousState != nil && event.NextState != nil && event.SettleFor != nil:
+			return fmt.Sprintf("%s → %s for %s", *event.PreviousState, *event.NextState, core.FormatSettleDuration(*event.SettleFor))
+		case event.PreviousState != nil && event.NextState != nil:
+			return fmt.Sprintf("%s → %s", *event.PreviousState, *event.NextState)
+		case event.NextState != nil:
+			return string(*event.NextState)
+		case event.PreviousState != nil:
+			return string(*event.PreviousState)
+		default:
+			return ""
+		}
+	},
+	"preview": func(s string) string {
+		const limit = 140
+		preview := []rune(strings.Join(strings.Fields(s), " "))
+		if len(preview) <= limit {
+			return string(preview)
+		}
+		return string(preview[:limit-3]) + "..."
+	},
+	"deref": func(p *int) int {
+		if p == nil {
+			return 0
+		}
+		return *p
+	},
+	"derefTime": func(p *time.Time) time.Time {
+		if p == nil {
+			return time.Time{}
+		}
+		return *p
+	},
+	"derefNote": func(p *string) string {
+		if p == nil {
+			return ""
+		}
+		return strings.TrimSpace(*p)
+	},
+}
+
+func readiness(item app.BloomThought) string {
+	if item.Ready {
+		return "ready now"
+	}
+	switch item.Thought.CurrentState {
+	case core.StateTended:
+		return "needs resolution"
+	case core.StateEvolved:
+		return "evolved"
+	case core.StateArchived:
+		return "remembered"
+	case core.StateReleased:
+		return "released"
+	}
+	return "eligible " + item.Thought.EligibilityAt.UTC().Format("2006-01-02 15:04Z")
+}
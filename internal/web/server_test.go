@@ -0,0 +1,178 @@
+package web
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/divijg19/peony/internal/app"
+	"github.com/divijg19/peony/internal/core"
+	"github.com/divijg19/peony/internal/storage"
+)
+
+func withSettleDuration(t *testing.T, duration time.Duration) {
+	t.Helper()
+	previous := core.SettleDuration
+	core.SettleDuration = duration
+	t.Cleanup(func() {
+		core.SettleDuration = previous
+	})
+}
+
+// newTestServer seeds a garden through a writable handle and serves it through a read-only one.
+func newTestServer(t *testing.T, seed func(service *app.Service)) *httptest.Server {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "peony.db")
+	db, err := storage.Open(path)
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	writable, err := app.NewForDB(db)
+	if err != nil {
+		t.Fatalf("new service: %v", err)
+	}
+	seed(writable)
+	_ = db.Close()
+
+	readOnly, err := storage.OpenReadOnly(path)
+	if err != nil {
+		t.Fatalf("open read-only: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = readOnly.Close()
+	})
+	service, err := app.NewForDB(readOnly)
+	if err != nil {
+		t.Fatalf("new read-only service: %v", err)
+	}
+	server, err := New(service)
+	if err != nil {
+		t.Fatalf("new server: %v", err)
+	}
+	ts := httptest.NewServer(server.Handler())
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func get(t *testing.T, ts *httptest.Server, path string) (int, string) {
+	t.Helper()
+	resp, err := http.Get(ts.URL + path)
+	if err != nil {
+		t.Fatalf("get %s: %v", path, err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("read %s: %v", path, err)
+	}
+	return resp.StatusCode, string(body)
+}
+
+func TestWebRendersQueuesAndThoughtHistory(t *testing.T) {
+	withSettleDuration(t, 0)
+	ts := newTestServer(t, func(service *app.Service) {
+		if _, err := service.Capture("a <quiet> ready thought"); err != nil {
+			t.Fatalf("capture ready: %v", err)
+		}
+		tendedID, err := service.Capture("a tended thought")
+		if err != nil {
+			t.Fatalf("capture tended: %v", err)
+		}
+		note := "softened the edges"
+		if err := service.Tend(tendedID, "a tended thought", &note); err != nil {
+			t.Fatalf("tend: %v", err)
+		}
+		if err := service.Rest(tendedID, nil); err != nil {
+			t.Fatalf("rest: %v", err)
+		}
+	})
+
+	status, body := get(t, ts, "/")
+	if status != http.StatusOK {
+		t.Fatalf("index status = %d, want 200", status)
+	}
+	if !strings.Contains(body, "a &lt;quiet&gt; ready thought") {
+		t.Fatalf("index should render escaped ready thought:\n%s", body)
+	}
+
+	status, body = get(t, ts, "/all")
+	if status != http.StatusOK || !strings.Contains(body, "a tended thought") {
+		t.Fatalf("all page status=%d body:\n%s", status, body)
+	}
+
+	status, body = get(t, ts, "/all?q=nothing-matches")
+	if status != http.StatusOK || !strings.Contains(body, "No matching thought found") {
+		t.Fatalf("search page status=%d body:\n%s", status, body)
+	}
+
+	status, body = get(t, ts, "/thoughts/2")
+	if status != http.StatusOK {
+		t.Fatalf("thought status = %d, want 200", status)
+	}
+	for _, want := range []string{"History", "captured", "tended → resting", "softened the edges"} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("thought page missing %q:\n%s", want, body)
+		}
+	}
+
+	if status, _ := get(t, ts, "/thoughts/99"); status != http.StatusNotFound {
+		t.Fatalf("missing thought status = %d, want 404", status)
+	}
+}
+
+func TestWebRefusesWrites(t *testing.T) {
+	ts := newTestServer(t, func(service *app.Service) {})
+	resp, err := http.Post(ts.URL+"/all", "text/plain", strings.NewReader("x"))
+	if err != nil {
+		t.Fatalf("post: %v", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Fatalf("post status = %d, want 405", resp.StatusCode)
+	}
+}
+
+func TestPreviewCutsByRunes(t *testing.T) {
+	preview := templateFuncs["preview"].(func(string) string)
+	long := strings.Repeat("花", 200)
+	got := preview(long)
+	if !utf8.ValidString(got) || got != strings.Repeat("花", 137)+"..." {
+		t.Fatalf("preview = %q", got)
+	}
+	if got := preview("  short\n  note "); got != "short note" {
+		t.Fatalf("preview = %q, want %q", got, "short note")
+	}
+}
+
+func TestListenAddrIsLoopbackOnly(t *testing.T) {
+	if got := ListenAddr(DefaultPort); got != "127.0.0.1:7733" {
+		t.Fatalf("listen addr = %q, want 127.0.0.1:7733", got)
+	}
+}
+
+func TestOpenReadOnlyRejectsWrites(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "peony.db")
+	db, err := storage.Open(path)
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	_ = db.Close()
+
+	readOnly, err := storage.OpenReadOnly(path)
+	if err != nil {
+		t.Fatalf("open read-only: %v", err)
+	}
+	defer readOnly.Close()
+	st, err := storage.New(readOnly)
+	if err != nil {
+		t.Fatalf("new store: %v", err)
+	}
+	if _, err := st.CreateThought("should not land"); err == nil {
+		t.Fatal("read-only store accepted a write")
+	}
+}
@@ -0,0 +1,88 @@
+package web
+
+// layoutTemplate wraps every page. It carries no scripts and no forms: the WebUI only looks.
+const layoutTemplate = `{{define "head"}}<!doctype html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>Peony · {{.Title}}</title>
+<style>
+  body { margin: 0; background: #262626; color: #d0d0d0; font: 16px/1.6 ui-sans-serif, system-ui, sans-serif; }
+  main { max-width: 46rem; margin: 0 auto; padding: 2rem 1.25rem 4rem; }
+  header { display: flex; justify-content: space-between; align-items: baseline; }
+  h1 { color: #ffd7af; font-weight: 600; font-size: 1.4rem; margin: 0; }
+  h2 { color: #d7d7af; font-size: 1rem; margin: 2rem 0 .5rem; }
+  a { color: #d7af87; text-decoration: none; }
+  a:hover { text-decoration: underline; }
+  nav { margin: 1rem 0 1.5rem; display: flex; gap: .75rem; }
+  nav a { padding: .15rem .6rem; border-radius: .3rem; }
+  nav a.active { background: #d7af87; color: #262626; }
+  ul.queue { list-style: none; padding: 0; margin: 0; }
+  ul.queue li { padding: .75rem 0; border-bottom: 1px solid #3a3a3a; }
+  .meta, .subtle { color: #8a8a8a; font-size: .9rem; }
+  .content { white-space: pre-wrap; color: #e4e4e4; }
+  ol.history { padding-left: 1.25rem; }
+  ol.history .note { color: #a8a8a8; white-space: pre-wrap; }
+  form.search input { background: #303030; border: 1px solid #444; color: #d0d0d0; padding: .3rem .5rem; border-radius: .3rem; width: 16rem; }
+</style>
+</head>
+<body>
+<main>
+<header><h1>Peony</h1><span class="subtle">a quiet, read-only window</span></header>
+{{end}}
+{{define "foot"}}
+</main>
+</body>
+</html>
+{{end}}`
+
+const listTemplate = `{{template "head" .}}
+<nav>
+  <a href="/ready"{{if eq .Filter "ready"}} class="active"{{end}}>Ready {{.Snapshot.Counts.Ready}}</a>
+  <a href="/resting"{{if eq .Filter "resting"}} class="active"{{end}}>Resting {{.Snapshot.Counts.Resting}}</a>
+  <a href="/all"{{if eq .Filter "all"}} class="active"{{end}}>All {{.Snapshot.Counts.All}}</a>
+</nav>
+<form class="search" method="get"><input type="search" name="q" value="{{.Query}}" placeholder="search thoughts, #tags, states, notes, or ids"></form>
+{{if .Snapshot.Thoughts}}
+<ul class="queue">
+{{range .Snapshot.Thoughts}}
+  <li>
+    <a href="/thoughts/{{.Thought.ID}}">#{{.Thought.ID}}</a> {{preview .Thought.Content}}
+    <div class="meta">{{readiness .}} · tended {{.Thought.TendCounter}}x{{range .Thought.Tags}} · <a href="/all?q=%23{{.}}">#{{.}}</a>{{end}}</div>
+  </li>
+{{end}}
+</ul>
+{{else}}
+<p class="subtle">{{.Empty}}</p>
+{{end}}
+{{template "foot" .}}`
+
+const thoughtTemplate = `{{template "head" .}}
+<nav><a href="/all">← back</a></nav>
+{{with .Item}}
+<h2>#{{.Thought.ID}} · {{.Thought.CurrentState}}</h2>
+<p class="meta">{{readiness .}} · tended {{.Thought.TendCounter}} times{{with .Thought.PublicID}} · ref {{.}}{{end}}</p>
+{{with .Thought.Tags}}<p class="meta">{{range .}}<a href="/all?q=%23{{.}}">#{{.}}</a> {{end}}</p>{{end}}
+<p class="content">{{.Thought.Content}}</p>
+<h2>When</h2>
+<p class="meta">
+  Created {{stamp .Thought.CreatedAt}}<br>
+  Updated {{stamp .Thought.UpdatedAt}}<br>
+  Eligible {{stamp .Thought.EligibilityAt}}{{if .Thought.LastTendedAt}}<br>
+  Tended {{stamp (derefTime .Thought.LastTendedAt)}}{{end}}
+</p>
+{{if or .Thought.Valence .Thought.Energy}}
+<h2>Feeling</h2>
+<p class="meta">{{if .Thought.Valence}}Valence {{deref .Thought.Valence}}{{end}}{{if and .Thought.Valence .Thought.Energy}} · {{end}}{{if .Thought.Energy}}Energy {{deref .Thought.Energy}}{{end}}</p>
+{{end}}
+{{if .Events}}
+<h2>History</h2>
+<ol class="history">
+{{range .Events}}
+  <li>{{stamp .At}} · {{.Kind}}{{with transition .}} · {{.}}{{end}}{{if .Note}}<div class="note">{{derefNote .Note}}</div>{{end}}</li>
+{{end}}
+</ol>
+{{end}}
+{{end}}
+{{template "foot" .}}`